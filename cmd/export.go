@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/nokia-paco-automation/paco-parser/parser"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// management subnet of the containerlab lab
+var mgmtSubnet string
+
+// format of the topology diagram
+var diagramFormat string
+
+// format of the cabling plan
+var cablingFormat string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export the paco deployment to other tools",
+}
+
+// exportClabCmd represents the export clab command
+var exportClabCmd = &cobra.Command{
+	Use:          "clab",
+	Short:        "export the fabric of the paco deployment as a containerlab lab",
+	Long:         "export the nodes and links of the paco deployment as a containerlab topology, with the generated configuration of the switches as startup configuration",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		if output == "-" {
+			return errors.New("export clab requires an output directory or archive")
+		}
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
+		}
+		setFlags(cfg)
+
+		files, err := parser.ExportClab(cmd.Context(), cfg, mgmtSubnet,
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir))
+		if err != nil {
+			return err
+		}
+		var sink parser.OutputSink
+		if isArchive(output) {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			sink = parser.NewTarSink(f)
+		} else {
+			sink = parser.NewDirSink(output)
+		}
+		if err := parser.WriteFiles(sink, files); err != nil {
+			sink.Close()
+			return err
+		}
+		log.Infof("containerlab lab written to %s", output)
+		return sink.Close()
+	},
+}
+
+// exportDiagramCmd represents the export diagram command
+var exportDiagramCmd = &cobra.Command{
+	Use:          "diagram",
+	Short:        "export the topology of the paco deployment as a diagram",
+	Long:         "draw the nodes grouped by their target and the links with their interfaces, lag, speed and addresses as a graphviz dot or mermaid diagram, written to the output directory or to stdout with -o -",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
+		}
+		setFlags(cfg)
+
+		fileName, b, err := parser.ExportDiagram(cmd.Context(), cfg, diagramFormat,
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir))
+		if err != nil {
+			return err
+		}
+		return writeExport(cmd, fileName, b)
+	},
+}
+
+// exportCablingCmd represents the export cabling command
+var exportCablingCmd = &cobra.Command{
+	Use:          "cabling",
+	Short:        "export the cabling plan of the paco deployment",
+	Long:         "write a row per physical link with the nodes, ports, speed, lag, vlan, kind, numa and bond as csv or xlsx, sorted by the rack and position labels of the nodes, to the output directory or to stdout with -o -",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
+		}
+		setFlags(cfg)
+
+		fileName, b, err := parser.ExportCabling(cmd.Context(), cfg, cablingFormat,
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir))
+		if err != nil {
+			return err
+		}
+		return writeExport(cmd, fileName, b)
+	},
+}
+
+// writeExport writes an exported file to the output directory or to stdout
+func writeExport(cmd *cobra.Command, fileName string, b []byte) error {
+	if output == "-" {
+		_, err := cmd.OutOrStdout().Write(b)
+		return err
+	}
+	sink := parser.NewDirSink(output)
+	if err := parser.WriteFiles(sink, map[string][]byte{fileName: b}); err != nil {
+		sink.Close()
+		return err
+	}
+	log.Infof("%s written", filepath.Join(output, fileName))
+	return sink.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportClabCmd)
+	exportCmd.AddCommand(exportDiagramCmd)
+	exportCmd.AddCommand(exportCablingCmd)
+	exportClabCmd.Flags().StringVarP(&mgmtSubnet, "mgmt-subnet", "", parser.DefaultClabMgmtSubnet, "ipv4 management subnet of the lab, the mgmt_ipv4 addresses in the subnet are assigned to the nodes")
+	exportDiagramCmd.Flags().StringVarP(&diagramFormat, "format", "", parser.DiagramFormatDot, "format of the diagram: dot or mermaid")
+	exportCablingCmd.Flags().StringVarP(&cablingFormat, "format", "", parser.CablingFormatCSV, "format of the cabling plan: csv or xlsx")
+}
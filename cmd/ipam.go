@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/nokia-paco-automation/paco-parser/parser"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// ipamCmd represents the ipam command
+var ipamCmd = &cobra.Command{
+	Use:   "ipam",
+	Short: "manage the ipam state of a paco deployment",
+}
+
+// ipamReclaimCmd represents the ipam reclaim command
+var ipamReclaimCmd = &cobra.Command{
+	Use:          "reclaim",
+	Short:        "reclaim the released ipam allocations",
+	Long:         "remove the ipam allocations that are no longer used by the deployment from the ipam state, such that their addresses can be allocated again",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := filepath.Join(output, parser.IPAMStateFileName)
+		s, err := parser.LoadIPAMState(file)
+		if err != nil {
+			return err
+		}
+		reclaimed := s.Reclaim()
+		for _, e := range reclaimed {
+			log.Infof("ipam allocation reclaimed: %s", e)
+		}
+		log.Infof("%d ipam allocation(s) reclaimed", len(reclaimed))
+		if len(reclaimed) == 0 {
+			return nil
+		}
+		return s.Write(file)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ipamCmd)
+	ipamCmd.AddCommand(ipamReclaimCmd)
+}
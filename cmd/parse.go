@@ -1,11 +1,29 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/nokia-paco-automation/paco-parser/parser"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// render the output in memory and show the differences with the output directory
+var dryRun bool
+
+// render the output in memory and fail when the output directory is out of date
+var check bool
+
+// output format of the switch configuration
+var switchFormat string
+
+// write the keys of the bgp authentication instead of their placeholders
+var renderSecrets bool
+
 // parseCmd represents the parse command
 var parseCmd = &cobra.Command{
 	Use:          "parse",
@@ -14,94 +32,112 @@ var parseCmd = &cobra.Command{
 	Aliases:      []string{"dep"},
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var err error
-		if err = configSet(); err != nil {
+		if err := configSet(); err != nil {
 			return err
 		}
-		opts := []parser.ParserOption{
-			parser.WithDebug(debug),
-			parser.WithConfigFile(&config),
-			parser.WithOutput(&output),
+		if (dryRun || check) && (output == "-" || isArchive(output)) {
+			return errors.New("--dry-run and --check require an output directory")
 		}
-		p := parser.NewParser(opts...)
-
-		setFlags(p.Config)
-
-		// initialize IPAM for the inter switch links (isl) links and elements
-		netwInfo := &parser.NetworkInfo{
-			Kind:                  parser.StringPtr("isl"),
-			AddressingSchema:      p.Config.Infrastructure.AddressingSchema,
-			Ipv4Cidr:              p.Config.Infrastructure.Networks["isl"].Ipv4Cidr,
-			Ipv4ItfcePrefixLength: p.Config.Infrastructure.Networks["isl"].Ipv4ItfcePrefixLength,
-			Ipv6Cidr:              p.Config.Infrastructure.Networks["isl"].Ipv6Cidr,
-			Ipv6ItfcePrefixLength: p.Config.Infrastructure.Networks["isl"].Ipv6ItfcePrefixLength,
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
 		}
+		setFlags(cfg)
 
-		p.IPAM["isl"], err = parser.NewIPAM(netwInfo)
-		if err != nil {
-			log.Fatal(err)
+		opts := []parser.ParserOption{
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir),
+			parser.WithSwitchFormat(switchFormat),
+			parser.WithRenderSecrets(renderSecrets),
 		}
-		// initialize IPAM for the loopbacks of the network elements
-		netwInfo = &parser.NetworkInfo{
-			Kind:                  parser.StringPtr("loopback"),
-			AddressingSchema:      p.Config.Infrastructure.AddressingSchema,
-			Ipv4Cidr:              p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr,
-			Ipv4ItfcePrefixLength: p.Config.Infrastructure.Networks["loopback"].Ipv4ItfcePrefixLength,
-			Ipv6Cidr:              p.Config.Infrastructure.Networks["loopback"].Ipv6Cidr,
-			Ipv6ItfcePrefixLength: p.Config.Infrastructure.Networks["loopback"].Ipv6ItfcePrefixLength,
+		var sink parser.OutputSink
+		var mem *parser.MemSink
+		dir := false
+		switch {
+		case output == "-":
+			sink = parser.NewStreamSink(os.Stdout)
+		case isArchive(output):
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			sink = parser.NewTarSink(f)
+		default:
+			opts = append(opts, parser.WithIPAMState(parser.StringPtr(filepath.Join(output, parser.IPAMStateFileName))))
+			sink = parser.NewDirSink(output)
+			dir = true
+			// in a dry-run or check nothing is written to the output directory
+			if dryRun || check {
+				mem = parser.NewMemSink()
+				sink = mem
+			}
 		}
-		p.IPAM["loopback"], err = parser.NewIPAM(netwInfo)
+
+		res, err := parser.Generate(cmd.Context(), cfg, opts...)
 		if err != nil {
-			log.Fatal(err)
+			sink.Close()
+			return err
 		}
-
-		// Parse the topology part of the configuration
-		if err = p.ParseTopology(); err != nil {
+		if err := res.WriteTo(sink); err != nil {
+			sink.Close()
 			return err
 		}
-		//p.ShowTopology()
-
-		if err = p.ParseClientGroup(); err != nil {
+		if err := sink.Close(); err != nil {
 			return err
 		}
-		//p.ShowClientGroup()
-
-		if err := p.InitializeIPAMWorkloads(); err != nil {
-			log.Fatal(err)
+		if mem != nil {
+			return diffOutput(cmd, mem)
 		}
-
-		// Parse the workload part of the configuration
-		/*
-			if err = p.ParseWorkload(); err != nil {
+		if dir {
+			// remove the files of nodes or workloads that are no longer rendered
+			removed, err := parser.PruneOutput(output, res.Files)
+			if err != nil {
 				return err
 			}
-			p.ShowWorkload()
-		*/
-
-		// Write the switch configuration in K8s
-		p.WriteBase()
-		// holds a structure with all directories that are used by kustomize
-		var kdirs []string
-		kd := p.WriteInfrastructure()
-		kdirs = append(kdirs, kd...)
-		kd = p.WriteClientsGroups()
-		kdirs = append(kdirs, kd...)
-		kd = p.WriteWorkloads()
-		kdirs = append(kdirs, kd...)
-		p.WriteFinalBase(kdirs)
-
-		//Write the server yaml files
-		p.ParseServerData()
-
-		//Write the values.yaml file for the respective applications in k8s
-		p.ParseApplicationData()
-
+			for _, path := range removed {
+				log.Infof("removed stale file %s", path)
+			}
+		}
 		return nil
 	},
 }
 
+// isArchive returns true if the output is a .tar.gz archive
+func isArchive(output string) bool {
+	return strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz")
+}
+
 func init() {
 	rootCmd.AddCommand(parseCmd)
+	parseCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "show the files that would be added, changed or removed in the output directory and their diff, without writing them")
+	parseCmd.Flags().BoolVarP(&check, "check", "", false, "exit with an error when the output directory is out of date, without writing it")
+	parseCmd.Flags().StringVarP(&switchFormat, "format", "", parser.SwitchFormatK8s, "format of the switch configuration: k8s resources, a SR Linux configuration per node in json or cli, or a gnmic set request file per node (gnmi)")
+	parseCmd.Flags().BoolVarP(&renderSecrets, "render-secrets", "", false, "write the keys of the bgp authentication in plaintext instead of ${VAR} placeholders, e.g. to load the native configuration directly")
+}
+
+// diffOutput prints the differences between the rendered files and the output directory
+func diffOutput(cmd *cobra.Command, mem *parser.MemSink) error {
+	diffs, err := parser.DiffOutput(output, mem.Files())
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	for _, d := range diffs {
+		fmt.Fprintf(out, "%s: %s\n", d.Status, d.Path)
+	}
+	if dryRun {
+		for _, d := range diffs {
+			fmt.Fprint(out, d.Diff)
+		}
+	}
+	if len(diffs) == 0 {
+		log.Infof("%s is up to date", output)
+		return nil
+	}
+	if check {
+		return fmt.Errorf("%s is out of date: %d file(s) differ", output, len(diffs))
+	}
+	return nil
 }
 
 // setFlags provides an override capability from the commandline
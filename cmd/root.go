@@ -19,6 +19,9 @@ var name string
 // output path
 var output string
 
+// directory with templates that replace the built-in templates
+var templatesDir string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "paco-parser",
@@ -44,6 +47,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&config, "config", "c", "", "path to the file with paco deployment information")
 	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "out", "path to the output path")
 	rootCmd.PersistentFlags().StringVarP(&name, "name", "n", "", "paco deployment name")
+	rootCmd.PersistentFlags().StringVarP(&templatesDir, "templates-dir", "", "", "path to a directory with templates that replace the built-in templates")
 }
 
 // returns an error if config path is not provided
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/nokia-paco-automation/paco-parser/parser"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:          "schema",
+	Short:        "print the json schema of the paco deployment file",
+	Long:         "print the json schema of the paco deployment file, for validation and completion in editors",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := parser.ConfigSchema()
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(b)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
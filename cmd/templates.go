@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/nokia-paco-automation/paco-parser/templates"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// overwrite existing files when exporting the templates
+var force bool
+
+// templatesCmd represents the templates command
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "manage the built-in templates",
+}
+
+// templatesListCmd represents the templates list command
+var templatesListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "list the built-in templates",
+	Long:         "list the built-in templates, the templates that are replaced by a file in the --templates-dir directory are marked",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fs.WalkDir(templates.FS, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".tmpl" {
+				return err
+			}
+			if templatesDir != "" {
+				override := filepath.Join(templatesDir, filepath.FromSlash(path))
+				if _, err := os.Stat(override); err == nil {
+					fmt.Printf("%s (replaced by %s)\n", path, override)
+					return nil
+				}
+			}
+			fmt.Println(path)
+			return nil
+		})
+	},
+}
+
+// templatesExportCmd represents the templates export command
+var templatesExportCmd = &cobra.Command{
+	Use:          "export <dir>",
+	Short:        "export the built-in templates",
+	Long:         "write the built-in templates to a directory, such that they can be customized and passed with --templates-dir",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := args[0]
+		return fs.WalkDir(templates.FS, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".tmpl" {
+				return err
+			}
+			file := filepath.Join(dir, filepath.FromSlash(path))
+			if _, err := os.Stat(file); err == nil && !force {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", file)
+			}
+			b, err := fs.ReadFile(templates.FS, path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, b, 0666); err != nil {
+				return err
+			}
+			log.Infof("template exported: %s", file)
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesExportCmd)
+	templatesExportCmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite existing files")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nokia-paco-automation/paco-parser/parser"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "validate a paco deployment file",
+	Long:         "validate a paco deployment definition file and report all problems without generating any output",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		verrs, err := parser.ValidateConfig(&config)
+		if err != nil {
+			return err
+		}
+		problems := 0
+		for _, verr := range verrs {
+			if verr.Warning {
+				log.Warn(verr)
+				continue
+			}
+			log.Error(verr)
+			problems++
+		}
+		if problems > 0 {
+			return fmt.Errorf("%s: %d problem(s) found", config, problems)
+		}
+		log.Infof("%s is valid", config)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/nokia-paco-automation/paco-parser/parser"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify the deployed network against the paco deployment",
+}
+
+// verifyCablingCmd represents the verify cabling command
+var verifyCablingCmd = &cobra.Command{
+	Use:   "cabling [node=]lldp-file...",
+	Short: "verify the cabling against the lldp neighbors of the nodes",
+	Long: "compare the lldp neighbors of the nodes with the planned links and report the missing links, swapped ports, links on the wrong node and unexpected neighbors; " +
+		"a file holds the SR Linux `show system lldp neighbor | as json` or the linux `lldpctl -f json` output of a node, the node is the file name up to the first dot unless it is given as node=file",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
+		}
+		setFlags(cfg)
+
+		neighbors := make(map[string][]*parser.LLDPNeighbor)
+		for _, arg := range args {
+			nodeName, file := nodeFile(arg)
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			nbrs, err := parser.ParseLLDP(b)
+			if err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			neighbors[nodeName] = append(neighbors[nodeName], nbrs...)
+		}
+
+		cerrs, err := parser.VerifyCabling(cmd.Context(), cfg, neighbors,
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir))
+		if err != nil {
+			return err
+		}
+		for _, cerr := range cerrs {
+			log.Error(cerr)
+		}
+		if len(cerrs) > 0 {
+			return fmt.Errorf("%d cabling problem(s) found", len(cerrs))
+		}
+		log.Infof("the cabling of %d node(s) is as planned", len(neighbors))
+		return nil
+	},
+}
+
+// verifyConfigCmd represents the verify config command
+var verifyConfigCmd = &cobra.Command{
+	Use:   "config [node=]config-file...",
+	Short: "verify the running configuration of the switches against the generated configuration",
+	Long: "compare the running configuration of the SR Linux nodes with the configuration the parser generates and report per node what is missing, extra or different; " +
+		"a file holds the `info from running / | as json` of a node, the node is the file name up to the first dot unless it is given as node=file, or the k8s resources of `kubectl get -o yaml` with their target label",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configSet(); err != nil {
+			return err
+		}
+		cfg, err := parser.LoadConfig(config)
+		if err != nil {
+			return err
+		}
+		setFlags(cfg)
+
+		rc := parser.NewRunningConfig()
+		for _, arg := range args {
+			nodeName, file := nodeFile(arg)
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := rc.Load(nodeName, b); err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+		}
+
+		drifts, err := parser.VerifyConfig(cmd.Context(), cfg, rc,
+			parser.WithDebug(debug),
+			parser.WithTemplateDir(&templatesDir))
+		if err != nil {
+			return err
+		}
+		for _, drift := range drifts {
+			log.Error(drift)
+		}
+		if len(drifts) > 0 {
+			return fmt.Errorf("%d configuration difference(s) found", len(drifts))
+		}
+		log.Info("the running configuration is as generated")
+		return nil
+	},
+}
+
+// nodeFile returns the node and the file of a node=file argument, the node of
+// a file argument is the file name up to the first dot
+func nodeFile(arg string) (string, string) {
+	if split := strings.SplitN(arg, "=", 2); len(split) == 2 {
+		return split[0], split[1]
+	}
+	return strings.SplitN(filepath.Base(arg), ".", 2)[0], arg
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.AddCommand(verifyCablingCmd)
+	verifyCmd.AddCommand(verifyConfigCmd)
+}
@@ -1,6 +1,6 @@
 module github.com/nokia-paco-automation/paco-parser
 
-go 1.15
+go 1.16
 
 require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
@@ -12,10 +12,12 @@ require (
 	github.com/huandu/xstrings v1.3.2 // indirect
 	github.com/imdario/mergo v0.3.12 // indirect
 	github.com/mitchellh/copystructure v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/cobra v1.1.3
 	github.com/stoewer/go-strcase v1.2.0
 	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550 // indirect
 	golang.org/x/sys v0.0.0-20191220142924-d4481acd189f // indirect
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
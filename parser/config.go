@@ -9,9 +9,12 @@ import (
 )
 
 const (
-	srlDefaultType    = "ixr6"
-	vrsrosDefaultType = "sr-1"
-	defaultPosition   = "network"
+	srlDefaultType     = "ixr6"
+	vrsrosDefaultType  = "sr-1"
+	defaultPosition    = "network"
+	srlDefaultImage    = "ghcr.io/nokia/srlinux"
+	vrsrosDefaultImage = "vrnetlab/vr-sros:latest"
+	linuxDefaultImage  = "ghcr.io/hellt/network-multitool"
 )
 
 // Config defines lab configuration as it is provided in the YAML file
@@ -80,8 +83,9 @@ type PacoNetworkInfo struct {
 
 type MultusInfo struct {
 	WorkloadName *string `yaml:"wl-name,omitempty"`
-	VrfCpId        *int    `yaml:"vrfcp-id,omitempty"`
-	VrfUpId        *int    `yaml:"vrfup-id,omitempty"`
+	VrfCpId      *int    `yaml:"vrfcp-id,omitempty"`
+	VrfUpId      *int    `yaml:"vrfup-id,omitempty"`
+	ShortName    *string `yaml:"shortname,omitempty"`
 }
 
 // Credentials
@@ -119,20 +123,22 @@ type Infrastructure struct {
 	InternetDns      *string                 `yaml:"internet_dns,omitempty"`
 	Protocols        *Protocols              `yaml:"protocols,omitempty"`
 	AddressingSchema *string                 `yaml:"addressing_schema,omitempty"`
+	IslAddressing    *string                 `yaml:"isl_addressing,omitempty"` // numbered or unnumbered
 	Networks         map[string]*NetworkInfo `yaml:"networks,omitempty"`
 }
 
 // NetworkInfo
 type NetworkInfo struct {
-	Ipv4Cidr              []*string `yaml:"ipv4_cidr,omitempty"`
-	Ipv4ItfcePrefixLength *int      `yaml:"ipv4_itfce_prefix_length,omitempty"`
-	Ipv6Cidr              []*string `yaml:"ipv6_cidr,omitempty"`
-	Ipv6ItfcePrefixLength *int      `yaml:"ipv6_itfce_prefix_length,omitempty"`
-	AddressingSchema      *string   `yaml:"addressing_schema,omitempty"`
-	Type                  *string   `yaml:"type,omitempty"`
-	VlanID                *int      `yaml:"vlan_id,omitempty"`
-	Kind                  *string   `yaml:"kind,omitempty"`
-	Target                *string   `yaml:"target,omitempty"`
+	Ipv4Cidr              []*string    `yaml:"ipv4_cidr,omitempty"`
+	Ipv4ItfcePrefixLength *int         `yaml:"ipv4_itfce_prefix_length,omitempty"`
+	Ipv6Cidr              []*string    `yaml:"ipv6_cidr,omitempty"`
+	Ipv6ItfcePrefixLength *int         `yaml:"ipv6_itfce_prefix_length,omitempty"`
+	AddressingSchema      *string      `yaml:"addressing_schema,omitempty"`
+	Type                  *string      `yaml:"type,omitempty"`
+	VlanID                *int         `yaml:"vlan_id,omitempty"`
+	Kind                  *string      `yaml:"kind,omitempty"`
+	Target                *string      `yaml:"target,omitempty"`
+	BgpSessions           *BgpSessions `yaml:"bgp_sessions,omitempty"` // overrides the bgp_sessions of the protocols for a routed itfce
 	NetworkIndex          *int
 	SwitchIndex           *int
 
@@ -172,10 +178,42 @@ type BGPPeerInfo struct {
 
 // Protocols
 type Protocols struct {
-	Protocol        *string   `yaml:"protocol,omitempty"`
-	AsPool          []*uint32 `yaml:"as_pool,omitempty"`
-	OverlayAs       *uint32   `yaml:"overlay_as,omitempty"`
-	OverlayProtocol *string   `yaml:"overlay_protocol,omitempty"`
+	Protocol        *string      `yaml:"protocol,omitempty"` // ebgp, isis or ospf
+	AsPool          []*uint32    `yaml:"as_pool,omitempty"`
+	OverlayAs       *uint32      `yaml:"overlay_as,omitempty"`
+	OverlayProtocol *string      `yaml:"overlay_protocol,omitempty"`
+	OverlayDesign   *string      `yaml:"overlay_design,omitempty"` // full-mesh, route-reflector or ebgp
+	IsisLevel       *string      `yaml:"isis_level,omitempty"`     // L1, L2 or L1L2
+	IsisArea        *string      `yaml:"isis_area,omitempty"`      // e.g. 49.0001
+	OspfArea        *string      `yaml:"ospf_area,omitempty"`      // e.g. 0.0.0.0
+	HelloInterval   *int         `yaml:"hello_interval,omitempty"`
+	HelloMultiplier *int         `yaml:"hello_multiplier,omitempty"`
+	BgpSessions     *BgpSessions `yaml:"bgp_sessions,omitempty"` // underlay and overlay groups, default of the workloads
+	// SummarizeLoopbacks advertises the supernets of the loopback cidrs in
+	// the export policy of the underlay
+	SummarizeLoopbacks *bool `yaml:"summarize_loopbacks,omitempty"`
+}
+
+// BgpSessions holds the bfd, authentication, timers and prefix limit of bgp sessions
+type BgpSessions struct {
+	Bfd               *Bfd     `yaml:"bfd,omitempty"`
+	Auth              *BgpAuth `yaml:"auth,omitempty"`
+	HoldTime          *int     `yaml:"hold_time,omitempty"`          // seconds
+	KeepaliveInterval *int     `yaml:"keepalive_interval,omitempty"` // seconds
+	MaxPrefix         *int     `yaml:"max_prefix,omitempty"`         // received routes per address family
+}
+
+// Bfd holds the bfd timers of the bgp sessions
+type Bfd struct {
+	Interval   *int `yaml:"interval,omitempty"` // transmit and receive interval in milliseconds
+	Multiplier *int `yaml:"multiplier,omitempty"`
+}
+
+// BgpAuth holds the authentication of the bgp sessions, the key is not part of
+// the config but a reference to a secret
+type BgpAuth struct {
+	Type   *string `yaml:"type,omitempty"`   // md5 or tcp-ao
+	Secret *string `yaml:"secret,omitempty"` // env:NAME or file:PATH
 }
 
 // WorkloadInfo
@@ -197,15 +235,32 @@ type NodeConfig struct {
 	Kind     *string            `yaml:"kind,omitempty"`   // srl, vr-sros, linux
 	Labels   map[string]*string `yaml:"labels,omitempty"` // Labels are attributes
 	Group    *string            `yaml:"group,omitempty"`
-	Type     *string            `yaml:"type,omitempty"` // ixrd2, sr-1s, etc
+	Type     *string            `yaml:"type,omitempty"`  // ixrd2, sr-1s, etc
+	Image    *string            `yaml:"image,omitempty"` // container image of the node in a containerlab lab
 	Position *string            `yaml:"position,omitempty"`
 	MgmtIPv4 *string            `yaml:"mgmt_ipv4,omitempty"` // user-defined IPv4 address in the management network
 	MgmtIPv6 *string            `yaml:"mgmt_ipv6,omitempty"` // user-defined IPv6 address in the management network
 	AS       *uint32            `yaml:"as,omitempty"`
+	Storage  *StorageInfo       `yaml:"storage,omitempty"`
+	WanPeers []*WanPeer         `yaml:"wan_peers,omitempty"` // eBGP peers of a data center gateway towards the wan
+}
+
+// WanPeer represents an eBGP peer of a data center gateway in the wan, the ue
+// pools are exported to it
+type WanPeer struct {
+	PeerAddress *string `yaml:"peer_address,omitempty"`
+	PeerAS      *uint32 `yaml:"peer_as,omitempty"`
+}
+
+// StorageInfo represents the nfs storage of the linux nodes
+type StorageInfo struct {
+	NfsServer *string `yaml:"nfs_server,omitempty"`
+	NfsMount  *string `yaml:"nfs_mount,omitempty"`
+	Csi       *string `yaml:"csi,omitempty"`
 }
 
 type LinkConfig struct {
-	Endpoints []*string
+	Endpoints []*string          `yaml:"endpoints"`
 	Labels    map[string]*string `yaml:"labels,omitempty"`
 }
 
@@ -214,6 +269,7 @@ type Node struct {
 	ShortName            *string
 	Kind                 *string
 	Type                 *string
+	Image                *string
 	Labels               map[string]*string
 	Group                *string
 	Position             *string
@@ -233,7 +289,7 @@ type Link struct {
 	B             *Endpoint
 	MTU           *int
 	Labels        map[string]*string
-	vWire         *bool   // tbd for what this is used
+	vWire         *bool   // true for a physical link, false for the logical link of a LAG
 	Kind          *string // isl: inter switch links, access: links to clients
 	VlanTagging   *bool   // VLANid, used only for isl links
 	VlanID        *string // VLANid, used only for isl links
@@ -305,12 +361,22 @@ func (p *Parser) ParseTopology() (err error) {
 	log.Info("Parsing topology information ...")
 
 	// initialize the Node information from the topology map
-	for nodeName, nodeCfg := range p.Config.Topology.Nodes {
-		p.Nodes[nodeName], err = p.NewNode(nodeName, nodeCfg)
+	// nodes are initialized in sorted order, since the AS and loopback
+	// allocation depend on the order
+	for _, nodeName := range SortedKeys(p.Config.Topology.Nodes) {
+		p.Nodes[nodeName], err = p.NewNode(nodeName, p.Config.Topology.Nodes[nodeName])
 		if err != nil {
 			return err
 		}
 	}
+	// the gateways get their AS after the network nodes, such that adding a
+	// gateway does not change the AS of the leafs
+	for _, nodeName := range SortedKeys(p.Nodes) {
+		n := p.Nodes[nodeName]
+		if isSros(*n.Kind) && p.Config.Topology.Nodes[nodeName].AS == nil && p.Config.Infrastructure != nil {
+			p.allocateAS(n)
+		}
+	}
 	for _, l := range p.Config.Topology.Links {
 		if err = p.NewLink(l); err != nil {
 			return err
@@ -332,8 +398,8 @@ func (p *Parser) kindInitialization(nodeCfg *NodeConfig) *string {
 // initialize Type
 func (p *Parser) typeInitialization(nodeCfg *NodeConfig, kind *string) *string {
 	if nodeCfg.Type != nil {
-		if *nodeCfg.Kind != "" {
-			return nodeCfg.Kind
+		if *nodeCfg.Type != "" {
+			return nodeCfg.Type
 		}
 	}
 	if _, ok := p.Config.Topology.Kinds[*kind]; ok {
@@ -356,12 +422,47 @@ func (p *Parser) typeInitialization(nodeCfg *NodeConfig, kind *string) *string {
 	switch *kind {
 	case "srl":
 		return StringPtr(srlDefaultType)
-	case "vr-sros":
+	case "vr-sros", "sros":
 		return StringPtr(vrsrosDefaultType)
 	}
 	return StringPtr("")
 }
 
+// initialize Image
+func (p *Parser) imageInitialization(nodeCfg *NodeConfig, kind *string) *string {
+	if nodeCfg.Image != nil {
+		if *nodeCfg.Image != "" {
+			return nodeCfg.Image
+		}
+	}
+	if _, ok := p.Config.Topology.Kinds[*kind]; ok {
+		if p.Config.Topology.Kinds[*kind].Image != nil {
+			if *p.Config.Topology.Kinds[*kind].Image != "" {
+				return p.Config.Topology.Kinds[*kind].Image
+			}
+		}
+	}
+
+	if p.Config.Topology.Defaults != nil {
+		if p.Config.Topology.Defaults.Image != nil {
+			if *p.Config.Topology.Defaults.Image != "" {
+				return p.Config.Topology.Defaults.Image
+			}
+		}
+	}
+
+	// default image if not defined
+	switch *kind {
+	case "srl":
+		return StringPtr(srlDefaultImage)
+	case "vr-sros", "sros":
+		return StringPtr(vrsrosDefaultImage)
+	case "linux":
+		return StringPtr(linuxDefaultImage)
+	}
+	return StringPtr("")
+}
+
 // initialize Position
 func (p *Parser) positionInitialization(nodeCfg *NodeConfig, kind *string) *string {
 	if nodeCfg.Position != nil {
@@ -401,6 +502,7 @@ func (p *Parser) NewNode(nodeName string, nodeCfg *NodeConfig) (*Node, error) {
 		ShortName:            new(string),
 		Kind:                 new(string),
 		Type:                 new(string),
+		Image:                new(string),
 		Labels:               make(map[string]*string),
 		Group:                new(string),
 		Position:             new(string),
@@ -429,6 +531,10 @@ func (p *Parser) NewNode(nodeName string, nodeCfg *NodeConfig) (*Node, error) {
 	// most specific information is selected
 	node.Type = StringPtr(strings.ToLower(*p.typeInitialization(nodeCfg, node.Kind)))
 
+	// initialize image, based on hierarchical information in the config file
+	// most specific information is selected
+	node.Image = p.imageInitialization(nodeCfg, node.Kind)
+
 	// initialize position, based on hierarchical information in the config file
 	// most specific information is selected
 	node.Position = StringPtr(strings.ToLower(*p.positionInitialization(nodeCfg, node.Kind)))
@@ -445,7 +551,8 @@ func (p *Parser) NewNode(nodeName string, nodeCfg *NodeConfig) (*Node, error) {
 	if p.Config.Infrastructure != nil {
 		if *node.Position == "network" {
 			// Allocate the node loopback address
-			ipEP, err := p.IPAM["loopback"].IPAMAllocateAddress(StringPtr("loopback"), p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr, p.Config.Infrastructure.Networks["loopback"].Ipv6Cidr)
+			ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.infraSchema(), p.Config.Infrastructure.Networks["loopback"])
+			ipEP, err := p.IPAM["loopback"].IPAMAllocateAddress(StringPtr("loopback"), node.ShortName, ipv4Cidrs, ipv6Cidrs)
 			if err != nil {
 				return nil, err
 			}
@@ -462,25 +569,54 @@ func (p *Parser) NewNode(nodeName string, nodeCfg *NodeConfig) (*Node, error) {
 			//log.Infof("IP loopback allocation on node: %s, %v", node.ShortName, node.Interfaces["lo1"])
 
 			log.Debugf("Node Loopback: %s, ipv4: %s, ipv6: %s", *node.ShortName, *node.Endpoints["lo0"].IPv4Prefix, *node.Endpoints["lo0"].IPv6Prefix)
-			log.Debugf("Position: %s %d", node.Position, p.NextAS)
+			log.Debugf("Position: %s %d", *node.Position, *p.NextAS)
 
 			// dont apply the AS auto-config if the AS is supplied by config
 			if nodeCfg.AS == nil {
-				switch *p.Config.Infrastructure.Protocols.Protocol {
-				case "ebgp":
-					// update the AS from the original parser
-					*node.AS = *p.NextAS
-					*p.NextAS++
-				default:
-					// update the AS from the original parser
-					*node.AS = *p.NextAS
-				}
+				p.allocateAS(node)
 			}
 		}
 	}
 	return node, nil
 }
 
+// allocateAS assigns the next AS of the as pool to the node; with an igp
+// underlay the fabric nodes share the overlay AS
+func (p *Parser) allocateAS(node *Node) {
+	protocols := p.Config.Infrastructure.Protocols
+	switch *protocols.Protocol {
+	case UnderlayISIS, UnderlayOSPF:
+		if !isSros(*node.Kind) && protocols.OverlayAs != nil {
+			*node.AS = *protocols.OverlayAs
+			return
+		}
+		// the gateways peer with eBGP in an AS of their own
+		*node.AS = *p.NextAS
+		*p.NextAS++
+	case UnderlayEBGP:
+		// update the AS from the original parser
+		*node.AS = *p.NextAS
+		*p.NextAS++
+	default:
+		// update the AS from the original parser
+		*node.AS = *p.NextAS
+	}
+}
+
+// isSros returns true for the SR OS kinds, sros and vr-sros
+func isSros(kind string) bool {
+	return kind == "sros" || kind == "vr-sros"
+}
+
+// srosPortName returns the SR OS port of an endpoint, eth1 is port 1/1/1
+// like in containerlab; other names are used as is
+func srosPortName(epName string) string {
+	if n, err := strconv.Atoi(strings.TrimPrefix(epName, "eth")); err == nil && strings.HasPrefix(epName, "eth") {
+		return "1/1/" + strconv.Itoa(n)
+	}
+	return epName
+}
+
 // NewLink initializes a new link object
 func (p *Parser) NewLink(l *LinkConfig) error {
 	// initialize a new link
@@ -509,13 +645,16 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 	var nodeShortNameB *string
 	var epShortNameB *string
 
+	if len(l.Endpoints) != 2 {
+		return &TopologyError{Element: "link", Msg: fmt.Sprintf("a link requires 2 endpoints, got %d", len(l.Endpoints))}
+	}
 	for i, d := range l.Endpoints {
 		// i indicates the number and d presents the string, which need to be
 		// split in node and endpoint name
 		// split the string to get node name and endpoint name
 		split := strings.Split(*d, ":")
 		if len(split) != 2 {
-			log.Fatal(fmt.Sprintf("endpoint %s has wrong syntax", *d))
+			return &TopologyError{Element: "endpoint " + *d, Msg: "wrong syntax, expected node:endpoint"}
 		}
 		if i == 0 {
 			nodeShortNameA = &split[0]
@@ -526,8 +665,14 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 		}
 	}
 	// initialize nodeA and nodeB
-	nodeA := p.Nodes[*nodeShortNameA]
-	nodeB := p.Nodes[*nodeShortNameB]
+	nodeA, ok := p.Nodes[*nodeShortNameA]
+	if !ok {
+		return &TopologyError{Element: "node " + *nodeShortNameA, Msg: "node is not declared in the nodes section"}
+	}
+	nodeB, ok := p.Nodes[*nodeShortNameB]
+	if !ok {
+		return &TopologyError{Element: "node " + *nodeShortNameB, Msg: "node is not declared in the nodes section"}
+	}
 
 	// initialize the label parameters from the config
 	link.Labels = l.Labels
@@ -591,7 +736,7 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 	if _, ok := link.Labels["type"]; ok {
 		split := strings.Split(*link.Labels["type"], ":")
 		if len(split) != 1 {
-			log.Fatal(fmt.Sprintf("Link label type %s has wrong syntax", *link.Labels["type"]))
+			return &ConfigError{Path: "topology.links.labels.type", Msg: fmt.Sprintf("link label type %s has wrong syntax", *link.Labels["type"])}
 		}
 		if strings.Contains(split[0], "lag") || strings.Contains(split[0], "esi") {
 			link.Lag = BoolPtr(true)
@@ -612,7 +757,7 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 	if _, ok := link.Labels["numa"]; ok {
 		n, err := strconv.Atoi(*link.Labels["numa"])
 		if err != nil {
-			log.WithError(err).Errorf("issue converting numa into string")
+			return &ConfigError{Path: "topology.links.labels.numa", Msg: fmt.Sprintf("numa %s is not an integer", *link.Labels["numa"])}
 		}
 		link.Numa = IntPtr(n)
 	}
@@ -642,31 +787,34 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 			lag.VlanID = link.VlanID
 			lag.Speed = link.Speed
 			lag.Pxe = link.Pxe
+			var err error
 			if *nodeA.Kind == "linux" {
 				// override the lag name with the client-name supplied in the labels field
-				lag.A = p.NewEndpoint(nodeShortNameA, link.ClientName, lag, nodeB)
+				lag.A, err = p.NewEndpoint(nodeShortNameA, link.ClientName, lag, nodeB)
 			} else {
-				lag.A = p.NewEndpoint(nodeShortNameA, lag.LagName, lag, nodeB)
+				lag.A, err = p.NewEndpoint(nodeShortNameA, lag.LagName, lag, nodeB)
+			}
+			if err != nil {
+				return err
 			}
 			if *nodeB.Kind == "linux" {
 				log.Debugf("New Endpoint: %s", *link.ClientName)
 				// override the lag name with the client-name supplied in the labels field
-				lag.B = p.NewEndpoint(nodeShortNameB, link.ClientName, lag, nodeA)
+				lag.B, err = p.NewEndpoint(nodeShortNameB, link.ClientName, lag, nodeA)
 			} else {
-				lag.B = p.NewEndpoint(nodeShortNameB, lag.LagName, lag, nodeA)
+				lag.B, err = p.NewEndpoint(nodeShortNameB, lag.LagName, lag, nodeA)
+			}
+			if err != nil {
+				return err
 			}
 
 			p.Nodes[*nodeShortNameA].Endpoints[*lag.LagName] = lag.A
 			p.Nodes[*nodeShortNameB].Endpoints[*lag.LagName] = lag.B
 			p.Links = append(p.Links, lag)
 			// Allocate IP addresses on the link if the link is of kind isl
-			if *lag.Kind == "isl" {
-				for _, ipv4Cidr := range p.Config.Infrastructure.Networks["isl"].Ipv4Cidr {
-					for _, ipv6Cidr := range p.Config.Infrastructure.Networks["isl"].Ipv6Cidr {
-						if err := p.IPAM["isl"].IPAMAllocateLinkPrefix(lag, ipv4Cidr, ipv6Cidr); err != nil {
-							log.Error(err)
-						}
-					}
+			if *lag.Kind == "isl" && !p.unnumberedIsl() {
+				if err := p.allocateIslPrefixes(lag); err != nil {
+					return err
 				}
 			}
 		}
@@ -681,28 +829,42 @@ func (p *Parser) NewLink(l *LinkConfig) error {
 		*link.LagName = strings.ReplaceAll(*link.LagName, "esi", "lag")
 	}
 	//link.LagNameA = lagID
-	link.A = p.NewEndpoint(nodeShortNameA, epShortNameA, link, nodeB)
-	link.B = p.NewEndpoint(nodeShortNameB, epShortNameB, link, nodeA)
+	var err error
+	if link.A, err = p.NewEndpoint(nodeShortNameA, epShortNameA, link, nodeB); err != nil {
+		return err
+	}
+	if link.B, err = p.NewEndpoint(nodeShortNameB, epShortNameB, link, nodeA); err != nil {
+		return err
+	}
 	p.Nodes[*nodeShortNameA].Endpoints[*epShortNameA] = link.A
 	p.Nodes[*nodeShortNameB].Endpoints[*epShortNameB] = link.B
 	link.vWire = BoolPtr(true)
 	p.Links = append(p.Links, link)
 	// Allocate IP addresses on the link
 	// only allocate IP link prefixes for LAG link bundle and links not part of a lag bundle
-	if link.LagMemberLink != nil && !*link.LagMemberLink && *link.Kind == "isl" {
-		for _, ipv4Cidr := range p.Config.Infrastructure.Networks["isl"].Ipv4Cidr {
-			for _, ipv6Cidr := range p.Config.Infrastructure.Networks["isl"].Ipv6Cidr {
-				if err := p.IPAM["isl"].IPAMAllocateLinkPrefix(link, ipv4Cidr, ipv6Cidr); err != nil {
-					log.Error(err)
-				}
-			}
+	// the unnumbered isls only have link-local addresses
+	if link.LagMemberLink != nil && !*link.LagMemberLink && *link.Kind == "isl" && !p.unnumberedIsl() {
+		if err := p.allocateIslPrefixes(link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateIslPrefixes allocates the prefixes of an isl from the cidrs of the
+// isl network in the addressing schema of the infrastructure
+func (p *Parser) allocateIslPrefixes(link *Link) error {
+	ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.infraSchema(), p.Config.Infrastructure.Networks["isl"])
+	for _, cidrs := range cidrPairs(ipv4Cidrs, ipv6Cidrs) {
+		if err := p.IPAM["isl"].IPAMAllocateLinkPrefix(link, cidrs[0], cidrs[1]); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // NewEndpoint initializes a new endpoint object
-func (p *Parser) NewEndpoint(nodeShortName, epShortName *string, l *Link, peerNode *Node) *Endpoint {
+func (p *Parser) NewEndpoint(nodeShortName, epShortName *string, l *Link, peerNode *Node) (*Endpoint, error) {
 	// initialize a new endpoint
 	ep := &Endpoint{
 		Node:                new(Node),
@@ -746,7 +908,7 @@ func (p *Parser) NewEndpoint(nodeShortName, epShortName *string, l *Link, peerNo
 		}
 	}
 	if !found {
-		log.Fatalf("Not all nodes are specified in the duts section or the names don't match in the duts/endpoint section: %s", nodeShortName)
+		return nil, &TopologyError{Element: "node " + *nodeShortName, Msg: "node is not declared in the nodes section"}
 	}
 
 	// initialize the endpoint name
@@ -758,6 +920,9 @@ func (p *Parser) NewEndpoint(nodeShortName, epShortName *string, l *Link, peerNo
 			*ep.RealName = strings.ReplaceAll(*epShortName, "-", "/")
 			*ep.RealName = strings.ReplaceAll(*ep.RealName, "e", "ethernet-")
 		}
+		if isSros(*ep.Node.Kind) {
+			*ep.RealName = srosPortName(*epShortName)
+		}
 	} else {
 		if *ep.Node.Kind == "srl" {
 			*ep.RealName = strings.ReplaceAll(*epShortName, "esi", "lag")
@@ -784,7 +949,7 @@ func (p *Parser) NewEndpoint(nodeShortName, epShortName *string, l *Link, peerNo
 		*ep.Pxe = *l.Pxe
 	}
 
-	return ep
+	return ep, nil
 }
 
 // ShowTopology show the topology that was initialized
@@ -842,9 +1007,16 @@ func (p *Parser) ParseClientGroup() (err error) {
 					if _, ok := p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName]; !ok {
 						p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName] = make([]*InterfaceDetails, 0)
 					}
-					// check id the initerface already exists
-
-					p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName] = append(p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName], interfaceDetails)
+					// check for duplicate interface information
+					found := false
+					for _, itfceDetail := range p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName] {
+						if *itfceDetail.Endpoint.RealName == *l.B.RealName {
+							found = true
+						}
+					}
+					if !found {
+						p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName] = append(p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName], interfaceDetails)
+					}
 
 					// Target Node initialization -> allows for node group configuration
 					// Here we need to check duplicate interfaces and avoid duplicating the interfaces
@@ -853,7 +1025,7 @@ func (p *Parser) ParseClientGroup() (err error) {
 						p.ClientGroups[cgName].Interfaces[*l.B.Node.ShortName] = make([]*InterfaceDetails, 0)
 					}
 					// check for duplicate interface information
-					found := false
+					found = false
 					for _, itfceDetail := range p.ClientGroups[cgName].Interfaces[*l.B.Node.Target] {
 						if *itfceDetail.Endpoint.RealName == *l.B.RealName {
 							found = true
@@ -1125,6 +1297,17 @@ func (p *Parser) ShowWorkload() {
 }
 */
 
+// InitializeIPAM initializes the ipam with the given name and attaches the ipam state to it
+func (p *Parser) InitializeIPAM(name string, netwInfo *NetworkInfo) (err error) {
+	p.IPAM[name], err = NewIPAM(netwInfo)
+	if err != nil {
+		return err
+	}
+	p.IPAM[name].Name = StringPtr(name)
+	p.IPAM[name].State = p.IPAMState
+	return nil
+}
+
 func (p *Parser) InitializeIPAMWorkloads() (err error) {
 	log.Info("initializing IPAM for workloads...")
 
@@ -1142,8 +1325,7 @@ func (p *Parser) InitializeIPAMWorkloads() (err error) {
 						Ipv6ItfcePrefixLength: netwInfo.Ipv6ItfcePrefixLength,
 					}
 
-					p.IPAM[ipamName], err = NewIPAM(netwInfo)
-					if err != nil {
+					if err = p.InitializeIPAM(ipamName, netwInfo); err != nil {
 						return err
 					}
 				}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"bytes"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// FileStatus indicates how a rendered file differs from the file on disk
+type FileStatus string
+
+const (
+	FileAdded   FileStatus = "added"
+	FileChanged FileStatus = "changed"
+	FileRemoved FileStatus = "removed"
+)
+
+// generatedDirs holds the directories of the output that the parser owns, the
+// files in them that are not rendered are stale; other files in the output
+// directory, e.g. the ipam state, are left alone
+var generatedDirs = []string{"switch", appValuesDir, appKustomizeDir, serverDir, appIpamDir, srosDir}
+
+// generatedFile returns true when the file, relative to the output directory,
+// is in one of the generated directories
+func generatedFile(path string) bool {
+	dir := strings.SplitN(filepath.ToSlash(path), "/", 2)[0]
+	for _, d := range generatedDirs {
+		if dir == d && dir != filepath.ToSlash(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// FileDiff is a difference between the rendered output and the output on disk
+type FileDiff struct {
+	Path   string // relative to the output directory
+	Status FileStatus
+	Diff   string // unified diff of the file
+}
+
+// DiffOutput compares the rendered files, keyed by path relative to the output
+// directory, with the files in the output directory on disk; the files in the
+// generated directories that are not rendered are removed
+func DiffOutput(dir string, files map[string][]byte) ([]*FileDiff, error) {
+	onDisk := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		onDisk[rel], err = ioutil.ReadFile(path)
+		return err
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	rendered := make(map[string][]byte, len(files))
+	for path, b := range files {
+		rendered[filepath.Clean(path)] = b
+	}
+
+	diffs := make([]*FileDiff, 0)
+	for _, path := range SortedKeys(rendered) {
+		old, ok := onDisk[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, newFileDiff(path, FileAdded, nil, rendered[path]))
+		case !bytes.Equal(old, rendered[path]):
+			diffs = append(diffs, newFileDiff(path, FileChanged, old, rendered[path]))
+		}
+	}
+	for _, path := range SortedKeys(onDisk) {
+		if _, ok := rendered[path]; !ok && generatedFile(path) {
+			diffs = append(diffs, newFileDiff(path, FileRemoved, onDisk[path], nil))
+		}
+	}
+	return diffs, nil
+}
+
+// PruneOutput removes the files in the generated directories of the output
+// directory that are not rendered, such that a parse leaves no stale files of
+// removed nodes or workloads; it returns the removed files
+func PruneOutput(dir string, files map[string][]byte) ([]string, error) {
+	diffs, err := DiffOutput(dir, files)
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]string, 0)
+	for _, d := range diffs {
+		if d.Status != FileRemoved {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, d.Path)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, d.Path)
+	}
+	return removed, nil
+}
+
+func newFileDiff(path string, status FileStatus, old, new []byte) *FileDiff {
+	from, to := "a/"+filepath.ToSlash(path), "b/"+filepath.ToSlash(path)
+	switch status {
+	case FileAdded:
+		from = "/dev/null"
+	case FileRemoved:
+		to = "/dev/null"
+	}
+	// the diff of an in memory string cannot fail
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(old),
+		B:        splitLines(new),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	return &FileDiff{
+		Path:   path,
+		Status: status,
+		Diff:   diff,
+	}
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(b))
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffOutput(t *testing.T) {
+	config := testConfigs[0]
+	output := t.TempDir()
+	runParser(t, config, output)
+
+	// rendering in memory produces the same files as rendering to disk
+	mem := NewMemSink()
+	runParser(t, config, output, WithOutputSink(mem))
+	compareTrees(t, readTree(t, output), mem.Files())
+	diffs, err := DiffOutput(output, mem.Files())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Fatalf("got %d diffs on an up to date output directory", len(diffs))
+	}
+
+	changed := filepath.Join("server", "sriovdp_cm.yaml")
+	added := filepath.Join("app-values", "amf_values.yaml")
+	// stale files in the generated directories are removed, other files are left alone
+	removed := filepath.Join("switch", "kustomize", "leaf9", "stale.yaml")
+	foreign := "stale.yaml"
+	f, err := os.OpenFile(filepath.Join(output, changed), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("stale line\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.Remove(filepath.Join(output, added)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(output, foreign), []byte("stale\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(output, removed)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(output, removed), []byte("stale\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err = DiffOutput(output, mem.Files())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]FileStatus{added: FileAdded, changed: FileChanged, removed: FileRemoved}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %d diffs, want %d", len(diffs), len(want))
+	}
+	for _, d := range diffs {
+		if want[d.Path] != d.Status {
+			t.Errorf("%s: got %s, want %s", d.Path, d.Status, want[d.Path])
+		}
+		if d.Path == changed && !strings.Contains(d.Diff, "\n-stale line\n") {
+			t.Errorf("%s: unexpected diff\n%s", d.Path, d.Diff)
+		}
+		if d.Path == removed && !strings.Contains(d.Diff, "+++ /dev/null\n") {
+			t.Errorf("%s: unexpected diff\n%s", d.Path, d.Diff)
+		}
+	}
+
+	pruned, err := PruneOutput(output, mem.Files())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || pruned[0] != removed {
+		t.Errorf("got pruned files %v, want %s", pruned, removed)
+	}
+	if _, err := os.Stat(filepath.Join(output, removed)); !os.IsNotExist(err) {
+		t.Errorf("%s: not removed", removed)
+	}
+	if _, err := os.Stat(filepath.Join(output, foreign)); err != nil {
+		t.Errorf("%s: %v", foreign, err)
+	}
+}
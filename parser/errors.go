@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// TopologyError is returned when the topology cannot be build from the
+// deployment file, e.g. a link refers to a node that is not declared
+type TopologyError struct {
+	Element string // node, link or endpoint the error relates to
+	Msg     string
+}
+
+func (e *TopologyError) Error() string {
+	return fmt.Sprintf("topology %s: %s", e.Element, e.Msg)
+}
+
+// IPAMExhaustedError is returned when an address or prefix cannot be allocated
+// since all addresses or prefixes of the cidr are in use
+type IPAMExhaustedError struct {
+	Kind string // loopback, isl, application or the workload ipam name
+	Cidr string
+}
+
+func (e *IPAMExhaustedError) Error() string {
+	return fmt.Sprintf("ipam %s: no free address or prefix left in %s", e.Kind, e.Cidr)
+}
+
+// ConfigError is returned when a parameter in the deployment file is missing
+// or does not follow the expected syntax
+type ConfigError struct {
+	Path string // yaml path of the parameter, e.g. appnetwindexes.itfce.switch.gw
+	Msg  string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config %s: %s", e.Path, e.Msg)
+}
+
+// TemplateError is returned when a template cannot be parsed or executed
+type TemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %s: %v", e.Template, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CablingFormatCSV writes the cabling plan as comma separated values
+	CablingFormatCSV = "csv"
+	// CablingFormatXLSX writes the cabling plan as an excel workbook
+	CablingFormatXLSX = "xlsx"
+)
+
+// CablingFormats holds the supported cabling plan formats
+var CablingFormats = []string{CablingFormatCSV, CablingFormatXLSX}
+
+// cablingHeader holds the columns of the cabling plan
+var cablingHeader = []string{"a node", "a port", "b node", "b port", "speed", "lag/esi", "vlan", "kind", "numa", "bond"}
+
+// ExportCabling generates the deployment and returns the cabling plan with a
+// row per physical link and its file name; the rows are sorted by the rack and
+// position labels of the nodes when they are set
+func ExportCabling(ctx context.Context, cfg *Config, format string, opts ...ParserOption) (string, []byte, error) {
+	if format != CablingFormatCSV && format != CablingFormatXLSX {
+		return "", nil, &ConfigError{Path: "format", Msg: fmt.Sprintf("unknown cabling format %s, supported formats are %s", format, strings.Join(CablingFormats, ", "))}
+	}
+	res, err := Generate(ctx, cfg, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := "paco"
+	if cfg.Name != nil && *cfg.Name != "" {
+		name = *cfg.Name
+	}
+	rows := append([][]string{cablingHeader}, cablingRows(res.Links)...)
+
+	fileName := name + "-cabling." + format
+	if format == CablingFormatXLSX {
+		b, err := writeXLSX("cabling", rows)
+		return fileName, b, err
+	}
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.WriteAll(rows); err != nil {
+		return "", nil, err
+	}
+	return fileName, buf.Bytes(), nil
+}
+
+// cablingRows returns a row per physical link, the logical links of the lags
+// are not cabled
+func cablingRows(links []*Link) [][]string {
+	esis := lagESIs(links)
+	cables := make([]*Link, 0, len(links))
+	for _, l := range links {
+		if l.vWire == nil || !*l.vWire {
+			continue
+		}
+		cables = append(cables, l)
+	}
+	// the links without rack labels keep the order of the topology
+	sort.SliceStable(cables, func(i, j int) bool {
+		return lessRackOrder(cables[i], cables[j])
+	})
+
+	rows := make([][]string, 0, len(cables))
+	for _, l := range cables {
+		vlan := "untagged"
+		if l.VlanTagging != nil && *l.VlanTagging {
+			vlan = *l.VlanID
+		}
+		numa := ""
+		if l.Numa != nil && (*l.A.Node.Kind == "linux" || *l.B.Node.Kind == "linux") {
+			numa = strconv.Itoa(*l.Numa)
+		}
+		bond := ""
+		if l.ClientName != nil {
+			bond = *l.ClientName
+		}
+		rows = append(rows, []string{
+			*l.A.Node.ShortName,
+			*l.A.RealName,
+			*l.B.Node.ShortName,
+			*l.B.RealName,
+			*l.Speed,
+			lagMembership(l, esis),
+			vlan,
+			*l.Kind,
+			numa,
+			bond,
+		})
+	}
+	return rows
+}
+
+// lagESIs returns the esi of the logical lag links by node and lag name
+func lagESIs(links []*Link) map[string]string {
+	esis := make(map[string]string)
+	for _, l := range links {
+		if l.Lag != nil && *l.Lag && l.LagName != nil && strings.Contains(*l.A.ShortName, "esi") {
+			esis[*l.A.Node.ShortName+"/"+*l.LagName] = *l.A.ShortName
+		}
+	}
+	return esis
+}
+
+// lagMembership returns the lag of a member link with its esi, e.g. lag1 (esi1),
+// empty for a link that is not part of a lag
+func lagMembership(l *Link, esis map[string]string) string {
+	if l.LagMemberLink == nil || !*l.LagMemberLink || l.LagName == nil {
+		return ""
+	}
+	if esi, ok := esis[*l.A.Node.ShortName+"/"+*l.LagName]; ok {
+		return *l.LagName + " (" + esi + ")"
+	}
+	return *l.LagName
+}
+
+// lessRackOrder orders the links by the rack and position labels of the a
+// node and then of the b node, the nodes without labels come last
+func lessRackOrder(a, b *Link) bool {
+	for _, n := range [][2]*Node{{a.A.Node, b.A.Node}, {a.B.Node, b.B.Node}} {
+		for _, label := range []string{"rack", "position"} {
+			va, oka := nodeLabel(n[0], label)
+			vb, okb := nodeLabel(n[1], label)
+			switch {
+			case oka && !okb:
+				return true
+			case !oka && okb:
+				return false
+			case oka && okb && va != vb:
+				return lessLabel(va, vb)
+			}
+		}
+	}
+	return false
+}
+
+// nodeLabel returns the value of a label of a node
+func nodeLabel(n *Node, label string) (string, bool) {
+	v, ok := n.Labels[label]
+	if !ok || v == nil {
+		return "", false
+	}
+	return *v, true
+}
+
+// lessLabel compares numeric labels as numbers, such that rack 2 comes before rack 10
+func lessLabel(a, b string) bool {
+	ia, erra := strconv.Atoi(a)
+	ib, errb := strconv.Atoi(b)
+	if erra == nil && errb == nil {
+		return ia < ib
+	}
+	return a < b
+}
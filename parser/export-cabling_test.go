@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportCabling(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, b, err := ExportCabling(context.Background(), cfg, CablingFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the header and the physical links, not the logical links of the lags
+	if len(rows) != 8 {
+		t.Fatalf("got %d rows, want 8:\n%s", len(rows), b)
+	}
+	for i, want := range map[int][]string{
+		1: {"leaf1", "ethernet-1/1", "master0", "eno5", "10G", "lag1 (esi1)", "untagged", "access", "0", "bond0"},
+		5: {"leaf1", "ethernet-1/49", "leaf2", "ethernet-1/49", "100G", "", "untagged", "isl", "", ""},
+	} {
+		if !reflect.DeepEqual(rows[i], want) {
+			t.Errorf("row %d: got %v, want %v", i, rows[i], want)
+		}
+	}
+
+	// the rows follow the racks of the nodes
+	cfg.Topology.Nodes["leaf1"].Labels["rack"] = StringPtr("10")
+	cfg.Topology.Nodes["leaf2"].Labels["rack"] = StringPtr("2")
+	_, b, err = ExportCabling(context.Background(), cfg, CablingFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err = csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"leaf2", "leaf2", "leaf2", "leaf1", "leaf1", "leaf1", "leaf1"} {
+		if rows[i+1][0] != want {
+			t.Errorf("row %d: got a node %s, want %s", i+1, rows[i+1][0], want)
+		}
+	}
+
+	fileName, b, err := ExportCabling(context.Background(), cfg, CablingFormatXLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileName != *cfg.Name+"-cabling.xlsx" {
+		t.Errorf("got file name %s", fileName)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		found = true
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheet, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(sheet), `<c r="F2" t="inlineStr"><is><t xml:space="preserve">lag1 (esi1)</t></is></c>`) {
+			t.Errorf("sheet misses the lag of row 2:\n%s", sheet)
+		}
+	}
+	if !found {
+		t.Error("workbook has no sheet")
+	}
+
+	if _, _, err := ExportCabling(context.Background(), cfg, "pdf"); err == nil {
+		t.Error("expected an error for an unknown cabling format")
+	}
+}
+
+func TestXlsxColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("%d: got %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultClabMgmtSubnet is the management subnet of a containerlab lab
+const DefaultClabMgmtSubnet = "172.20.20.0/24"
+
+// clabKinds maps the node kinds to the containerlab kinds
+var clabKinds = map[string]string{
+	"srl":     "srl",
+	"sros":    "vr-sros",
+	"vr-sros": "vr-sros",
+	"linux":   "linux",
+}
+
+var goClabTemplate = `# containerlab topology of the paco deployment {{.Name}}
+name: {{.Name}}
+mgmt:
+  network: {{.Name}}-mgmt
+  ipv4-subnet: {{.MgmtSubnet}}
+topology:
+  nodes:
+{{- range $index, $node := .Nodes}}
+    {{$node.Name}}:
+      kind: {{$node.Kind}}
+      image: {{$node.Image}}
+{{- if $node.Type}}
+      type: {{$node.Type}}
+{{- end}}
+{{- if $node.MgmtIPv4}}
+      mgmt-ipv4: {{$node.MgmtIPv4}}
+{{- end}}
+{{- if $node.StartupConfig}}
+      startup-config: {{$node.StartupConfig}}
+{{- end}}
+{{- end}}
+  links:
+{{- range $index, $link := .Links}}
+    - endpoints: ["{{index $link 0}}", "{{index $link 1}}"]
+{{- end}}
+`
+
+type clabNode struct {
+	Name          string
+	Kind          string
+	Image         string
+	Type          string
+	MgmtIPv4      string
+	StartupConfig string
+}
+
+// ExportClab generates the deployment and returns the files of a containerlab
+// lab with the same fabric: the topology and the startup configuration of the
+// switches, keyed by path relative to the lab directory. The management
+// addresses outside the management subnet or of its gateway are left to
+// containerlab.
+func ExportClab(ctx context.Context, cfg *Config, mgmtSubnet string, opts ...ParserOption) (map[string][]byte, error) {
+	if mgmtSubnet == "" {
+		mgmtSubnet = DefaultClabMgmtSubnet
+	}
+	_, mgmtNet, err := net.ParseCIDR(mgmtSubnet)
+	if err != nil {
+		return nil, &ConfigError{Path: "mgmt-subnet", Msg: err.Error()}
+	}
+	// containerlab uses the first address of the subnet for the bridge
+	mgmtGw, err := cidr.Host(mgmtNet, 1)
+	if err != nil {
+		return nil, &ConfigError{Path: "mgmt-subnet", Msg: err.Error()}
+	}
+	// the SR Linux startup configuration is loaded as flat set commands
+	res, err := Generate(ctx, cfg, append(opts, WithSwitchFormat(SwitchFormatCLI))...)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "paco"
+	if cfg.Name != nil && *cfg.Name != "" {
+		name = *cfg.Name
+	}
+	files := make(map[string][]byte)
+	nodes := make([]*clabNode, 0, len(res.Nodes))
+	for _, nodeName := range SortedKeys(res.Nodes) {
+		n := res.Nodes[nodeName]
+		kind, ok := clabKinds[*n.Kind]
+		if !ok {
+			return nil, &TopologyError{Element: "node " + nodeName, Msg: "kind " + *n.Kind + " is not supported by containerlab"}
+		}
+		node := &clabNode{
+			Name:  nodeName,
+			Kind:  kind,
+			Image: *n.Image,
+		}
+		// the type of a linux node is the distribution of the server
+		if kind != "linux" {
+			node.Type = *n.Type
+		}
+		if n.MgmtIPv4Address != nil && *n.MgmtIPv4Address != "" {
+			ip := net.ParseIP(*n.MgmtIPv4Address)
+			switch {
+			case ip == nil || !mgmtNet.Contains(ip):
+				log.Warnf("mgmt_ipv4 %s of node %s is not in the management subnet %s", *n.MgmtIPv4Address, nodeName, mgmtSubnet)
+			case ip.Equal(mgmtGw):
+				log.Warnf("mgmt_ipv4 %s of node %s is the gateway of the management subnet %s", *n.MgmtIPv4Address, nodeName, mgmtSubnet)
+			default:
+				node.MgmtIPv4 = *n.MgmtIPv4Address
+			}
+		}
+		if b, ok := res.SwitchConfigs[nodeName]; ok {
+			node.StartupConfig = path.Join("configs", nodeName+".cli")
+			files[node.StartupConfig] = b
+		}
+		// a partial configuration is added to the default configuration of the node
+		if b, ok := res.SrosConfigs[nodeName]; ok {
+			node.StartupConfig = path.Join("configs", nodeName+".partial.cfg")
+			files[node.StartupConfig] = b
+		}
+		nodes = append(nodes, node)
+	}
+
+	// the physical links, the LAGs are expanded in their member links
+	links := make([][2]string, 0, len(res.Links))
+	for _, l := range res.Links {
+		if l.vWire == nil || !*l.vWire {
+			continue
+		}
+		links = append(links, [2]string{
+			*l.A.Node.ShortName + ":" + *l.A.ShortName,
+			*l.B.Node.ShortName + ":" + *l.B.ShortName,
+		})
+	}
+
+	s := struct {
+		Name       string
+		MgmtSubnet string
+		Nodes      []*clabNode
+		Links      [][2]string
+	}{
+		Name:       name,
+		MgmtSubnet: mgmtSubnet,
+		Nodes:      nodes,
+		Links:      links,
+	}
+	buf := new(bytes.Buffer)
+	if err := goTemplates["clab"].Execute(buf, s); err != nil {
+		return nil, &TemplateError{Template: "clab", Err: err}
+	}
+	files[name+".clab.yml"] = buf.Bytes()
+	return files, nil
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestExportClab(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ExportClab(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := files[*cfg.Name+".clab.yml"]
+	if !ok {
+		t.Fatalf("got files %v, want %s.clab.yml", SortedKeys(files), *cfg.Name)
+	}
+	lab := struct {
+		Name     string `yaml:"name"`
+		Topology struct {
+			Nodes map[string]struct {
+				Kind          string `yaml:"kind"`
+				Image         string `yaml:"image"`
+				MgmtIPv4      string `yaml:"mgmt-ipv4"`
+				StartupConfig string `yaml:"startup-config"`
+			} `yaml:"nodes"`
+			Links []struct {
+				Endpoints []string `yaml:"endpoints"`
+			} `yaml:"links"`
+		} `yaml:"topology"`
+	}{}
+	if err := yaml.Unmarshal(b, &lab); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := lab.Topology.Nodes
+	if nodes["leaf1"].Kind != "srl" || nodes["leaf1"].Image != srlDefaultImage || nodes["leaf1"].MgmtIPv4 != "172.20.20.3" {
+		t.Errorf("got leaf1 %+v", nodes["leaf1"])
+	}
+	if nodes["dcgw2"].Kind != "vr-sros" || nodes["master0"].Kind != "linux" {
+		t.Errorf("got dcgw2 %+v, master0 %+v", nodes["dcgw2"], nodes["master0"])
+	}
+	// outside the management subnet and the gateway of the subnet
+	if nodes["master0"].MgmtIPv4 != "" || nodes["dcgw1"].MgmtIPv4 != "" {
+		t.Errorf("got mgmt-ipv4 %s for master0 and %s for dcgw1, want none", nodes["master0"].MgmtIPv4, nodes["dcgw1"].MgmtIPv4)
+	}
+	// the startup configurations are part of the lab
+	for node, want := range map[string]string{"leaf1": "configs/leaf1.cli", "dcgw1": "configs/dcgw1.partial.cfg"} {
+		if nodes[node].StartupConfig != want {
+			t.Errorf("%s: got startup-config %s, want %s", node, nodes[node].StartupConfig, want)
+		}
+		if _, ok := files[want]; !ok {
+			t.Errorf("%s is not exported", want)
+		}
+	}
+	if !strings.HasPrefix(string(files["configs/leaf1.cli"]), "set / ") {
+		t.Error("the leaf1 startup configuration is not in the cli format")
+	}
+
+	// the lag member links, not the lags
+	links := make(map[string]bool)
+	for _, l := range lab.Topology.Links {
+		links[strings.Join(l.Endpoints, " ")] = true
+	}
+	for _, want := range []string{"leaf1:e1-1 master0:eno5", "leaf2:e1-1 master0:eno6", "leaf1:e1-50 dcgw1:eth1"} {
+		if !links[want] {
+			t.Errorf("link %s is not exported", want)
+		}
+	}
+	for l := range links {
+		if strings.Contains(l, "lag") || strings.Contains(l, "bond") {
+			t.Errorf("got logical link %s", l)
+		}
+	}
+
+	if _, err := ExportClab(context.Background(), cfg, "172.20.20.0"); err == nil {
+		t.Error("expected an error for an invalid management subnet")
+	}
+}
+
+func TestExportClabConfigs(t *testing.T) {
+	for _, config := range testConfigs {
+		t.Run(goldenName(config), func(t *testing.T) {
+			cfg, err := LoadConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files, err := ExportClab(context.Background(), cfg, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			lab := struct {
+				Topology struct {
+					Nodes map[string]struct {
+						Kind          string `yaml:"kind"`
+						StartupConfig string `yaml:"startup-config"`
+					} `yaml:"nodes"`
+				} `yaml:"topology"`
+			}{}
+			if err := yaml.Unmarshal(files[*cfg.Name+".clab.yml"], &lab); err != nil {
+				t.Fatal(err)
+			}
+			// every switch and gateway starts with its rendered configuration
+			for _, node := range SortedKeys(lab.Topology.Nodes) {
+				n := lab.Topology.Nodes[node]
+				if n.Kind == "linux" {
+					continue
+				}
+				if len(files[n.StartupConfig]) == 0 {
+					t.Errorf("%s: startup-config %q is not exported", node, n.StartupConfig)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DiagramFormatDot draws the topology as an undirected Graphviz graph
+	DiagramFormatDot = "dot"
+	// DiagramFormatMermaid draws the topology as a Mermaid flowchart
+	DiagramFormatMermaid = "mermaid"
+)
+
+// DiagramFormats holds the supported diagram formats
+var DiagramFormats = []string{DiagramFormatDot, DiagramFormatMermaid}
+
+// diagramExtensions holds the file extension per diagram format
+var diagramExtensions = map[string]string{
+	DiagramFormatDot:     "dot",
+	DiagramFormatMermaid: "mmd",
+}
+
+var goDiagramDotTemplate = `// topology of the paco deployment {{.Name}}
+graph "{{.Name}}" {
+  rankdir=TB;
+  node [shape=box];
+{{- range $index, $group := .Groups}}
+  subgraph "cluster_{{$group.Name}}" {
+    label="{{$group.Name}}";
+{{- range $index, $node := $group.Nodes}}
+    "{{$node.Name}}" [label="{{$node.Name}}\n{{$node.Kind}}"];
+{{- end}}
+  }
+{{- end}}
+{{- range $index, $edge := .Edges}}
+  "{{$edge.A}}" -- "{{$edge.B}}" [label="{{join "\\n" $edge.Label}}"];
+{{- end}}
+}
+`
+
+var goDiagramMermaidTemplate = `%% topology of the paco deployment {{.Name}}
+graph TB
+{{- range $index, $group := .Groups}}
+  subgraph {{$group.ID}}["{{$group.Name}}"]
+{{- range $index, $node := $group.Nodes}}
+    {{$node.ID}}["{{$node.Name}}<br/>{{$node.Kind}}"]
+{{- end}}
+  end
+{{- end}}
+{{- range $index, $edge := .Edges}}
+  {{$edge.AID}} ---|"{{join "<br/>" $edge.Label}}"| {{$edge.BID}}
+{{- end}}
+`
+
+type diagramGroup struct {
+	ID    string
+	Name  string
+	Nodes []*diagramNode
+}
+
+type diagramNode struct {
+	ID   string
+	Name string
+	Kind string
+}
+
+type diagramEdge struct {
+	A     string
+	AID   string
+	B     string
+	BID   string
+	Label []string
+}
+
+var mermaidIDRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaidID returns a mermaid identifier for a name, the dashes of e.g.
+// leaf-grp1 would be read as a link
+func mermaidID(name string) string {
+	return mermaidIDRegexp.ReplaceAllString(name, "_")
+}
+
+// ExportDiagram generates the deployment and draws the nodes grouped by their
+// target and the physical links with their interfaces, lag, speed, server
+// flags and isl addresses; it returns the file name and the diagram
+func ExportDiagram(ctx context.Context, cfg *Config, format string, opts ...ParserOption) (string, []byte, error) {
+	ext, ok := diagramExtensions[format]
+	if !ok {
+		return "", nil, &ConfigError{Path: "format", Msg: fmt.Sprintf("unknown diagram format %s, supported formats are %s", format, strings.Join(DiagramFormats, ", "))}
+	}
+	res, err := Generate(ctx, cfg, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := "paco"
+	if cfg.Name != nil && *cfg.Name != "" {
+		name = *cfg.Name
+	}
+
+	groups := make(map[string]*diagramGroup)
+	for _, nodeName := range SortedKeys(res.Nodes) {
+		n := res.Nodes[nodeName]
+		if _, ok := groups[*n.Target]; !ok {
+			groups[*n.Target] = &diagramGroup{
+				ID:    mermaidID(*n.Target),
+				Name:  *n.Target,
+				Nodes: make([]*diagramNode, 0),
+			}
+		}
+		groups[*n.Target].Nodes = append(groups[*n.Target].Nodes, &diagramNode{
+			ID:   mermaidID(nodeName),
+			Name: nodeName,
+			Kind: *n.Kind,
+		})
+	}
+
+	esis := lagESIs(res.Links)
+	edges := make([]*diagramEdge, 0)
+	for _, l := range res.Links {
+		// the member links are drawn, not the logical link of the lag
+		if l.vWire == nil || !*l.vWire {
+			continue
+		}
+		edges = append(edges, &diagramEdge{
+			A:     *l.A.Node.ShortName,
+			AID:   mermaidID(*l.A.Node.ShortName),
+			B:     *l.B.Node.ShortName,
+			BID:   mermaidID(*l.B.Node.ShortName),
+			Label: diagramLabel(l, esis),
+		})
+	}
+
+	s := struct {
+		Name   string
+		Groups []*diagramGroup
+		Edges  []*diagramEdge
+	}{
+		Name:   name,
+		Groups: make([]*diagramGroup, 0, len(groups)),
+		Edges:  edges,
+	}
+	for _, target := range SortedKeys(groups) {
+		s.Groups = append(s.Groups, groups[target])
+	}
+
+	tmplName := "diagram-" + format
+	buf := new(bytes.Buffer)
+	if err := goTemplates[tmplName].Execute(buf, s); err != nil {
+		return "", nil, &TemplateError{Template: tmplName, Err: err}
+	}
+	return name + "." + ext, buf.Bytes(), nil
+}
+
+// diagramLabel returns the lines of the label of a physical link
+func diagramLabel(l *Link, esis map[string]string) []string {
+	label := []string{*l.A.RealName + " - " + *l.B.RealName}
+	if l.LagMemberLink != nil && *l.LagMemberLink && l.LagName != nil {
+		lag := *l.LagName
+		if l.ClientName != nil && *l.ClientName != "" {
+			lag += " - " + *l.ClientName
+		}
+		if esi, ok := esis[*l.A.Node.ShortName+"/"+*l.LagName]; ok {
+			lag += " (" + esi + ")"
+		}
+		label = append(label, lag)
+	}
+
+	props := make([]string, 0)
+	if l.Speed != nil && *l.Speed != "" {
+		props = append(props, *l.Speed)
+	}
+	if l.Sriov != nil && *l.Sriov {
+		props = append(props, "sriov")
+	}
+	if l.IPVlan != nil && *l.IPVlan {
+		props = append(props, "ipvlan")
+	}
+	// the numa of the server nic
+	if l.Numa != nil && (*l.A.Node.Kind == "linux" || *l.B.Node.Kind == "linux") {
+		props = append(props, fmt.Sprintf("numa %d", *l.Numa))
+	}
+	if len(props) > 0 {
+		label = append(label, strings.Join(props, ", "))
+	}
+
+	if a := addressPrefix(l.A.IPv4Address, l.A.IPv4PrefixLength); a != "" {
+		label = append(label, a+" - "+addressPrefix(l.B.IPv4Address, l.B.IPv4PrefixLength))
+	}
+	if a := addressPrefix(l.A.IPv6Address, l.A.IPv6PrefixLength); a != "" {
+		label = append(label, a+" - "+addressPrefix(l.B.IPv6Address, l.B.IPv6PrefixLength))
+	}
+	return label
+}
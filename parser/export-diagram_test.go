@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExportDiagram(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for format, want := range map[string][]string{
+		DiagramFormatDot: {
+			"  subgraph \"cluster_leaf-grp1\" {\n",
+			`  "leaf1" -- "master0" [label="ethernet-1/1 - eno5\nlag1 - bond0 (esi1)\n10G, sriov, ipvlan, numa 0"];`,
+			`  "leaf1" -- "leaf2" [label="ethernet-1/49 - ethernet-1/49\n100G\n100.64.0.0/31 - 100.64.0.1/31\n3100:64::/127 - 3100:64::1/127"];`,
+		},
+		DiagramFormatMermaid: {
+			"  subgraph leaf_grp1[\"leaf-grp1\"]\n",
+			`  leaf2 ---|"ethernet-1/1 - eno6<br/>lag1 - bond0 (esi1)<br/>10G, sriov, ipvlan, numa 0"| master0`,
+		},
+	} {
+		fileName, b, err := ExportDiagram(context.Background(), cfg, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fileName != *cfg.Name+"."+diagramExtensions[format] {
+			t.Errorf("%s: got file name %s", format, fileName)
+		}
+		for _, line := range want {
+			if !strings.Contains(string(b), line) {
+				t.Errorf("%s misses %q:\n%s", format, line, b)
+			}
+		}
+		// the member links of the lags are drawn, not the logical links
+		if n := strings.Count(string(b), "| master0\n") + strings.Count(string(b), `-- "master0"`); n != 4 {
+			t.Errorf("%s: got %d links to master0, want 4", format, n)
+		}
+	}
+
+	if _, _, err := ExportDiagram(context.Background(), cfg, "png"); err == nil {
+		t.Error("expected an error for an unknown diagram format")
+	}
+}
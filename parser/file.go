@@ -27,7 +27,7 @@ func (p *Parser) GetConfig(file *string) error {
 	}
 	log.Debug(fmt.Sprintf("Config file contents:\n%s\n", yamlFile))
 
-	err = yaml.Unmarshal(yamlFile, p.Config)
+	err = yaml.UnmarshalStrict(yamlFile, p.Config)
 	if err != nil {
 		return err
 	}
@@ -39,14 +39,39 @@ func (p *Parser) GetConfig(file *string) error {
 		fullName: file,
 		name:     &filename[0],
 	}
-	p.NextAS = p.Config.Infrastructure.Protocols.AsPool[0] 
-	*p.NextAS++
+	return p.initConfig()
+}
+
+// initConfig initializes the parser state that is derived from p.Config
+func (p *Parser) initConfig() error {
+	if p.Config.Infrastructure == nil || p.Config.Infrastructure.Protocols == nil || len(p.Config.Infrastructure.Protocols.AsPool) == 0 {
+		return &ConfigError{Path: "infrastructure.protocols.as_pool", Msg: "as_pool must contain at least 1 AS"}
+	}
+	// the AS numbers are allocated from the pool without changing the config
+	p.NextAS = Uint32Ptr(*p.Config.Infrastructure.Protocols.AsPool[0] + 1)
 	return nil
 }
 
-// CreateDirectory creates a directory
-func (p *Parser) CreateDirectory(path string, perm os.FileMode) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		os.Mkdir(path, perm)
+// LoadConfig reads a paco deployment file
+func LoadConfig(file string) (*Config, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
 	}
+	return ParseConfig(b)
+}
+
+// ParseConfig parses a paco deployment in yaml, unknown fields are rejected
+func ParseConfig(b []byte) (*Config, error) {
+	cfg := new(Config)
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// CreateDirectory creates a directory, including the parent directories that
+// do not exist yet
+func (p *Parser) CreateDirectory(path string, perm os.FileMode) error {
+	return p.Sink.MkdirAll(path, perm)
 }
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Result is the outcome of Generate: the resolved topology, the ip allocations
+// and the rendered manifests
+type Result struct {
+	Nodes        map[string]*Node
+	Links        []*Link
+	Workloads    map[string]*Workload
+	ClientGroups map[string]*ClientGroup
+	// Allocations holds the loopback, isl, workload and application allocations
+	Allocations []*IPAMStateEntry
+	// ApplicationIPAM holds the application allocations per multus workload,
+	// network type and subnet
+	ApplicationIPAM map[string]map[string]map[string]*IpamApp
+	// Cnfs holds the application configuration per enabled cnf
+	Cnfs map[string]*AppConfig
+	// SriovResources holds the sriov pf names per server interface, numa and switch
+	SriovResources map[string]map[int]map[string][]*string
+
+	// SwitchResources holds the decoded k8s SR Linux resources per node, a
+	// resource of a group target is listed for every node of the group; the
+	// kustomizations are only in Files
+	SwitchResources map[string][]*Manifest
+	// HelmValues holds the decoded helm values per cnf
+	HelmValues map[string]map[string]interface{}
+	// Kustomize holds the decoded kustomize manifests per cnf in path order
+	Kustomize map[string][]*Manifest
+	// ServerResources holds the server manifests keyed by file name
+	ServerResources map[string][]byte
+	// SwitchConfigs holds the native SR Linux configuration per node in the
+	// json or cli switch format
+	SwitchConfigs map[string][]byte
+	// GnmiRequests holds the gnmic set request file per node in the gnmi
+	// switch format
+	GnmiRequests map[string][]byte
+	// SrosConfigs holds the MD-CLI configuration per SR OS gateway
+	SrosConfigs map[string][]byte
+	// Files holds all output files keyed by path relative to the output root
+	Files map[string][]byte
+}
+
+// Generate parses the deployment in-process and returns the result without
+// writing any files; the options can set e.g. the template directory or the
+// ipam state, the config is not modified
+func Generate(ctx context.Context, cfg *Config, opts ...ParserOption) (*Result, error) {
+	// the parser fills in the config while parsing, so it works on a copy
+	cfg, err := copyConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mem := NewMemSink()
+	opts = append(opts, WithConfig(cfg), WithOutputSink(mem))
+	p, err := NewParser(opts...)
+	if err != nil {
+		return nil, err
+	}
+	// keep track of the allocations, also when no ipam state file is used
+	if p.IPAMState == nil {
+		p.IPAMState = &IPAMState{Allocations: make([]*IPAMStateEntry, 0)}
+	}
+	if err := p.run(ctx); err != nil {
+		return nil, err
+	}
+	return p.result(mem.Files())
+}
+
+// GenerateYAML is Generate for a paco deployment in yaml
+func GenerateYAML(ctx context.Context, b []byte, opts ...ParserOption) (*Result, error) {
+	cfg, err := ParseConfig(b)
+	if err != nil {
+		return nil, err
+	}
+	return Generate(ctx, cfg, opts...)
+}
+
+// WriteTo writes the output files to the sink in path order, the sink is not closed
+func (r *Result) WriteTo(sink OutputSink) error {
+	return WriteFiles(sink, r.Files)
+}
+
+// WriteFiles writes the files, keyed by path, to the sink in path order; the
+// sink is not closed
+func WriteFiles(sink OutputSink, files map[string][]byte) error {
+	for _, name := range SortedKeys(files) {
+		if err := sink.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			return err
+		}
+		f, err := sink.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) result(files map[string][]byte) (*Result, error) {
+	p.IPAMState.sort()
+	r := &Result{
+		Nodes:           p.Nodes,
+		Links:           p.Links,
+		Workloads:       p.Workloads,
+		ClientGroups:    p.ClientGroups,
+		Allocations:     p.IPAMState.Allocations,
+		ApplicationIPAM: p.DeploymentIPAM,
+		Cnfs:            p.AppConfigs,
+		SriovResources:  p.ClientServer2NetworkLinks["sriov"],
+		SwitchResources: make(map[string][]*Manifest),
+		HelmValues:      make(map[string]map[string]interface{}),
+		Kustomize:       make(map[string][]*Manifest),
+		ServerResources: make(map[string][]byte),
+		SwitchConfigs:   make(map[string][]byte),
+		GnmiRequests:    make(map[string][]byte),
+		SrosConfigs:     make(map[string][]byte),
+		Files:           files,
+	}
+	// in path order, the manifests of a node or a cnf keep the order of the files
+	for _, name := range SortedKeys(files) {
+		b := files[name]
+		name := filepath.ToSlash(name)
+		dir, file := path.Split(name)
+		switch {
+		case strings.HasPrefix(name, switchDir+"/") && file != "kustomization.yaml":
+			manifests, err := decodeManifests(name, b)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range manifests {
+				for _, nodeName := range p.targetNodes(m.Target) {
+					r.SwitchResources[nodeName] = append(r.SwitchResources[nodeName], m)
+				}
+			}
+		case dir == switchConfigDir+"/":
+			r.SwitchConfigs[strings.TrimSuffix(file, path.Ext(file))] = b
+		case dir == switchGnmiDir+"/" && path.Ext(file) == ".json":
+			r.GnmiRequests[strings.TrimSuffix(file, ".json")] = b
+		case dir == appValuesDir+"/":
+			values, err := decodeValues(name, b)
+			if err != nil {
+				return nil, err
+			}
+			r.HelmValues[strings.TrimSuffix(file, "_values.yaml")] = values
+		case strings.HasPrefix(name, appKustomizeDir+"/"):
+			manifests, err := decodeManifests(name, b)
+			if err != nil {
+				return nil, err
+			}
+			cnf := path.Base(dir)
+			r.Kustomize[cnf] = append(r.Kustomize[cnf], manifests...)
+		case dir == serverDir+"/":
+			r.ServerResources[file] = b
+		case dir == srosDir+"/":
+			r.SrosConfigs[strings.TrimSuffix(file, ".cfg")] = b
+		}
+	}
+	return r, nil
+}
+
+// copyConfig returns a deep copy of the config
+func copyConfig(cfg *Config) (*Config, error) {
+	if cfg == nil {
+		return nil, &ConfigError{Path: ".", Msg: "config is required"}
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(b)
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, config := range testConfigs {
+		config := config
+		t.Run(config, func(t *testing.T) {
+			output := t.TempDir()
+			runParser(t, config, output)
+			want := readTree(t, output)
+			// without an ipam state file the state is only kept in the result
+			delete(want, IPAMStateFileName)
+
+			cfg, err := LoadConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := yaml.Marshal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := Generate(context.Background(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareTrees(t, want, res.Files)
+
+			after, err := yaml.Marshal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(before, after) {
+				t.Error("config was modified by Generate")
+			}
+
+			if len(res.Allocations) == 0 {
+				t.Error("no allocations")
+			}
+			if len(res.Nodes) == 0 || len(res.Links) == 0 {
+				t.Error("no topology")
+			}
+			for cnf := range res.Cnfs {
+				if _, ok := res.HelmValues[cnf]; !ok {
+					t.Errorf("%s: no helm values", cnf)
+				}
+			}
+			if _, ok := res.Files[switchDir+"/infra/kustomization.yaml"]; !ok {
+				t.Error("no switch kustomization")
+			}
+			if len(res.SwitchResources) == 0 {
+				t.Error("no switch resources")
+			}
+			for nodeName, manifests := range res.SwitchResources {
+				for _, m := range manifests {
+					// a resource of a group target is listed per node of the group
+					if m.Target != nodeName && *res.Nodes[nodeName].Target != m.Target {
+						t.Errorf("%s: got resource %s of target %s", nodeName, m.Name, m.Target)
+					}
+				}
+			}
+			for cnf, manifests := range res.Kustomize {
+				for _, m := range manifests {
+					if m.Kind == "" || m.Name == "" {
+						t.Errorf("%s: %s has a manifest without kind or name", cnf, m.Path)
+					}
+				}
+			}
+
+			mem := NewMemSink()
+			if err := res.WriteTo(mem); err != nil {
+				t.Fatal(err)
+			}
+			compareTrees(t, res.Files, mem.Files())
+		})
+	}
+}
+
+func TestGenerateYAML(t *testing.T) {
+	b, err := ioutil.ReadFile(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := GenerateYAML(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cnf := range []string{"upf", "smf", "amf"} {
+		if _, ok := res.HelmValues[cnf]; !ok {
+			t.Errorf("%s: no helm values", cnf)
+		}
+	}
+
+	if _, err := GenerateYAML(context.Background(), []byte("topology: [")); err == nil {
+		t.Error("expected an error for invalid yaml")
+	}
+}
+
+func TestGenerateCanceled(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Generate(ctx, cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in parser/testdata/golden")
+
+// goldenDir holds the expected output per sample config, relative to the
+// repository root
+const goldenDir = "parser/testdata/golden"
+
+// goldenTrees are the output directories that are compared against the golden files
+var goldenTrees = []string{
+	"switch/kustomize",
+	"app-values",
+	"app-kustomize",
+	"server",
+	"app-ipam-csv",
+	"sros",
+}
+
+// goldenName returns the name of the golden directory of the config file
+func goldenName(config string) string {
+	return strings.TrimSuffix(filepath.Base(config), filepath.Ext(config))
+}
+
+// writeTree replaces the directory with the files keyed by relative path
+func writeTree(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, b, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	for _, config := range testConfigs {
+		t.Run(goldenName(config), func(t *testing.T) {
+			output := t.TempDir()
+			runParser(t, config, output)
+
+			for _, tree := range goldenTrees {
+				t.Run(tree, func(t *testing.T) {
+					got := readTree(t, filepath.Join(output, tree))
+					golden := filepath.Join(goldenDir, goldenName(config), tree)
+					if *update {
+						writeTree(t, golden, got)
+						return
+					}
+					if _, err := os.Stat(golden); err != nil {
+						t.Fatalf("%v, run the tests with -update to create the golden files", err)
+					}
+					compareTrees(t, readTree(t, golden), got)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// IPAMStateFileName is the name of the ipam state file in the output directory
+const IPAMStateFileName = "ipam-state.yaml"
+
+// IPAMState holds the ipam allocations of previous runs, such that re-running
+// the parser keeps the addresses of the existing nodes, links and applications
+type IPAMState struct {
+	Allocations []*IPAMStateEntry `yaml:"allocations"`
+}
+
+// IPAMStateEntry is a single allocation in the ipam state
+type IPAMStateEntry struct {
+	IPAM     string `yaml:"ipam"` // loopback, isl, the workload ipam name or application
+	Cidr     string `yaml:"cidr"`
+	Node     string `yaml:"node,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"`
+	Workload string `yaml:"workload,omitempty"`
+	Network  string `yaml:"network,omitempty"` // ipvlan, sriov1.1, loopback, etc
+	Cnf      string `yaml:"cnf,omitempty"`
+	Usage    string `yaml:"usage,omitempty"`
+	Address  string `yaml:"address"` // address or prefix
+	// a released allocation is no longer used by the deployment, it stays
+	// reserved until it is reclaimed explicitly
+	Released bool `yaml:"released,omitempty"`
+
+	inUse bool
+}
+
+// LoadIPAMState reads the ipam state file, a missing file results in an empty state
+func LoadIPAMState(file string) (*IPAMState, error) {
+	s := &IPAMState{
+		Allocations: make([]*IPAMStateEntry, 0),
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, s); err != nil {
+		return nil, &ConfigError{Path: file, Msg: err.Error()}
+	}
+	return s, nil
+}
+
+// Write writes the ipam state file
+func (s *IPAMState) Write(file string) error {
+	return s.write(NewDirSink(filepath.Dir(file)), filepath.Base(file))
+}
+
+func (s *IPAMState) write(sink OutputSink, file string) error {
+	if s == nil {
+		return nil
+	}
+	s.sort()
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := sink.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	f, err := sink.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *IPAMState) sort() {
+	sort.SliceStable(s.Allocations, func(i, j int) bool {
+		return s.Allocations[i].sortKey() < s.Allocations[j].sortKey()
+	})
+}
+
+// Release marks the allocations that were not used in this run as released
+// and returns the allocations that got released in this run
+func (s *IPAMState) Release() []*IPAMStateEntry {
+	released := make([]*IPAMStateEntry, 0)
+	if s == nil {
+		return released
+	}
+	for _, e := range s.Allocations {
+		if !e.inUse && !e.Released {
+			e.Released = true
+			released = append(released, e)
+		}
+	}
+	return released
+}
+
+// Reclaim removes the released allocations from the state, such that their
+// addresses can be allocated again, and returns the reclaimed allocations
+func (s *IPAMState) Reclaim() []*IPAMStateEntry {
+	reclaimed := make([]*IPAMStateEntry, 0)
+	if s == nil {
+		return reclaimed
+	}
+	allocations := make([]*IPAMStateEntry, 0, len(s.Allocations))
+	for _, e := range s.Allocations {
+		if e.Released {
+			reclaimed = append(reclaimed, e)
+		} else {
+			allocations = append(allocations, e)
+		}
+	}
+	s.Allocations = allocations
+	return reclaimed
+}
+
+// lookup returns the allocation of the owner described by the key, the
+// address of the key is not taken into account
+func (s *IPAMState) lookup(key *IPAMStateEntry) *IPAMStateEntry {
+	if s == nil {
+		return nil
+	}
+	for _, e := range s.Allocations {
+		if e.sameOwner(key) {
+			e.inUse = true
+			e.Released = false
+			return e
+		}
+	}
+	return nil
+}
+
+// record adds the allocation to the state, if it is not there yet
+func (s *IPAMState) record(entry *IPAMStateEntry) {
+	if s == nil {
+		return
+	}
+	for _, e := range s.Allocations {
+		if e.sameOwner(entry) && e.Address == entry.Address {
+			e.inUse = true
+			e.Released = false
+			return
+		}
+	}
+	entry.inUse = true
+	s.Allocations = append(s.Allocations, entry)
+}
+
+// reserved returns true if the address or prefix is allocated in the state,
+// released allocations are reserved until they are reclaimed
+func (s *IPAMState) reserved(ipam, cidr, address string) bool {
+	if s == nil {
+		return false
+	}
+	for _, e := range s.Allocations {
+		if e.IPAM == ipam && e.Cidr == cidr && e.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *IPAMStateEntry) sameOwner(o *IPAMStateEntry) bool {
+	return e.IPAM == o.IPAM &&
+		e.Cidr == o.Cidr &&
+		e.Node == o.Node &&
+		e.Endpoint == o.Endpoint &&
+		e.Workload == o.Workload &&
+		e.Network == o.Network &&
+		e.Cnf == o.Cnf &&
+		e.Usage == o.Usage
+}
+
+func (e *IPAMStateEntry) sortKey() string {
+	return e.IPAM + "|" + e.Cidr + "|" + e.Node + "|" + e.Endpoint + "|" + e.Workload + "|" + e.Network + "|" + e.Cnf + "|" + e.Usage + "|" + e.Address
+}
+
+// String returns a short description of the owner and the address of the allocation
+func (e *IPAMStateEntry) String() string {
+	owner := e.Node + ":" + e.Endpoint
+	if e.Workload != "" {
+		owner = e.Workload + "/" + e.Network + "/" + e.Cnf + "/" + e.Usage
+	}
+	return e.IPAM + " " + owner + " " + e.Address
+}
+
+// recordDeploymentIPAM records the application allocations in the ipam state,
+// these addresses are derived from the appnetwindexes and hence stable
+func (p *Parser) recordDeploymentIPAM() {
+	for wlName, netwInfo := range p.DeploymentIPAM {
+		for netwType, subnetInfo := range netwInfo {
+			for subnet, ipamInfo := range subnetInfo {
+				for _, ipAlloc := range ipamInfo.AllocatedIPs {
+					p.IPAMState.record(&IPAMStateEntry{
+						IPAM:     "application",
+						Cidr:     subnet,
+						Workload: wlName,
+						Network:  netwType,
+						Cnf:      *ipAlloc.Application,
+						Usage:    *ipAlloc.Usage,
+						Address:  *ipAlloc.IPAddress,
+					})
+				}
+			}
+		}
+	}
+}
+
+// WriteIPAMState releases the allocations that are no longer used and writes
+// the ipam state file
+func (p *Parser) WriteIPAMState() error {
+	if p.IPAMState == nil || p.IPAMStateFile == nil {
+		return nil
+	}
+	for _, e := range p.IPAMState.Release() {
+		log.Infof("ipam allocation released: %s", e)
+	}
+	log.Infof("Writing ipam state %s...", IPAMStateFileName)
+	return p.IPAMState.write(p.Sink, IPAMStateFileName)
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"strconv"
 
@@ -13,6 +14,10 @@ import (
 type Ipam struct {
 	//
 	IP map[string]*IpamAlloc
+	// Name of the ipam in the State, the State holds the allocations of previous
+	// runs; a nil State allocates sequentially from the cidr
+	Name  *string
+	State *IPAMState
 }
 
 // IpamAlloc struct that holds the allocation of the prefix
@@ -34,7 +39,7 @@ func NewIPAM(netwInfo *NetworkInfo) (*Ipam, error) {
 
 	// initialize prefix Length
 	for _, Ipv4Cidr := range netwInfo.Ipv4Cidr {
-		if *netwInfo.AddressingSchema == "dual-stack" || *netwInfo.AddressingSchema == "ipv4-only" {
+		if *netwInfo.AddressingSchema == AddressingDualStack || *netwInfo.AddressingSchema == AddressingIPv4Only {
 			if err = initializeIPAM(netwInfo.Kind,
 				StringPtr("ipv4"),
 				Ipv4Cidr,                       // IPv4 CIDR
@@ -45,7 +50,7 @@ func NewIPAM(netwInfo *NetworkInfo) (*Ipam, error) {
 		}
 	}
 	for _, Ipv6Cidr := range netwInfo.Ipv6Cidr {
-		if *netwInfo.AddressingSchema == "dual-stack" || *netwInfo.AddressingSchema == "ipv6-only" {
+		if *netwInfo.AddressingSchema == AddressingDualStack || *netwInfo.AddressingSchema == AddressingIPv6Only {
 			if err = initializeIPAM(netwInfo.Kind,
 				StringPtr("ipv6"),
 				Ipv6Cidr,                       // IPv6 CIDR
@@ -122,15 +127,38 @@ func (ipam *Ipam) IPAMAllocPrefixPerLink(version, prefix *string, link *Link) (e
 	log.Debugf("IPAMLinkPrefixAlloc: %s, %s, %s ...", *link.Kind, *version, *prefix)
 	//p.IPAM[*link.Kind].IP[*prefix].NextFreeSubnet
 
-	ipNet := ipam.IP[*prefix].NextFreeSubnet
+	if _, ok := ipam.IP[*prefix]; !ok {
+		return &ConfigError{Path: *link.Kind, Msg: fmt.Sprintf("%s cidr %s is not initialized for the addressing schema", *version, *prefix)}
+	}
+	// existing links keep the prefix of the ipam state
+	key := &IPAMStateEntry{
+		IPAM:     ipam.name(),
+		Cidr:     *prefix,
+		Node:     *link.A.Node.ShortName,
+		Endpoint: *link.A.ShortName,
+	}
+	var ipNet *net.IPNet
+	if entry := ipam.State.lookup(key); entry != nil {
+		_, ipNet, err = net.ParseCIDR(entry.Address)
+		if err != nil {
+			return err
+		}
+	} else {
+		ipNet, err = ipam.nextFreeSubnet(*link.Kind, *prefix)
+		if err != nil {
+			return err
+		}
+		key.Address = ipNet.String()
+		ipam.State.record(key)
+	}
 	ipMask, length := ipNet.Mask.Size()
 
 	if length != 32 && *version == "ipv4" {
-		log.Error("Not an IPv4 address")
+		return &ConfigError{Path: *link.Kind, Msg: fmt.Sprintf("cidr %s is not an ipv4 cidr", *prefix)}
 	}
 
 	if length != 128 && *version == "ipv6" {
-		log.Error("Not an IPv6 address")
+		return &ConfigError{Path: *link.Kind, Msg: fmt.Sprintf("cidr %s is not an ipv6 cidr", *prefix)}
 	}
 
 	switch ipMask {
@@ -152,12 +180,18 @@ func (ipam *Ipam) IPAMAllocPrefixPerLink(version, prefix *string, link *Link) (e
 		// for a non /31 ipv4 or /127 ipv6 the A address starts with .1
 		if *version == "ipv4" {
 			link.A.IPv4Address, err = incrementIP(StringPtr(ipNet.IP.To4().String()), StringPtr(ipNet.String()))
+			if err != nil {
+				return err
+			}
 			link.B.IPv4Address, err = incrementIP(link.A.IPv4Address, StringPtr(ipNet.String()))
 			if err != nil {
 				return err
 			}
 		} else { // version is ipv6
 			link.A.IPv6Address, err = incrementIP(StringPtr(ipNet.IP.To16().String()), StringPtr(ipNet.String()))
+			if err != nil {
+				return err
+			}
 			link.B.IPv6Address, err = incrementIP(link.A.IPv6Address, StringPtr(ipNet.String()))
 			if err != nil {
 				return err
@@ -186,30 +220,56 @@ func (ipam *Ipam) IPAMAllocPrefixPerLink(version, prefix *string, link *Link) (e
 		link.B.IPv6NeighborPrefix = link.A.IPv6Prefix
 		link.A.IPv6NeighborPrefix = link.B.IPv6Prefix
 	}
-	var b bool
-	ipam.IP[*prefix].NextFreeSubnet, b = cidr.NextSubnet(ipNet, ipMask)
-	if b == true {
-		log.Fatal("Next IP cannot be allocated")
+	return nil
+}
+
+// nextFreeSubnet returns the next free subnet of the cidr, skipping the subnets
+// that are allocated in the ipam state
+func (ipam *Ipam) nextFreeSubnet(kind, prefix string) (*net.IPNet, error) {
+	_, ipPrefixNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, err
 	}
-	_, ipPrefixNet, err := net.ParseCIDR(*prefix)
-	if !ipPrefixNet.Contains(ipam.IP[*prefix].NextFreeSubnet.IP) {
-		log.Fatal("Next IP cannot be allocated")
+	for {
+		ipNet := ipam.IP[prefix].NextFreeSubnet
+		if ipNet == nil {
+			return nil, &IPAMExhaustedError{Kind: kind, Cidr: prefix}
+		}
+		// a nil NextFreeSubnet indicates the cidr is exhausted, which is returned
+		// as an error on the next allocation
+		ipMask, _ := ipNet.Mask.Size()
+		nextSubnet, overflow := cidr.NextSubnet(ipNet, ipMask)
+		if overflow || !ipPrefixNet.Contains(nextSubnet.IP) {
+			nextSubnet = nil
+		}
+		ipam.IP[prefix].NextFreeSubnet = nextSubnet
+		if !ipam.State.reserved(ipam.name(), prefix, ipNet.String()) {
+			return ipNet, nil
+		}
 	}
-	return nil
 }
 
 // IPAMAllocateAddress - allocated address kind = network, access, loopback
 // typically being used for loopback address allocation of the network elements
-func (ipam *Ipam) IPAMAllocateAddress(kind *string, ipv4Cidrs, ipv6Cidrs []*string) (*Endpoint, error) {
+// the owner is the node the address is allocated to in the ipam state
+func (ipam *Ipam) IPAMAllocateAddress(kind, owner *string, ipv4Cidrs, ipv6Cidrs []*string) (*Endpoint, error) {
 	log.Debug("AllocateIPEndpoint ...")
 	var err error
-	e := new(Endpoint)
+	// the addresses of a family without cidr stay empty
+	e := &Endpoint{
+		IPv4Prefix:       new(string),
+		IPv4Address:      new(string),
+		IPv4PrefixLength: new(int),
+		IPv6Prefix:       new(string),
+		IPv6Address:      new(string),
+		IPv6PrefixLength: new(int),
+	}
 
-	log.Debugf("Kind: %s, ipv4Cidrs: %v", kind, ipv4Cidrs)
-	log.Debugf("Kind: %s, ipv6Cidrs: %v", kind, ipv4Cidrs)
+	log.Debugf("Kind: %s, ipv4Cidrs: %v", *kind, ipv4Cidrs)
+	log.Debugf("Kind: %s, ipv6Cidrs: %v", *kind, ipv6Cidrs)
 	for _, ipv4Prefix := range ipv4Cidrs {
 		if *ipv4Prefix != "" {
-			err = ipam.IPEndpointAlloc(*kind, "ipv4", *ipv4Prefix, e)
+			err = ipam.IPEndpointAlloc(*kind, *owner, "ipv4", *ipv4Prefix, e)
 			if err != nil {
 				return nil, err
 			}
@@ -218,7 +278,7 @@ func (ipam *Ipam) IPAMAllocateAddress(kind *string, ipv4Cidrs, ipv6Cidrs []*stri
 
 	for _, ipv6Prefix := range ipv6Cidrs {
 		if *ipv6Prefix != "" {
-			err = ipam.IPEndpointAlloc(*kind, "ipv6", *ipv6Prefix, e)
+			err = ipam.IPEndpointAlloc(*kind, *owner, "ipv6", *ipv6Prefix, e)
 			if err != nil {
 				return nil, err
 			}
@@ -228,10 +288,29 @@ func (ipam *Ipam) IPAMAllocateAddress(kind *string, ipv4Cidrs, ipv6Cidrs []*stri
 }
 
 // IPEndpointAlloc function allocates the ipv4 or ipv6 addresses; kind = network, access, loopback
-func (ipam *Ipam) IPEndpointAlloc(kind, version, prefix string, e *Endpoint) error {
+func (ipam *Ipam) IPEndpointAlloc(kind, owner, version, prefix string, e *Endpoint) error {
 	log.Debug("IPEndpointAlloc ...")
-	var err error
-	ipAddr := ipam.IP[prefix].NextFreeAddress
+	if _, ok := ipam.IP[prefix]; !ok {
+		return &ConfigError{Path: kind, Msg: fmt.Sprintf("%s cidr %s is not initialized for the addressing schema", version, prefix)}
+	}
+	// existing owners keep the address of the ipam state
+	key := &IPAMStateEntry{
+		IPAM: ipam.name(),
+		Cidr: prefix,
+		Node: owner,
+	}
+	var ipAddr *string
+	if entry := ipam.State.lookup(key); entry != nil {
+		ipAddr = StringPtr(entry.Address)
+	} else {
+		var err error
+		ipAddr, err = ipam.nextFreeAddress(kind, prefix)
+		if err != nil {
+			return err
+		}
+		key.Address = *ipAddr
+		ipam.State.record(key)
+	}
 
 	if version == "ipv4" {
 		e.IPv4Prefix = StringPtr(*ipAddr + "/" + "32")
@@ -242,14 +321,38 @@ func (ipam *Ipam) IPEndpointAlloc(kind, version, prefix string, e *Endpoint) err
 		e.IPv6Address = ipAddr
 		e.IPv6PrefixLength = IntPtr(128)
 	}
+	return nil
+}
 
+// nextFreeAddress returns the next free address of the cidr, skipping the
+// addresses that are allocated in the ipam state
+func (ipam *Ipam) nextFreeAddress(kind, prefix string) (*string, error) {
 	_, ipNet, err := net.ParseCIDR(prefix)
-	ipam.IP[prefix].NextFreeAddress, err = incrementIP(ipAddr, StringPtr(ipNet.String()))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	for {
+		ipAddr := ipam.IP[prefix].NextFreeAddress
+		if ipAddr == nil {
+			return nil, &IPAMExhaustedError{Kind: kind, Cidr: prefix}
+		}
+		// a nil NextFreeAddress indicates the cidr is exhausted, which is returned
+		// as an error on the next allocation
+		ipam.IP[prefix].NextFreeAddress, err = incrementIP(ipAddr, StringPtr(ipNet.String()))
+		if err != nil {
+			ipam.IP[prefix].NextFreeAddress = nil
+		}
+		if !ipam.State.reserved(ipam.name(), prefix, *ipAddr) {
+			return ipAddr, nil
+		}
+	}
+}
 
-	return nil
+func (ipam *Ipam) name() string {
+	if ipam.Name == nil {
+		return ""
+	}
+	return *ipam.Name
 }
 
 func incrementIP(origIP, cidr *string) (*string, error) {
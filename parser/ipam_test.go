@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func testLink(kind string) *Link {
+	return &Link{
+		Kind: StringPtr(kind),
+		A:    &Endpoint{Node: &Node{ShortName: StringPtr("leaf1")}, ShortName: StringPtr("e1-49")},
+		B:    &Endpoint{Node: &Node{ShortName: StringPtr("leaf2")}, ShortName: StringPtr("e1-49")},
+	}
+}
+
+func TestNewIPAM(t *testing.T) {
+	tests := []struct {
+		name         string
+		netwInfo     *NetworkInfo
+		wantPrefixes map[string]int // cidr -> prefix length
+		wantNext     map[string]string
+	}{
+		{
+			name: "loopback dual-stack",
+			netwInfo: &NetworkInfo{
+				Kind:             StringPtr("loopback"),
+				AddressingSchema: StringPtr("dual-stack"),
+				Ipv4Cidr:         []*string{StringPtr("100.112.100.0/24")},
+				Ipv6Cidr:         []*string{StringPtr("3100:100::/48")},
+			},
+			wantPrefixes: map[string]int{"100.112.100.0/24": 32, "3100:100::/48": 128},
+			wantNext:     map[string]string{"100.112.100.0/24": "100.112.100.0/32", "3100:100::/48": "3100:100::/128"},
+		},
+		{
+			name: "isl dual-stack",
+			netwInfo: &NetworkInfo{
+				Kind:                  StringPtr("isl"),
+				AddressingSchema:      StringPtr("dual-stack"),
+				Ipv4Cidr:              []*string{StringPtr("100.64.0.0/16")},
+				Ipv6Cidr:              []*string{StringPtr("3100:64::/48")},
+				Ipv4ItfcePrefixLength: IntPtr(31),
+				Ipv6ItfcePrefixLength: IntPtr(127),
+			},
+			wantPrefixes: map[string]int{"100.64.0.0/16": 31, "3100:64::/48": 127},
+			wantNext:     map[string]string{"100.64.0.0/16": "100.64.0.0/31", "3100:64::/48": "3100:64::/127"},
+		},
+		{
+			name: "isl ipv4-only",
+			netwInfo: &NetworkInfo{
+				Kind:                  StringPtr("isl"),
+				AddressingSchema:      StringPtr("ipv4-only"),
+				Ipv4Cidr:              []*string{StringPtr("100.64.0.0/16")},
+				Ipv6Cidr:              []*string{StringPtr("3100:64::/48")},
+				Ipv4ItfcePrefixLength: IntPtr(31),
+				Ipv6ItfcePrefixLength: IntPtr(127),
+			},
+			wantPrefixes: map[string]int{"100.64.0.0/16": 31},
+			wantNext:     map[string]string{"100.64.0.0/16": "100.64.0.0/31"},
+		},
+		{
+			name: "isl ipv6-only",
+			netwInfo: &NetworkInfo{
+				Kind:                  StringPtr("isl"),
+				AddressingSchema:      StringPtr("ipv6-only"),
+				Ipv4Cidr:              []*string{StringPtr("100.64.0.0/16")},
+				Ipv6Cidr:              []*string{StringPtr("3100:64::/48")},
+				Ipv4ItfcePrefixLength: IntPtr(31),
+				Ipv6ItfcePrefixLength: IntPtr(127),
+			},
+			wantPrefixes: map[string]int{"3100:64::/48": 127},
+			wantNext:     map[string]string{"3100:64::/48": "3100:64::/127"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipam, err := NewIPAM(tt.netwInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ipam.IP) != len(tt.wantPrefixes) {
+				t.Fatalf("got %d cidrs, want %d", len(ipam.IP), len(tt.wantPrefixes))
+			}
+			for cidr, length := range tt.wantPrefixes {
+				alloc, ok := ipam.IP[cidr]
+				if !ok {
+					t.Fatalf("cidr %s not initialized", cidr)
+				}
+				if *alloc.PrefixLength != length {
+					t.Errorf("%s: prefix length %d, want %d", cidr, *alloc.PrefixLength, length)
+				}
+				if alloc.NextFreeSubnet.String() != tt.wantNext[cidr] {
+					t.Errorf("%s: next free subnet %s, want %s", cidr, alloc.NextFreeSubnet, tt.wantNext[cidr])
+				}
+			}
+		})
+	}
+}
+
+func TestNewIPAMInvalidCidr(t *testing.T) {
+	_, err := NewIPAM(&NetworkInfo{
+		Kind:             StringPtr("loopback"),
+		AddressingSchema: StringPtr("ipv4-only"),
+		Ipv4Cidr:         []*string{StringPtr("100.112.100.0")},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an invalid cidr")
+	}
+}
+
+func TestIPAMAllocPrefixPerLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		cidr      string
+		length    int
+		wantA     string
+		wantB     string
+		wantNextA string // A address of the second link
+	}{
+		{name: "ipv4 /31", version: "ipv4", cidr: "100.64.0.0/16", length: 31, wantA: "100.64.0.0", wantB: "100.64.0.1", wantNextA: "100.64.0.2"},
+		{name: "ipv4 /30", version: "ipv4", cidr: "100.64.0.0/16", length: 30, wantA: "100.64.0.1", wantB: "100.64.0.2", wantNextA: "100.64.0.5"},
+		{name: "ipv6 /127", version: "ipv6", cidr: "3100:64::/48", length: 127, wantA: "3100:64::", wantB: "3100:64::1", wantNextA: "3100:64::2"},
+		{name: "ipv6 /64", version: "ipv6", cidr: "3100:64::/48", length: 64, wantA: "3100:64::1", wantB: "3100:64::2", wantNextA: "3100:64:0:1::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			netwInfo := &NetworkInfo{Kind: StringPtr("isl"), AddressingSchema: StringPtr("dual-stack")}
+			if tt.version == "ipv4" {
+				netwInfo.Ipv4Cidr = []*string{StringPtr(tt.cidr)}
+				netwInfo.Ipv4ItfcePrefixLength = IntPtr(tt.length)
+			} else {
+				netwInfo.Ipv6Cidr = []*string{StringPtr(tt.cidr)}
+				netwInfo.Ipv6ItfcePrefixLength = IntPtr(tt.length)
+			}
+			ipam, err := NewIPAM(netwInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			links := []*Link{testLink("isl"), testLink("isl")}
+			links[1].A.ShortName = StringPtr("e1-48")
+			for _, link := range links {
+				if err := ipam.IPAMAllocPrefixPerLink(StringPtr(tt.version), StringPtr(tt.cidr), link); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a, b, next := links[0].A.IPv4Address, links[0].B.IPv4Address, links[1].A.IPv4Address
+			length := links[0].A.IPv4PrefixLength
+			if tt.version == "ipv6" {
+				a, b, next = links[0].A.IPv6Address, links[0].B.IPv6Address, links[1].A.IPv6Address
+				length = links[0].A.IPv6PrefixLength
+			}
+			if *a != tt.wantA || *b != tt.wantB {
+				t.Errorf("got %s - %s, want %s - %s", *a, *b, tt.wantA, tt.wantB)
+			}
+			if *next != tt.wantNextA {
+				t.Errorf("second link got %s, want %s", *next, tt.wantNextA)
+			}
+			if *length != tt.length {
+				t.Errorf("prefix length %d, want %d", *length, tt.length)
+			}
+		})
+	}
+}
+
+func TestIPAMAllocPrefixPerLinkExhausted(t *testing.T) {
+	ipam, err := NewIPAM(&NetworkInfo{
+		Kind:                  StringPtr("isl"),
+		AddressingSchema:      StringPtr("ipv4-only"),
+		Ipv4Cidr:              []*string{StringPtr("100.64.0.0/30")},
+		Ipv4ItfcePrefixLength: IntPtr(31),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a /30 holds two /31 links
+	for i := 0; i < 2; i++ {
+		if err := ipam.IPAMAllocPrefixPerLink(StringPtr("ipv4"), StringPtr("100.64.0.0/30"), testLink("isl")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ipam.IPAMAllocPrefixPerLink(StringPtr("ipv4"), StringPtr("100.64.0.0/30"), testLink("isl"))
+	var exhausted *IPAMExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("got error %v, want IPAMExhaustedError", err)
+	}
+}
+
+func TestIPAMAllocPrefixPerLinkState(t *testing.T) {
+	state := &IPAMState{
+		Allocations: []*IPAMStateEntry{
+			{IPAM: "isl", Cidr: "100.64.0.0/16", Node: "leaf1", Endpoint: "e1-50", Address: "100.64.0.0/31"},
+			{IPAM: "isl", Cidr: "100.64.0.0/16", Node: "leaf1", Endpoint: "e1-49", Address: "100.64.0.6/31"},
+		},
+	}
+	ipam, err := NewIPAM(&NetworkInfo{
+		Kind:                  StringPtr("isl"),
+		AddressingSchema:      StringPtr("ipv4-only"),
+		Ipv4Cidr:              []*string{StringPtr("100.64.0.0/16")},
+		Ipv4ItfcePrefixLength: IntPtr(31),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipam.Name = StringPtr("isl")
+	ipam.State = state
+
+	// the link in the state keeps its prefix
+	link := testLink("isl")
+	if err := ipam.IPAMAllocPrefixPerLink(StringPtr("ipv4"), StringPtr("100.64.0.0/16"), link); err != nil {
+		t.Fatal(err)
+	}
+	if *link.A.IPv4Prefix != "100.64.0.6/31" {
+		t.Errorf("got %s, want 100.64.0.6/31", *link.A.IPv4Prefix)
+	}
+
+	// a new link skips the prefix reserved in the state
+	link = testLink("isl")
+	link.A.ShortName = StringPtr("e1-48")
+	if err := ipam.IPAMAllocPrefixPerLink(StringPtr("ipv4"), StringPtr("100.64.0.0/16"), link); err != nil {
+		t.Fatal(err)
+	}
+	if *link.A.IPv4Prefix != "100.64.0.2/31" {
+		t.Errorf("got %s, want 100.64.0.2/31", *link.A.IPv4Prefix)
+	}
+}
+
+func TestIPAMAllocPrefixPerLinkNotInitialized(t *testing.T) {
+	ipam, err := NewIPAM(&NetworkInfo{
+		Kind:                  StringPtr("isl"),
+		AddressingSchema:      StringPtr("ipv4-only"),
+		Ipv4Cidr:              []*string{StringPtr("100.64.0.0/16")},
+		Ipv6Cidr:              []*string{StringPtr("3100:64::/48")},
+		Ipv4ItfcePrefixLength: IntPtr(31),
+		Ipv6ItfcePrefixLength: IntPtr(127),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ipam.IPAMAllocPrefixPerLink(StringPtr("ipv6"), StringPtr("3100:64::/48"), testLink("isl"))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("got error %v, want ConfigError", err)
+	}
+}
+
+func TestIncrementIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		cidr    string
+		want    string
+		wantErr bool
+	}{
+		{ip: "10.0.0.1", cidr: "10.0.0.0/24", want: "10.0.0.2"},
+		{ip: "10.0.0.255", cidr: "10.0.0.0/16", want: "10.0.1.0"},
+		{ip: "10.0.0.255", cidr: "10.0.0.0/24", wantErr: true},
+		{ip: "100.64.0.0", cidr: "100.64.0.0/31", want: "100.64.0.1"},
+		{ip: "100.64.0.1", cidr: "100.64.0.0/31", wantErr: true},
+		{ip: "2a02:1800:80:7000::", cidr: "2a02:1800:80:7000::/64", want: "2a02:1800:80:7000::1"},
+		{ip: "2a02:1800:80:7000::ffff", cidr: "2a02:1800:80:7000::/64", want: "2a02:1800:80:7000::1:0"},
+		{ip: "3100:64::1", cidr: "3100:64::/127", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip+" in "+tt.cidr, func(t *testing.T) {
+			got, err := incrementIP(StringPtr(tt.ip), StringPtr(tt.cidr))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", *got)
+				}
+				// the original ip is returned on overflow
+				if *got != tt.ip {
+					t.Errorf("got %s, want %s", *got, tt.ip)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %s, want %s", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecrementIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "10.0.0.2", want: "10.0.0.1"},
+		{ip: "10.0.1.0", want: "10.0.0.255"},
+		{ip: "10.1.0.0", want: "10.0.255.255"},
+		{ip: "2a02:1800:80:7000::1", want: "2a02:1800:80:7000::"},
+		{ip: "2a02:1800:80:7000::1:0", want: "2a02:1800:80:7000::ffff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got := decrementIP(net.ParseIP(tt.ip))
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
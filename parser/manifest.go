@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a decoded k8s manifest of the output
+type Manifest struct {
+	// Path is the path of the file of the manifest relative to the output root
+	Path       string
+	APIVersion string
+	Kind       string
+	Name       string
+	// Target is the target label of a switch resource, a node or a group target
+	Target string
+	// Object holds the whole manifest
+	Object map[string]interface{}
+}
+
+// decodeManifests decodes the documents of a yaml file, the empty documents
+// are skipped
+func decodeManifests(name string, b []byte) ([]*Manifest, error) {
+	manifests := make([]*Manifest, 0)
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		obj := make(map[string]interface{})
+		if err := dec.Decode(&obj); err != nil {
+			if err == io.EOF {
+				return manifests, nil
+			}
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		m := &Manifest{
+			Path:       name,
+			APIVersion: stringField(obj, "apiVersion"),
+			Kind:       stringField(obj, "kind"),
+			Object:     obj,
+		}
+		if md, ok := obj["metadata"].(map[string]interface{}); ok {
+			m.Name = stringField(md, "name")
+			if labels, ok := md["labels"].(map[string]interface{}); ok {
+				m.Target = stringField(labels, "target")
+			}
+		}
+		manifests = append(manifests, m)
+	}
+}
+
+// decodeValues decodes a yaml file with helm values
+func decodeValues(name string, b []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return values, nil
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// targetNodes returns the switch nodes of a resource target, the node itself or
+// the switch nodes of a group target
+func (p *Parser) targetNodes(target string) []string {
+	if _, ok := p.Nodes[target]; ok {
+		return []string{target}
+	}
+	nodes := make([]string, 0)
+	for _, nodeName := range SortedKeys(p.Nodes) {
+		n := p.Nodes[nodeName]
+		if n.Target == nil || *n.Target != target {
+			continue
+		}
+		if _, ok := switchBackendName(*n.Kind); ok {
+			nodes = append(nodes, nodeName)
+		}
+	}
+	return nodes
+}
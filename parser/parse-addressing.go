@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// addressing schemas of the infrastructure and the workload networks
+const (
+	// AddressingDualStack allocates and peers in ipv4 and ipv6
+	AddressingDualStack = "dual-stack"
+	// AddressingIPv4Only allocates and peers in ipv4 only
+	AddressingIPv4Only = "ipv4-only"
+	// AddressingIPv6Only allocates and peers in ipv6 only
+	AddressingIPv6Only = "ipv6-only"
+)
+
+// addressingSchemas holds the supported addressing schemas
+var addressingSchemas = []string{AddressingDualStack, AddressingIPv4Only, AddressingIPv6Only}
+
+// addressFamilies returns the bgp address families of an addressing schema
+func addressFamilies(schema string) []string {
+	switch schema {
+	case AddressingIPv4Only:
+		return []string{"ipv4-unicast"}
+	case AddressingIPv6Only:
+		return []string{"ipv6-unicast"}
+	}
+	return []string{"ipv4-unicast", "ipv6-unicast"}
+}
+
+// infraSchema returns the addressing schema of the infrastructure
+func (p *Parser) infraSchema() string {
+	return *p.Config.Infrastructure.AddressingSchema
+}
+
+// networkSchema returns the addressing schema of a network, the schema of the
+// infrastructure when the network has none
+func (p *Parser) networkSchema(netwInfo *NetworkInfo) string {
+	if netwInfo.AddressingSchema == nil || *netwInfo.AddressingSchema == "" {
+		return p.infraSchema()
+	}
+	return *netwInfo.AddressingSchema
+}
+
+// schemaCidrs returns the ipv4 and ipv6 cidrs of a network that are part of the
+// addressing schema
+func schemaCidrs(schema string, netwInfo *NetworkInfo) ([]*string, []*string) {
+	var ipv4Cidrs, ipv6Cidrs []*string
+	if schema != AddressingIPv6Only {
+		ipv4Cidrs = netwInfo.Ipv4Cidr
+	}
+	if schema != AddressingIPv4Only {
+		ipv6Cidrs = netwInfo.Ipv6Cidr
+	}
+	return ipv4Cidrs, ipv6Cidrs
+}
+
+// cidrPairs pairs the ipv4 and ipv6 cidrs by index, the missing family of a
+// pair is empty
+func cidrPairs(ipv4Cidrs, ipv6Cidrs []*string) [][2]*string {
+	n := len(ipv4Cidrs)
+	if len(ipv6Cidrs) > n {
+		n = len(ipv6Cidrs)
+	}
+	pairs := make([][2]*string, 0, n)
+	for i := 0; i < n; i++ {
+		pair := [2]*string{StringPtr(""), StringPtr("")}
+		if i < len(ipv4Cidrs) {
+			pair[0] = ipv4Cidrs[i]
+		}
+		if i < len(ipv6Cidrs) {
+			pair[1] = ipv6Cidrs[i]
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// addressPrefix returns the address with its prefix length, empty for an
+// endpoint without address
+func addressPrefix(address *string, prefixLength *int) string {
+	if address == nil || *address == "" {
+		return ""
+	}
+	if prefixLength == nil {
+		return *address
+	}
+	return fmt.Sprintf("%s/%d", *address, *prefixLength)
+}
+
+// routerID returns the router id of a node of the fabric, the ipv4 loopback or
+// derived from the last 32 bits of the ipv6 loopback when the node has no ipv4
+// loopback; these bits are incremented by one, since the first loopback of the
+// network would give the invalid router id 0.0.0.0
+func routerID(n *Node) string {
+	lo := n.Endpoints["lo0"]
+	if *lo.IPv4Address != "" {
+		return *lo.IPv4Address
+	}
+	ip := net.ParseIP(*lo.IPv6Address)
+	if ip == nil {
+		return ""
+	}
+	id := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(id, binary.BigEndian.Uint32(ip.To16()[12:16])+1)
+	return id.String()
+}
+
+// loopbackAddress returns the loopback address of the overlay sessions of a
+// node, the ipv6 loopback in an ipv6-only fabric
+func (p *Parser) loopbackAddress(n *Node) string {
+	if p.infraSchema() == AddressingIPv6Only {
+		return *n.Endpoints["lo0"].IPv6Address
+	}
+	return *n.Endpoints["lo0"].IPv4Address
+}
+
+// underlayPeerGroups returns the eBGP underlay groups of the fabric: a group per
+// address family in dual-stack, which peers ipv6 over the ipv6 isl addresses,
+// or a single group in a single-stack fabric or over unnumbered isls
+func (p *Parser) underlayPeerGroups() []*PeerGroup {
+	schema := p.infraSchema()
+	if schema != AddressingDualStack || p.unnumberedIsl() {
+		return []*PeerGroup{{
+			Name:         "underlay",
+			PolicyName:   "export-underlay-local",
+			Protocols:    addressFamilies(schema),
+			IPv6NextHops: p.unnumberedIsl(),
+		}}
+	}
+	return []*PeerGroup{
+		{
+			Name:       "underlay",
+			PolicyName: "export-underlay-local",
+			Protocols:  []string{"ipv4-unicast"},
+		},
+		{
+			Name:       "underlay-v6",
+			PolicyName: "export-underlay-local",
+			Protocols:  []string{"ipv6-unicast"},
+		},
+	}
+}
+
+// underlayNeighbors returns the eBGP underlay sessions of a numbered isl
+// endpoint per address family of the fabric
+func (p *Parser) underlayNeighbors(ep *Endpoint) []*Neighbor {
+	neighbors := make([]*Neighbor, 0, 2)
+	schema := p.infraSchema()
+	if schema != AddressingIPv6Only && *ep.IPv4NeighborAddress != "" {
+		neighbors = append(neighbors, &Neighbor{
+			PeerIP:    *ep.IPv4NeighborAddress,
+			PeerAS:    *ep.PeerAS,
+			PeerGroup: "underlay",
+		})
+	}
+	if schema != AddressingIPv4Only && *ep.IPv6NeighborAddress != "" {
+		group := "underlay-v6"
+		if schema == AddressingIPv6Only {
+			group = "underlay"
+		}
+		neighbors = append(neighbors, &Neighbor{
+			PeerIP:    *ep.IPv6NeighborAddress,
+			PeerAS:    *ep.PeerAS,
+			PeerGroup: group,
+		})
+	}
+	return neighbors
+}
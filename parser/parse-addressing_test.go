@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// addressingConfig holds the isl subinterface and the bgp of a leaf
+type addressingConfig struct {
+	IslPrefixes map[string][]string // ipv4 or ipv6 prefixes of ethernet-1/49.0
+	RouterID    string
+	Groups      map[string][]string // address families per group
+	Neighbors   map[string]string   // peer group per peer address
+	// Gateway holds the address families of the bgp towards the gateways in
+	// the ip-vrf of the infrastructure
+	Gateway []string
+}
+
+// generateAddressing generates the fabric after the update of the config and
+// returns the addressing of leaf1
+func generateAddressing(t *testing.T, update func(cfg *Config)) *addressingConfig {
+	t.Helper()
+	type prefixes struct {
+		Address []struct {
+			IPPrefix string `json:"ip-prefix"`
+		} `json:"address"`
+	}
+	type bgp struct {
+		RouterID string `json:"router-id"`
+		Group    []struct {
+			GroupName   string           `json:"group-name"`
+			IPv4Unicast *json.RawMessage `json:"ipv4-unicast"`
+			IPv6Unicast *json.RawMessage `json:"ipv6-unicast"`
+			Evpn        *json.RawMessage `json:"evpn"`
+		} `json:"group"`
+		IPv4Unicast *json.RawMessage `json:"ipv4-unicast"`
+		IPv6Unicast *json.RawMessage `json:"ipv6-unicast"`
+		Neighbor    []struct {
+			PeerAddress string `json:"peer-address"`
+			PeerGroup   string `json:"peer-group"`
+		} `json:"neighbor"`
+	}
+	tree := struct {
+		Interface []struct {
+			Name         string `json:"name"`
+			Subinterface []struct {
+				IPv4 *prefixes `json:"ipv4"`
+				IPv6 *prefixes `json:"ipv6"`
+			} `json:"subinterface"`
+		} `json:"interface"`
+		NetworkInstance []struct {
+			Name      string `json:"name"`
+			Protocols struct {
+				Bgp bgp `json:"bgp"`
+			} `json:"protocols"`
+		} `json:"network-instance"`
+	}{}
+	generateNodeJSON(t, update, "leaf1", &tree)
+	families := func(v4, v6 *json.RawMessage) []string {
+		afs := make([]string, 0)
+		if v4 != nil {
+			afs = append(afs, "ipv4")
+		}
+		if v6 != nil {
+			afs = append(afs, "ipv6")
+		}
+		return afs
+	}
+	a := &addressingConfig{
+		IslPrefixes: make(map[string][]string),
+		Groups:      make(map[string][]string),
+		Neighbors:   make(map[string]string),
+	}
+	for _, itfce := range tree.Interface {
+		if itfce.Name != "ethernet-1/49" {
+			continue
+		}
+		si := itfce.Subinterface[0]
+		for af, pfxs := range map[string]*prefixes{"ipv4": si.IPv4, "ipv6": si.IPv6} {
+			if pfxs == nil {
+				continue
+			}
+			for _, addr := range pfxs.Address {
+				a.IslPrefixes[af] = append(a.IslPrefixes[af], addr.IPPrefix)
+			}
+		}
+	}
+	for _, ni := range tree.NetworkInstance {
+		b := ni.Protocols.Bgp
+		switch ni.Name {
+		case "default":
+			a.RouterID = b.RouterID
+			for _, g := range b.Group {
+				if g.Evpn == nil {
+					a.Groups[g.GroupName] = families(g.IPv4Unicast, g.IPv6Unicast)
+				}
+			}
+			for _, n := range b.Neighbor {
+				a.Neighbors[n.PeerAddress] = n.PeerGroup
+			}
+		case "infrastructure-ipvrf-itfce-45":
+			a.Gateway = families(b.IPv4Unicast, b.IPv6Unicast)
+		}
+	}
+	return a
+}
+
+func TestAddressingSchema(t *testing.T) {
+	dualStack := generateAddressing(t, func(cfg *Config) {})
+	if want := map[string][]string{"ipv4": {"100.64.0.0/31"}, "ipv6": {"3100:64::/127"}}; !reflect.DeepEqual(dualStack.IslPrefixes, want) {
+		t.Errorf("dual-stack: got isl prefixes %v, want %v", dualStack.IslPrefixes, want)
+	}
+	if want := map[string][]string{"underlay": {"ipv4"}, "underlay-v6": {"ipv6"}}; !reflect.DeepEqual(dualStack.Groups, want) {
+		t.Errorf("dual-stack: got groups %v, want %v", dualStack.Groups, want)
+	}
+	if want := map[string]string{"100.64.0.1": "underlay", "3100:64::1": "underlay-v6", "100.112.100.1": "overlay"}; !reflect.DeepEqual(dualStack.Neighbors, want) {
+		t.Errorf("dual-stack: got neighbors %v, want %v", dualStack.Neighbors, want)
+	}
+
+	ipv4Only := generateAddressing(t, func(cfg *Config) {
+		cfg.Infrastructure.AddressingSchema = StringPtr(AddressingIPv4Only)
+	})
+	if want := map[string][]string{"ipv4": {"100.64.0.0/31"}}; !reflect.DeepEqual(ipv4Only.IslPrefixes, want) {
+		t.Errorf("ipv4-only: got isl prefixes %v, want %v", ipv4Only.IslPrefixes, want)
+	}
+	if want := map[string][]string{"underlay": {"ipv4"}}; !reflect.DeepEqual(ipv4Only.Groups, want) {
+		t.Errorf("ipv4-only: got groups %v, want %v", ipv4Only.Groups, want)
+	}
+	if want := map[string]string{"100.64.0.1": "underlay", "100.112.100.1": "overlay"}; !reflect.DeepEqual(ipv4Only.Neighbors, want) {
+		t.Errorf("ipv4-only: got neighbors %v, want %v", ipv4Only.Neighbors, want)
+	}
+
+	ipv6Only := generateAddressing(t, func(cfg *Config) {
+		cfg.Infrastructure.AddressingSchema = StringPtr(AddressingIPv6Only)
+		// the workload towards the gateways follows its own addressing schema
+		cfg.Workloads["infrastructure"]["dcgw-grp1"].Itfces["itfce"].AddressingSchema = StringPtr(AddressingIPv4Only)
+	})
+	if want := map[string][]string{"ipv6": {"3100:64::/127"}}; !reflect.DeepEqual(ipv6Only.IslPrefixes, want) {
+		t.Errorf("ipv6-only: got isl prefixes %v, want %v", ipv6Only.IslPrefixes, want)
+	}
+	if want := map[string][]string{"underlay": {"ipv6"}}; !reflect.DeepEqual(ipv6Only.Groups, want) {
+		t.Errorf("ipv6-only: got groups %v, want %v", ipv6Only.Groups, want)
+	}
+	if want := map[string]string{"3100:64::1": "underlay", "3100:100::1": "overlay"}; !reflect.DeepEqual(ipv6Only.Neighbors, want) {
+		t.Errorf("ipv6-only: got neighbors %v, want %v", ipv6Only.Neighbors, want)
+	}
+	// the router id is derived from the ipv6 loopback 3100:100::
+	if ipv6Only.RouterID != "0.0.0.1" {
+		t.Errorf("ipv6-only: got router id %s, want 0.0.0.1", ipv6Only.RouterID)
+	}
+	if want := []string{"ipv4"}; !reflect.DeepEqual(ipv6Only.Gateway, want) {
+		t.Errorf("ipv6-only: got gateway families %v, want %v", ipv6Only.Gateway, want)
+	}
+}
+
+func TestRouterID(t *testing.T) {
+	for _, tc := range []struct{ ipv4, ipv6, want string }{
+		{"100.112.100.0", "3100:100::", "100.112.100.0"},
+		{"", "3100:100::a01:203", "10.1.2.4"},
+	} {
+		n := &Node{Endpoints: map[string]*Endpoint{"lo0": {IPv4Address: StringPtr(tc.ipv4), IPv6Address: StringPtr(tc.ipv6)}}}
+		if got := routerID(n); got != tc.want {
+			t.Errorf("routerID(%s, %s): got %s, want %s", tc.ipv4, tc.ipv6, got, tc.want)
+		}
+	}
+}
@@ -152,14 +152,18 @@ type MultusDataPlaneInfo struct {
 	NumDsfDevices *int
 }
 
-func (p *Parser) ParseCnfKustomize(cnfName *string, appc *AppConfig, appIPMap *AppIPMap) {
+func (p *Parser) ParseCnfKustomize(cnfName *string, appc *AppConfig, appIPMap *AppIPMap) error {
 	log.Infof("Rendering Application Data into Kustomize K8s manifests for %s...", *cnfName)
 	dirName := filepath.Join(*p.BaseAppKustomizesDir)
-	p.CreateDirectory(dirName, 0777)
-	p.CreateDirectory(filepath.Join(dirName, *cnfName), 0777)
+	if err := p.CreateDirectory(filepath.Join(dirName, *cnfName), 0777); err != nil {
+		return err
+	}
 
 	// Parse the application templates
-	t := ParseTemplates("./templates/app-kustomize")
+	t, err := ParseTemplates("app-kustomize", p.templateFS()...)
+	if err != nil {
+		return err
+	}
 
 	switch *cnfName {
 	case "upf", "smf":
@@ -298,8 +302,10 @@ func (p *Parser) ParseCnfKustomize(cnfName *string, appc *AppConfig, appIPMap *A
 
 			}
 		}
-		for _, workloads := range appc.Networks {
-			for _, switchGroups := range workloads[0] {
+		for _, wlName := range SortedKeys(appc.Networks) {
+			workloads := appc.Networks[wlName]
+			for _, switchIndex := range SortedIntKeys(workloads[0]) {
+				switchGroups := workloads[0][switchIndex]
 				if networks, ok := switchGroups["itfce"]; ok {
 					if networkTypes, ok := networks["intIP"]; ok {
 						networkInfo := networkTypes[0]
@@ -337,15 +343,21 @@ func (p *Parser) ParseCnfKustomize(cnfName *string, appc *AppConfig, appIPMap *A
 		}
 
 		// render network attachement definition
-		p.RenderNetworkAttachement(t, StringPtr(filepath.Join(dirName, *cnfName)), values)
+		if err := p.RenderNetworkAttachement(t, StringPtr(filepath.Join(dirName, *cnfName)), values); err != nil {
+			return err
+		}
 
 		// render network attachement definition
-		p.RenderToActiveConfigMap(t, StringPtr(filepath.Join(dirName, *cnfName)), values)
+		if err := p.RenderToActiveConfigMap(t, StringPtr(filepath.Join(dirName, *cnfName)), values); err != nil {
+			return err
+		}
 
 		// render network attachement definition
-		p.RenderStatefulSet(t, StringPtr(filepath.Join(dirName, *cnfName)), values)
+		if err := p.RenderStatefulSet(t, StringPtr(filepath.Join(dirName, *cnfName)), values); err != nil {
+			return err
+		}
 
 	case "amf":
 	}
-
+	return nil
 }
@@ -221,13 +221,18 @@ type switchInfo struct {
 	switchGwsPerWlNameIpv6 map[string]map[int][]string // key1 = wlName, key2 = switch, value is list of GwIPs
 }
 
-func (p *Parser) ParseApplicationData() {
+func (p *Parser) ParseApplicationData() error {
 	log.Infof("Rendering Application Data into Helm values.yaml...")
 	dirName := filepath.Join(*p.BaseAppValuesDir)
-	p.CreateDirectory(dirName, 0777)
+	if err := p.CreateDirectory(dirName, 0777); err != nil {
+		return err
+	}
 
 	// Parse the application templates
-	t := ParseTemplates("./templates/app-helm")
+	t, err := ParseTemplates("app-helm", p.templateFS()...)
+	if err != nil {
+		return err
+	}
 
 	// get IP allocations from the cluster IP(s)
 	var apiServer net.IP
@@ -236,24 +241,31 @@ func (p *Parser) ParseApplicationData() {
 		for _, ipv4Cidr := range svc.Ipv4Cidr {
 			_, ipNet, err := net.ParseCIDR(*ipv4Cidr)
 			if err != nil {
-				log.WithError(err).Error("Cidr Parsing error")
+				return &ConfigError{Path: "cluster.networks.svc.ipv4_cidr", Msg: err.Error()}
 			}
 			apiServer, err = cidr.Host(ipNet, 1)
 			if err != nil {
-				log.WithError(err).Error("Host Parsing error")
+				return err
 			}
 			dns, err = cidr.Host(ipNet, 10)
 			if err != nil {
-				log.WithError(err).Error("Host Parsing error")
+				return err
 			}
 		}
 	}
 
 	// get gw and bgp indexes
-	gwidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr("switch"), StringPtr("gw"))
-	bgpidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr("switch"), StringPtr("bgp"))
+	gwidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr("switch"), StringPtr("gw"))
+	if err != nil {
+		return err
+	}
+	bgpidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr("switch"), StringPtr("bgp"))
+	if err != nil {
+		return err
+	}
 
-	for wlName, clients := range p.Config.Workloads {
+	for _, wlName := range SortedKeys(p.Config.Workloads) {
+		clients := p.Config.Workloads[wlName]
 		p.DeploymentIPAM[wlName] = make(map[string]map[string]*IpamApp)
 		// initilaize switchBgpPeers
 		// key1 = wlName, key2 = switchid -> value is bgp peer; 1 per switch
@@ -268,35 +280,46 @@ func (p *Parser) ParseApplicationData() {
 		p.SwitchInfo.switchGwsPerWlNameIpv4[wlName] = make(map[int][]string)
 		p.SwitchInfo.switchGwsPerWlNameIpv6[wlName] = make(map[int][]string)
 
-		for _, wlInfo := range clients {
+		for _, cgName := range SortedKeys(clients) {
+			wlInfo := clients[cgName]
 
 			// loopback subnets
-			for netwType, netwInfo := range wlInfo.Loopbacks {
+			for _, netwType := range SortedKeys(wlInfo.Loopbacks) {
+				netwInfo := wlInfo.Loopbacks[netwType]
 				if strings.Contains(netwType, "loopback") {
 					for _, ipv4Cidr := range netwInfo.Ipv4Cidr {
-						p.AssignSwitchBgpLoopback(StringPtr("ipv4"), ipv4Cidr, StringPtr(wlName), StringPtr(netwType), *bgpidx)
+						if err := p.AssignSwitchBgpLoopback(StringPtr("ipv4"), ipv4Cidr, StringPtr(wlName), StringPtr(netwType), *bgpidx); err != nil {
+							return err
+						}
 					}
 
 					for _, ipv6Cidr := range netwInfo.Ipv6Cidr {
-						p.AssignSwitchBgpLoopback(StringPtr("ipv6"), ipv6Cidr, StringPtr(wlName), StringPtr(netwType), *bgpidx)
+						if err := p.AssignSwitchBgpLoopback(StringPtr("ipv6"), ipv6Cidr, StringPtr(wlName), StringPtr(netwType), *bgpidx); err != nil {
+							return err
+						}
 					}
 				}
 			}
 
 			// SRIOV, IPVLAN subnets
 
-			for netwType, netwInfo := range wlInfo.Itfces {
+			for _, netwType := range SortedKeys(wlInfo.Itfces) {
+				netwInfo := wlInfo.Itfces[netwType]
 				if strings.Contains(netwType, "ipvlan") || strings.Contains(netwType, "sriov") {
 
 					// initialize sriov or ipvlan
 					p.DeploymentIPAM[wlName][netwType] = make(map[string]*IpamApp)
 
 					for idx, ipv4Cidr := range netwInfo.Ipv4Cidr {
-						p.AssignSwitchGWs(StringPtr("ipv4"), ipv4Cidr, StringPtr(wlName), StringPtr(netwType), idx, *gwidx, netwInfo)
+						if err := p.AssignSwitchGWs(StringPtr("ipv4"), ipv4Cidr, StringPtr(wlName), StringPtr(netwType), idx, *gwidx, netwInfo); err != nil {
+							return err
+						}
 					}
 
 					for idx, ipv6Cidr := range netwInfo.Ipv6Cidr {
-						p.AssignSwitchGWs(StringPtr("ipv6"), ipv6Cidr, StringPtr(wlName), StringPtr(netwType), idx, *gwidx, netwInfo)
+						if err := p.AssignSwitchGWs(StringPtr("ipv6"), ipv6Cidr, StringPtr(wlName), StringPtr(netwType), idx, *gwidx, netwInfo); err != nil {
+							return err
+						}
 					}
 				}
 			}
@@ -313,20 +336,24 @@ func (p *Parser) ParseApplicationData() {
 	log.Debugf("Gateways Ipv6: %v", p.SwitchInfo.switchGwsPerWlNameIpv6)
 
 	// get the application information
-	for app, pacoInfo := range p.Config.Application {
+	for _, app := range SortedKeys(p.Config.Application) {
+		pacoInfo := p.Config.Application[app]
 		if app == "paco" {
 			// holds the global IP configuration that applications use to communicate to eachother
 			appIPMap := new(AppIPMap)
 			appIPMap.IPinfo = make(map[string]*IPinfo)
 
 			appc := make(map[string]*AppConfig)
+			p.AppConfigs = appc
 			// identifies the multus networks on which the apps are connected
 			// this holds only the relevant information for the app
 			connectedMultusNetworks := make(map[string]*MultusInfo)
 			// find all the multus networks on which the cnfs are connected
-			for _, cnfInfo := range pacoInfo.Cnfs {
+			for _, cnfName := range SortedKeys(pacoInfo.Cnfs) {
+				cnfInfo := pacoInfo.Cnfs[cnfName]
 				if *cnfInfo.Enabled {
-					for multusGenericWlName, multusInfo := range cnfInfo.Networking.Multus {
+					for _, multusGenericWlName := range SortedKeys(cnfInfo.Networking.Multus) {
+						multusInfo := cnfInfo.Networking.Multus[multusGenericWlName]
 						connectedMultusNetworks[multusGenericWlName] = multusInfo
 
 						//initilaize the appIPMap
@@ -337,7 +364,8 @@ func (p *Parser) ParseApplicationData() {
 			log.Debugf("Connected multus networks : %v", connectedMultusNetworks)
 			// get the cnf related pod/connectivity info
 			appIPMap.IPinfo["oam"].InternetDNS = p.Config.Infrastructure.InternetDns
-			for cnfName, cnfInfo := range pacoInfo.Cnfs {
+			for _, cnfName := range SortedKeys(pacoInfo.Cnfs) {
+				cnfInfo := pacoInfo.Cnfs[cnfName]
 				if *cnfInfo.Enabled {
 					// holds the relevant information per CNF
 					appc[cnfName] = new(AppConfig)
@@ -351,7 +379,9 @@ func (p *Parser) ParseApplicationData() {
 					appc[cnfName].InitializeCnfContainerData(p.Config.ContainerRegistry, cnfInfo.Pods)
 					//
 					//appc[cnfName].InitializeCnfNetworkData(cnfName, cnfInfo, p, appIPMap, p.ClientLinks, p.ClientSriovInfo, p.SwitchInfo)
-					appc[cnfName].InitializeCnfNetworkData(cnfName, cnfInfo, p, appIPMap, p.ClientServer2NetworkLinks, p.SwitchInfo)
+					if err := appc[cnfName].InitializeCnfNetworkData(cnfName, cnfInfo, p, appIPMap, p.ClientServer2NetworkLinks, p.SwitchInfo); err != nil {
+						return err
+					}
 
 					appc[cnfName].K8sApiServer = apiServer.String()
 					appc[cnfName].K8sDns = dns.String()
@@ -386,50 +416,38 @@ func (p *Parser) ParseApplicationData() {
 
 			// write the related values.yaml file for the cnfs that are enabled
 
-			for cnfName := range pacoInfo.Cnfs {
+			for _, cnfName := range SortedKeys(pacoInfo.Cnfs) {
 				log.Infof("CnfName: %s", cnfName)
 
-				p.WriteCnfValues(t, &dirName,
+				if err := p.WriteCnfValues(t, &dirName,
 					StringPtr(cnfName),
 					appc[cnfName],
-					appIPMap)
-
-				// Show the application IPAM
-				//dirName := filepath.Join(*p.BaseAppIpamDir)
-				//p.WriteApplicationDeploymentIPAM(&dirName)
+					appIPMap); err != nil {
+					return err
+				}
 
-				p.ParseCnfKustomize(StringPtr(cnfName), appc[cnfName], appIPMap)
+				if err := p.ParseCnfKustomize(StringPtr(cnfName), appc[cnfName], appIPMap); err != nil {
+					return err
+				}
 			}
 
+			// write the application ipam allocations as csv files
+			if err := p.WriteApplicationDeploymentIPAM(p.BaseAppIpamDir); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-func (p *Parser) AssignSwitchGWs(version, ipcidr, wlName, netwType *string, idx, gwidx int, netwInfo *NetworkInfo) {
+func (p *Parser) AssignSwitchGWs(version, ipcidr, wlName, netwType *string, idx, gwidx int, netwInfo *NetworkInfo) error {
 	// initialize ipv4 or ipv6 subnet
 	p.DeploymentIPAM[*wlName][*netwType][*ipcidr] = new(IpamApp)
 
 	// get switch index from the netwType
-	switchIndex := 0
-	networkIndex := 0
-	split := strings.Split(*netwType, ".")
-	if len(split) > 1 {
-		// sriov
-		var err error
-		networkIndex, err = strconv.Atoi(split[1])
-		if err != nil {
-			log.Fatalf("error in sriov definition: -> sriov1.1 or sriov2.1, first integer represents the switch, 2nd integer represents the subnet")
-		}
-		switchIndex, err = strconv.Atoi(strings.TrimPrefix(split[0], "sriov"))
-		if err != nil {
-			log.Fatalf("error in sriov definition: -> sriov1.1 or sriov2.1")
-		}
-		log.Debugf("Sriov Name %s, NetworkIndex %d, SwitchIndex %d", *netwType, networkIndex, switchIndex)
-
-	} else {
-		// ipvlan, do nothing since ipvlan is connected via a bond and multi-homing
-		// network Index = 0
-		// switchIndex = 0
+	switchIndex, _, err := getSwitchIndexes(*netwType)
+	if err != nil {
+		return err
 	}
 
 	// initialize the gw with netwType
@@ -460,29 +478,28 @@ func (p *Parser) AssignSwitchGWs(version, ipcidr, wlName, netwType *string, idx,
 	// assign Ipv4 or IPv6 Gateway
 	_, ipNet, err := net.ParseCIDR(*ipcidr)
 	if err != nil {
-		log.WithError(err).Error("Cidr Parsing error")
-	} else {
-		gw, err := cidr.Host(ipNet, gwidx)
-		if err != nil {
-			log.WithError(err).Error("Host Parsing error")
-		} else {
-			p.DeploymentIPAM[*wlName][*netwType][*ipcidr].Gateway = StringPtr(gw.String())
-			p.DeploymentIPAM[*wlName][*netwType][*ipcidr].VlanID = netwInfo.VlanID
-
-			log.Debugf("gatways: %s", *netwType)
-			switch *version {
-			case "ipv4":
-				p.SwitchInfo.switchGwsIPv4[*wlName][*netwType][switchIndex][idx] = gw.String()
-				p.SwitchInfo.switchGwsPerWlNameIpv4[*wlName][switchIndex] = append(p.SwitchInfo.switchGwsPerWlNameIpv4[*wlName][switchIndex], gw.String())
-			case "ipv6":
-				p.SwitchInfo.switchGwsIPv6[*wlName][*netwType][switchIndex][idx] = gw.String()
-				p.SwitchInfo.switchGwsPerWlNameIpv6[*wlName][switchIndex] = append(p.SwitchInfo.switchGwsPerWlNameIpv6[*wlName][switchIndex], gw.String())
-			}
-		}
+		return &ConfigError{Path: "workloads." + *wlName + ".itfces." + *netwType, Msg: err.Error()}
+	}
+	gw, err := cidr.Host(ipNet, gwidx)
+	if err != nil {
+		return &IPAMExhaustedError{Kind: *wlName + "/" + *netwType, Cidr: *ipcidr}
 	}
+	p.DeploymentIPAM[*wlName][*netwType][*ipcidr].Gateway = StringPtr(gw.String())
+	p.DeploymentIPAM[*wlName][*netwType][*ipcidr].VlanID = netwInfo.VlanID
+
+	log.Debugf("gatways: %s", *netwType)
+	switch *version {
+	case "ipv4":
+		p.SwitchInfo.switchGwsIPv4[*wlName][*netwType][switchIndex][idx] = gw.String()
+		p.SwitchInfo.switchGwsPerWlNameIpv4[*wlName][switchIndex] = append(p.SwitchInfo.switchGwsPerWlNameIpv4[*wlName][switchIndex], gw.String())
+	case "ipv6":
+		p.SwitchInfo.switchGwsIPv6[*wlName][*netwType][switchIndex][idx] = gw.String()
+		p.SwitchInfo.switchGwsPerWlNameIpv6[*wlName][switchIndex] = append(p.SwitchInfo.switchGwsPerWlNameIpv6[*wlName][switchIndex], gw.String())
+	}
+	return nil
 }
 
-func (p *Parser) AssignSwitchBgpLoopback(version, ipcidr, wlName, netwType *string, bgpidx int) {
+func (p *Parser) AssignSwitchBgpLoopback(version, ipcidr, wlName, netwType *string, bgpidx int) error {
 	// initialize sriov or ipvlan
 	p.DeploymentIPAM[*wlName][*netwType] = make(map[string]*IpamApp)
 
@@ -491,7 +508,7 @@ func (p *Parser) AssignSwitchBgpLoopback(version, ipcidr, wlName, netwType *stri
 
 	_, ipNet, err := net.ParseCIDR(*ipcidr)
 	if err != nil {
-		log.WithError(err).Error("Cidr Parsing error")
+		return &ConfigError{Path: "workloads." + *wlName + ".loopbacks." + *netwType, Msg: err.Error()}
 	}
 
 	// assign leaf1 BGP Loopback
@@ -499,20 +516,22 @@ func (p *Parser) AssignSwitchBgpLoopback(version, ipcidr, wlName, netwType *stri
 	// e.g. map[master0:[leaf1 leaf2]]
 	// We are assuming uniform connectivity
 	once := true
-	for _, clientLinkInfo := range p.ClientServer2NetworkLinks["sriov"] {
-		for _, numaInfo := range clientLinkInfo {
+	for _, clientLinkName := range SortedKeys(p.ClientServer2NetworkLinks["sriov"]) {
+		clientLinkInfo := p.ClientServer2NetworkLinks["sriov"][clientLinkName]
+		for _, numa := range SortedIntKeys(clientLinkInfo) {
+			numaInfo := clientLinkInfo[numa]
 			if once {
-				for switchName := range numaInfo {
+				for _, switchName := range SortedKeys(numaInfo) {
 					switchIndex, err := strconv.Atoi(switchName[len(switchName)-1:])
 					if err != nil {
-						log.Fatalf("switch name should end with a integer")
+						return &TopologyError{Element: "node " + switchName, Msg: "switch name should end with an integer"}
 					}
 
 					// assign BGP Loopback
 					var allocateIP *AllocatedIPInfo
 					allocateIP, err = AllocateIPIndex(StringPtr(switchName), StringPtr("BGP loopback"), IntPtr(bgpidx+switchIndex-1), ipNet, p.DeploymentIPAM[*wlName][*netwType])
 					if err != nil {
-						log.WithError(err).Errorf("Allocating IP error")
+						return err
 					}
 
 					switch *version {
@@ -532,6 +551,7 @@ func (p *Parser) AssignSwitchBgpLoopback(version, ipcidr, wlName, netwType *stri
 			}
 		}
 	}
+	return nil
 }
 
 // initializes the POD/Container information per CNF
@@ -557,7 +577,8 @@ func (a *AppConfig) InitializeCnfContainerData(c *ContainerRegistry, pods map[st
 
 	// initialize the CNF POD/container information
 	a.Containers = make(map[string]*ContainerInfo)
-	for podName, podInfo := range pods {
+	for _, podName := range SortedKeys(pods) {
+		podInfo := pods[podName]
 		// specific for AMF
 		switch podName {
 		case "dbs":
@@ -706,7 +727,7 @@ func (a *AppConfig) InitializeCnfContainerData(c *ContainerRegistry, pods map[st
 	}
 }
 
-func getSwitchIndexes(netwType string) (int, int) {
+func getSwitchIndexes(netwType string) (int, int, error) {
 	switchIndex := 0
 	networkIndex := 0
 	split := strings.Split(netwType, ".")
@@ -715,11 +736,11 @@ func getSwitchIndexes(netwType string) (int, int) {
 		var err error
 		networkIndex, err = strconv.Atoi(split[1])
 		if err != nil {
-			log.Fatalf("error in sriov definition: -> sriov1.1 or sriov2.1, first integer represents the switch, 2nd integer represents the subnet")
+			return 0, 0, &ConfigError{Path: netwType, Msg: "error in sriov definition: -> sriov1.1 or sriov2.1, first integer represents the switch, 2nd integer represents the subnet"}
 		}
 		switchIndex, err = strconv.Atoi(strings.TrimPrefix(split[0], "sriov"))
 		if err != nil {
-			log.Fatalf("error in sriov definition: -> sriov1.1 or sriov2.1")
+			return 0, 0, &ConfigError{Path: netwType, Msg: "error in sriov definition: -> sriov1.1 or sriov2.1"}
 		}
 		log.Debugf("Sriov Name %s, NetworkIndex %d, SwitchIndex %d", netwType, networkIndex, switchIndex)
 
@@ -728,18 +749,21 @@ func getSwitchIndexes(netwType string) (int, int) {
 		// network Index = 0
 		// switchIndex = 0
 	}
-	return switchIndex, networkIndex
+	return switchIndex, networkIndex, nil
 
 }
 
-func getUpGatewaysPerLmg(switchInfo *switchInfo, wlName *string, pods *int) (map[int][]string, map[int][]string) {
+func getUpGatewaysPerLmg(switchInfo *switchInfo, wlName *string, pods *int) (map[int][]string, map[int][]string, error) {
 	upIpv4GWs := make(map[int][]string)
 	upIpv6GWs := make(map[int][]string)
 
-	for netwType := range switchInfo.switchGwsIPv4[*wlName] {
+	for _, netwType := range SortedKeys(switchInfo.switchGwsIPv4[*wlName]) {
 		if strings.Contains(netwType, "sriov") {
 			// indicates which switch the network is connected to
-			switchIndex, networkIndex := getSwitchIndexes(netwType)
+			switchIndex, networkIndex, err := getSwitchIndexes(netwType)
+			if err != nil {
+				return nil, nil, err
+			}
 			log.Infof("getUpGatewaysPerLmg: %d, %d %d", switchIndex, networkIndex, *pods)
 			if networkIndex <= *pods {
 				upIpv4GWs[switchIndex] = append(upIpv4GWs[switchIndex], switchInfo.switchGwsIPv4[*wlName][netwType][switchIndex][networkIndex-1])
@@ -747,17 +771,20 @@ func getUpGatewaysPerLmg(switchInfo *switchInfo, wlName *string, pods *int) (map
 		}
 	}
 
-	for netwType := range switchInfo.switchGwsIPv6[*wlName] {
+	for _, netwType := range SortedKeys(switchInfo.switchGwsIPv6[*wlName]) {
 		if strings.Contains(netwType, "sriov") {
 			// indicates which switch the network is connected to
-			switchIndex, networkIndex := getSwitchIndexes(netwType)
+			switchIndex, networkIndex, err := getSwitchIndexes(netwType)
+			if err != nil {
+				return nil, nil, err
+			}
 			if networkIndex <= *pods {
 				upIpv6GWs[switchIndex] = append(upIpv6GWs[switchIndex], switchInfo.switchGwsIPv4[*wlName][netwType][switchIndex][networkIndex-1])
 			}
 		}
 	}
 
-	return upIpv4GWs, upIpv6GWs
+	return upIpv4GWs, upIpv6GWs, nil
 }
 
 func getUpGateways(switchInfo *switchInfo, wlName, deployment *string) (map[int][]string, map[int][]string) {
@@ -796,7 +823,7 @@ func getUpGateways(switchInfo *switchInfo, wlName, deployment *string) (map[int]
 	return upIpv4GWs, upIpv6GWs
 }
 
-func AllocateIP(ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, cnfName *string, group *int, allocShortName, allocLongName *string, ipIndex *int, ipv4Net, ipv6Net *net.IPNet, deployIPAM map[string]*IpamApp) (*string, *string) {
+func AllocateIP(ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, cnfName *string, group *int, allocShortName, allocLongName *string, ipIndex *int, ipv4Net, ipv6Net *net.IPNet, deployIPAM map[string]*IpamApp) (*string, *string, error) {
 	if len((*ipAddresses)["ipv4"][*group][*allocShortName]) == 0 {
 		(*ipAddresses)["ipv4"][*group][*allocShortName] = make([]*AllocatedIPInfo, 0)
 		(*ipAddresses)["ipv6"][*group][*allocShortName] = make([]*AllocatedIPInfo, 0)
@@ -809,20 +836,20 @@ func AllocateIP(ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, c
 
 	allocateIPv4, err := AllocateIPIndex(cnfName, allocLongName, ipIndex, ipv4Net, deployIPAM)
 	if err != nil {
-		log.WithError(err).Errorf("Allocating IP error")
+		return nil, nil, err
 	}
 	(*ipAddresses)["ipv4"][*group][*allocShortName] = append((*ipAddresses)["ipv4"][*group][*allocShortName], allocateIPv4)
 
 	allocateIPv6, err := AllocateIPIndex(cnfName, allocLongName, ipIndex, ipv6Net, deployIPAM)
 	if err != nil {
-		log.WithError(err).Errorf("Allocating IP error")
+		return nil, nil, err
 	}
 	(*ipAddresses)["ipv6"][*group][*allocShortName] = append((*ipAddresses)["ipv6"][*group][*allocShortName], allocateIPv6)
 
-	return allocateIPv4.IPAddress, allocateIPv6.IPAddress
+	return allocateIPv4.IPAddress, allocateIPv6.IPAddress, nil
 }
 
-func (a *AppConfig) AssignNetworkInfoLoopback(group *int, itfceType, wlName, multusGenericWlName *string, netwInfo *NetworkInfo, ipv4PrefixLength, ipv6PrefixLength *int, ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, switchInfo *switchInfo, cnfInfo *CnfInfo, cnfName, ipv4BGPAddress, ipv6BGPAddress *string, multusInfo map[string]*MultusInfo) {
+func (a *AppConfig) AssignNetworkInfoLoopback(group *int, itfceType, wlName, multusGenericWlName *string, netwInfo *NetworkInfo, ipv4PrefixLength, ipv6PrefixLength *int, ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, switchInfo *switchInfo, cnfInfo *CnfInfo, cnfName, ipv4BGPAddress, ipv6BGPAddress *string, multusInfo map[string]*MultusInfo) error {
 
 	if len(a.Networks[*multusGenericWlName][*group]) == 0 {
 		a.Networks[*multusGenericWlName][*group] = make(map[int]map[string]map[string][]*RenderedNetworkInfo)
@@ -876,7 +903,10 @@ func (a *AppConfig) AssignNetworkInfoLoopback(group *int, itfceType, wlName, mul
 		a.Networks[*multusGenericWlName][*group][0]["loopback"][*itfceType] = append(a.Networks[*multusGenericWlName][*group][0]["loopback"][*itfceType], rnInfo)
 
 		if *itfceType == "lmgLpb" && a.Lmgs != nil {
-			upIpv4GWs, upIpv6GWs := getUpGatewaysPerLmg(switchInfo, wlName, a.Lmgs)
+			upIpv4GWs, upIpv6GWs, err := getUpGatewaysPerLmg(switchInfo, wlName, a.Lmgs)
+			if err != nil {
+				return err
+			}
 			rnInfo.Ipv4GwPerWl = upIpv4GWs
 			rnInfo.Ipv6GwPerWl = upIpv6GWs
 		}
@@ -906,7 +936,7 @@ func (a *AppConfig) AssignNetworkInfoLoopback(group *int, itfceType, wlName, mul
 			rnInfo.NetworkShortName = StringPtr("gilan")
 		}
 	}
-
+	return nil
 }
 
 func (a *AppConfig) AssignNetworkInfoItfce(group *int, itfceType, wlName, multusGenericWlName *string, netwInfo *NetworkInfo, ipv4PrefixLength, ipv6PrefixLength *int, ipAddresses *map[string]map[int]map[string][]*AllocatedIPInfo, switchInfo *switchInfo, cnfInfo *CnfInfo, cnfName *string, networkIndex, switchIndex *int, clientLinkInfo *ClientLinkInfo, fipv4, fipv6, connType, netwType *string, multusInfo map[string]*MultusInfo) {
@@ -1018,16 +1048,16 @@ func (a *AppConfig) UpdateWorkloadShortNames(multusGenericWlName *string) {
 	}
 }
 
-func (p *Parser) GetApplicationIndex(itfceType, element, kind *string) *int {
+func (p *Parser) GetApplicationIndex(itfceType, element, kind *string) (*int, error) {
 	// get application indexes
-	if _, ok := p.Config.AppNetworkIndexes[*itfceType][*element][*kind]; !ok {
-		log.Fatalf(fmt.Sprintf("Assign app-netw-indexes/%s/%s/%s", *itfceType, *element, *kind))
+	if idx, ok := p.Config.AppNetworkIndexes[*itfceType][*element][*kind]; !ok || idx == nil {
+		return nil, &ConfigError{Path: fmt.Sprintf("appnetwindexes.%s.%s.%s", *itfceType, *element, *kind), Msg: "index is required but not assigned"}
 	}
-	return p.Config.AppNetworkIndexes[*itfceType][*element][*kind]
+	return p.Config.AppNetworkIndexes[*itfceType][*element][*kind], nil
 }
 
 // initialize the network data per cnf
-func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p *Parser, appIPMap *AppIPMap, clientServer2NetworkLinks map[string]map[string]map[int]map[string][]*string, switchInfo *switchInfo) {
+func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p *Parser, appIPMap *AppIPMap, clientServer2NetworkLinks map[string]map[string]map[int]map[string][]*string, switchInfo *switchInfo) error {
 
 	// initialize switches
 	a.SwitchesPerServer = p.SwitchInfo.switchesPerServer
@@ -1054,12 +1084,14 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 	bgpCnfSrcIPv6 := make(map[string]map[string]string)
 
 	// check all networks that are relevant for the app
-	for multusGenericWlName, multusInfo := range cnfInfo.Networking.Multus {
+	for _, multusGenericWlName := range SortedKeys(cnfInfo.Networking.Multus) {
+		multusInfo := cnfInfo.Networking.Multus[multusGenericWlName]
 		// loop over all networks in the workloads
 
 		a.UpdateWorkloadShortNames(StringPtr(multusGenericWlName))
 
-		for wlName, clients := range p.Config.Workloads {
+		for _, wlName := range SortedKeys(p.Config.Workloads) {
+			clients := p.Config.Workloads[wlName]
 			// if the network is relevant for the app we continue the processing
 
 			if wlName == *multusInfo.WorkloadName {
@@ -1075,8 +1107,10 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 				a.Networks[multusGenericWlName][0][0]["loopback"]["sysLbk"] = make([]*RenderedNetworkInfo, 0)
 				a.Networks[multusGenericWlName][0][0]["loopback"]["llbLbk"] = make([]*RenderedNetworkInfo, 0)
 
-				for _, wlInfo := range clients {
-					for netwType, netwInfo := range wlInfo.Loopbacks {
+				for _, cgName := range SortedKeys(clients) {
+					wlInfo := clients[cgName]
+					for _, netwType := range SortedKeys(wlInfo.Loopbacks) {
+						netwInfo := wlInfo.Loopbacks[netwType]
 						// initialize the loopback based networking informaation
 						// BGP, LLB, LMG loopbacks
 						// SMF, UPF loopbacks per multus network type -> TBD
@@ -1088,11 +1122,11 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 
 								_, ipv4Net, err := net.ParseCIDR(*netwInfo.Ipv4Cidr[i])
 								if err != nil {
-									log.WithError(err).Error("Cidr Parsing error")
+									return &ConfigError{Path: "workloads." + wlName + "." + netwType, Msg: err.Error()}
 								}
 								_, ipv6Net, err := net.ParseCIDR(*netwInfo.Ipv6Cidr[i])
 								if err != nil {
-									log.WithError(err).Error("Cidr Parsing error")
+									return &ConfigError{Path: "workloads." + wlName + "." + netwType, Msg: err.Error()}
 								}
 
 								// key1, ipv4 or ipv6, key2: group, key3: bgp/system/lmg-pod/llb-pod lbks
@@ -1112,59 +1146,101 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 								case "smf":
 									// allocate BGP Loopback
 									//-> ipAddresses["ipv4"][0]["bgpLbk"] = make([]*AllocatedIPInfo, 0)
-									bgpidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("bgp"))
-									ipv4BGPAddress, ipv6BGPAddress = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("bgpLbk"), StringPtr("BGP loopback"), bgpidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									bgpidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("bgp"))
+									if err != nil {
+										return err
+									}
+									ipv4BGPAddress, ipv6BGPAddress, err = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("bgpLbk"), StringPtr("BGP loopback"), bgpidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									if err != nil {
+										return err
+									}
 
 									// allocate SigLoopback
 									//-> ipAddresses["ipv4"][0]["sigLbk"] = make([]*AllocatedIPInfo, 0)
-									llbsigidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-sig"))
-									AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sigLbk"), StringPtr("Sig loopback"), llbsigidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									llbsigidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-sig"))
+									if err != nil {
+										return err
+									}
+									if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sigLbk"), StringPtr("Sig loopback"), llbsigidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+										return err
+									}
 
 									appIPMap.IPinfo[multusGenericWlName].SmfIPv4 = ipAddresses["ipv4"][0]["sigLbk"][0].IPAddress
 									appIPMap.IPinfo[multusGenericWlName].SmfIPv6 = ipAddresses["ipv6"][0]["sigLbk"][0].IPAddress
 
 									// allocate System Loopback
 									if multusGenericWlName == "3GPP_Internal" {
-										sysidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("system"))
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sysLbk"), StringPtr("System loopback"), sysidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										sysidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("system"))
+										if err != nil {
+											return err
+										}
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sysLbk"), StringPtr("System loopback"), sysidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 
 									// allocate LLB loopbacks
 									llbs := getLLBs(cnfInfo)
 									a.Llbs = IntPtr(llbs)
-									llbpodidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-pod"))
+									llbpodidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-pod"))
+									if err != nil {
+										return err
+									}
 									for i := 0; i < llbs; i++ {
 										// allocate BGP Loopback
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("llbLbk"), StringPtr("LLB loopback"), IntPtr(*llbpodidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("llbLbk"), StringPtr("LLB loopback"), IntPtr(*llbpodidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 								case "upf":
 									// allocate BGP Loopback
 									//-> ipAddresses["ipv4"][0]["bgpLbk"] = make([]*AllocatedIPInfo, 0)
-									bgpidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("bgp"))
-									ipv4BGPAddress, ipv6BGPAddress = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("bgpLbk"), StringPtr("BGP loopback"), bgpidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									bgpidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("bgp"))
+									if err != nil {
+										return err
+									}
+									ipv4BGPAddress, ipv6BGPAddress, err = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("bgpLbk"), StringPtr("BGP loopback"), bgpidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									if err != nil {
+										return err
+									}
 
 									// allocate SigLoopback
 									//-> ipAddresses["ipv4"][0]["sigLbk"] = make([]*AllocatedIPInfo, 0)
-									llbsigidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-sig"))
-									AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sigLbk"), StringPtr("Sig loopback"), llbsigidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									llbsigidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-sig"))
+									if err != nil {
+										return err
+									}
+									if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sigLbk"), StringPtr("Sig loopback"), llbsigidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+										return err
+									}
 
 									appIPMap.IPinfo[multusGenericWlName].UpfCpIPv4 = ipAddresses["ipv4"][0]["sigLbk"][0].IPAddress
 									appIPMap.IPinfo[multusGenericWlName].UpfCpIPv6 = ipAddresses["ipv6"][0]["sigLbk"][0].IPAddress
 
 									// allocate System Loopback
 									if multusGenericWlName == "3GPP_Internal" {
-										sysidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("system"))
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sysLbk"), StringPtr("System loopback"), sysidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										sysidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("system"))
+										if err != nil {
+											return err
+										}
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("sysLbk"), StringPtr("System loopback"), sysidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 
 									// alloacte LLB loopbacks
 									llbs := getLLBs(cnfInfo)
 									a.Llbs = IntPtr(llbs)
-									llbpodidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-pod"))
+									llbpodidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("llb-pod"))
+									if err != nil {
+										return err
+									}
 									for i := 0; i < llbs; i++ {
 										// allocate BGP Loopback
 										//-> ipAddresses["ipv4"][0]["bgpLbk"] = make([]*AllocatedIPInfo, 0)
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("llbLbk"), StringPtr("LLB loopback"), IntPtr(*llbpodidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("llbLbk"), StringPtr("LLB loopback"), IntPtr(*llbpodidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 
 									lmgs, lmgPodsPerGroup = getLMGs(cnfInfo, a.K)
@@ -1173,8 +1249,14 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 
 									// first loop over lmg groups = total lmgs divide bylmgPodsPerGroup
 									// 2nd loop over lmgs per group
-									lmgpodidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("lmg-pod"))
-									lmgsigidx := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("lmg-sig"))
+									lmgpodidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("lmg-pod"))
+									if err != nil {
+										return err
+									}
+									lmgsigidx, err := p.GetApplicationIndex(StringPtr("loopback"), StringPtr(cnfName), StringPtr("lmg-sig"))
+									if err != nil {
+										return err
+									}
 									for g := 1; g <= lmgs/lmgPodsPerGroup; g++ {
 										ipAddresses["ipv4"][g] = make(map[string][]*AllocatedIPInfo)
 										ipAddresses["ipv6"][g] = make(map[string][]*AllocatedIPInfo)
@@ -1184,11 +1266,15 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 											//-> ipAddresses["ipv4"][g]["lmgLbk"] = make([]*AllocatedIPInfo, 0)
 											log.Debugf("lmgLbk: %d", g)
 
-											AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("lmgLbk"), StringPtr("LMG loopback"), IntPtr(*lmgpodidx+i+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+											if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("lmgLbk"), StringPtr("LMG loopback"), IntPtr(*lmgpodidx+i+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+												return err
+											}
 										}
 
 										// allocate Sig Up Loopback per lmg group
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("sigLbk"), StringPtr("Sig loopback"), IntPtr(*lmgsigidx+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("sigLbk"), StringPtr("Sig loopback"), IntPtr(*lmgsigidx+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
 										//appIPMap.IPinfo[multusGenericWlName].UpfUpIPv4 = allocateIP.IPAddress
 										//appIPMap.IPinfo[multusGenericWlName].UpfUpIPv6 = allocateIP.IPAddress
@@ -1198,32 +1284,44 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 								log.Debugf("Loopbacks per cnf: %s", cnfName)
 
 								// bgp loopback
-								a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("bgpLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus)
+								if err := a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("bgpLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+									return err
+								}
 
 								// system loopback
-								a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("sysLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus)
+								if err := a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("sysLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+									return err
+								}
 
 								// signalling control plane loopback
-								a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("sigLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus)
+								if err := a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("sigLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv6BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+									return err
+								}
 
 								if cnfName == "upf" {
 									if lmgPodsPerGroup > 0 {
 										for g := 1; g <= lmgs/lmgPodsPerGroup; g++ {
 											// signalling control plane loopback per group
-											a.AssignNetworkInfoLoopback(IntPtr(g), StringPtr("sigLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus)
+											if err := a.AssignNetworkInfoLoopback(IntPtr(g), StringPtr("sigLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+												return err
+											}
 										}
 									}
 								}
 
 								// llb loopback
-								a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("llbLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus)
+								if err := a.AssignNetworkInfoLoopback(IntPtr(0), StringPtr("llbLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+									return err
+								}
 
 								if lmgPodsPerGroup > 0 {
 									log.Debugf("lmgLbk lmg lmgPodsPerGroup: %d %d", lmgs, lmgPodsPerGroup)
 									for g := 1; g <= lmgs/lmgPodsPerGroup; g++ {
 										// lmg loopback
 										log.Debugf("lmgLbk: %d", g)
-										a.AssignNetworkInfoLoopback(IntPtr(g), StringPtr("lmgLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus)
+										if err := a.AssignNetworkInfoLoopback(IntPtr(g), StringPtr("lmgLbk"), &wlName, &multusGenericWlName, netwInfo, IntPtr(32), IntPtr(128), &ipAddresses, switchInfo, cnfInfo, &cnfName, ipv4BGPAddress, ipv4BGPAddress, p.Config.Application["paco"].Global.Multus); err != nil {
+											return err
+										}
 									}
 								}
 							}
@@ -1232,7 +1330,8 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 					// initialize the Multus interface related information
 					// netwType is ipvlan, sriov1.x, sriov2.x
 					count := 0
-					for netwType, netwInfo := range wlInfo.Itfces {
+					for _, netwType := range SortedKeys(wlInfo.Itfces) {
+						netwInfo := wlInfo.Itfces[netwType]
 						// Allocate the CNF interface IP(s)
 
 						// Only process the information that is relevant for the application
@@ -1249,7 +1348,10 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 							ipAddresses["ipv6"][0] = make(map[string][]*AllocatedIPInfo)
 
 							// indicates which switch the network is connected to
-							switchIndex, networkIndex := getSwitchIndexes(netwType)
+							switchIndex, networkIndex, err := getSwitchIndexes(netwType)
+							if err != nil {
+								return err
+							}
 
 							var fipv4 *string
 							var fipv6 *string
@@ -1269,11 +1371,11 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 
 								_, ipv4Net, err := net.ParseCIDR(*ipv4Cidr)
 								if err != nil {
-									log.WithError(err).Error("Cidr Parsing error")
+									return &ConfigError{Path: "workloads." + wlName + "." + netwType, Msg: err.Error()}
 								}
 								_, ipv6Net, err := net.ParseCIDR(*ipv6Cidr)
 								if err != nil {
-									log.WithError(err).Error("Cidr Parsing error")
+									return &ConfigError{Path: "workloads." + wlName + "." + netwType, Msg: err.Error()}
 								}
 
 								ipv4PrefixLength, _ = ipv4Net.Mask.Size()
@@ -1287,39 +1389,58 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 									// allocate an ip per llb from each subnet, max 6 subnets (6 llbs per cnf upf)
 									llbs := getLLBs(cnfInfo)
 
-									llbitfcidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("llb"))
+									llbitfcidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("llb"))
+									if err != nil {
+										return err
+									}
 									if i < llbs && networkIndex <= llbs {
 										log.Infof("SMF LLBs: %d, %d, %d", llbs, i, networkIndex)
 										// allocate interface LLB
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface LLB"), llbitfcidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface LLB"), llbitfcidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 
 									// update unique links to ensure we map the right multus interface
-									clientLink = p.UpdateUniqueClientLink(cnfInfo, StringPtr("llb"), connType, a.UniqueClientServer2NetworkLinks)
+									clientLink, err = p.UpdateUniqueClientLink(cnfInfo, StringPtr("llb"), connType, a.UniqueClientServer2NetworkLinks)
+									if err != nil {
+										return err
+									}
 
 								case "upf":
 									// allocate an ip per llb from each subnet, max 6 subnets (6 llbs per cnf upf)
 									llbs := getLLBs(cnfInfo)
 
 									// update unique links to ensure we map the right multus interface
-									clientLink = p.UpdateUniqueClientLink(cnfInfo, StringPtr("llb"), connType, a.UniqueClientServer2NetworkLinks)
+									clientLink, err = p.UpdateUniqueClientLink(cnfInfo, StringPtr("llb"), connType, a.UniqueClientServer2NetworkLinks)
+									if err != nil {
+										return err
+									}
 
 									//a.ClientLinks[*connType]["llb"] = clientLink
 
 									//a.UpdateUniqueClientLinks(connType, clientLink)
 
-									llbitfcidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("llb"))
+									llbitfcidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("llb"))
+									if err != nil {
+										return err
+									}
 									if i < llbs && networkIndex <= llbs {
 										log.Infof("UPF LLBs: %d, %d, %d", llbs, i, networkIndex)
 										// allocate interface LLB
 										//-> ipAddresses["ipv4"][0]["intLbk"] = make([]*AllocatedIPInfo, 0)
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface LLB"), llbitfcidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface LLB"), llbitfcidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 									// the amount of subnets per sriov differs for ntok versus 1to1
 									lmgs, lmgPodsPerGroup = getLMGs(cnfInfo, a.K)
 
 									// update unique links to ensure we map the right multus interface
-									lmgClientLink = p.UpdateUniqueClientLink(cnfInfo, StringPtr("lmg"), connType, a.UniqueClientServer2NetworkLinks)
+									lmgClientLink, err = p.UpdateUniqueClientLink(cnfInfo, StringPtr("lmg"), connType, a.UniqueClientServer2NetworkLinks)
+									if err != nil {
+										return err
+									}
 
 									//lmgClientLink = p.GetClientLink(cnfInfo, StringPtr("lmg"), connType)
 
@@ -1332,7 +1453,10 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 									// allocate the ips in the respective subnet based on th deployment strategy
 									// for 1to1 we use 2 pods per group, for ntok 1 pod per group
 									log.Debugf("lmgPodsPerGroup: %d, %d, %d", lmgPodsPerGroup, networkIndex, lmgs)
-									lmgitfcidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("lmg"))
+									lmgitfcidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("lmg"))
+									if err != nil {
+										return err
+									}
 									if i < lmgPodsPerGroup && networkIndex <= lmgPodsPerGroup {
 										log.Infof("UPF LMGs: %d, %d, %d %d", lmgs, lmgPodsPerGroup, i, networkIndex)
 										// within the subnet we allocate the amount of ips based on the deployment strategy, we allocate based on lngs per subnet
@@ -1344,7 +1468,9 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 											ipAddresses["ipv4"][g] = make(map[string][]*AllocatedIPInfo)
 											ipAddresses["ipv6"][g] = make(map[string][]*AllocatedIPInfo)
 											for i := 0; i < lmgPodsPerGroup; i++ {
-												AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("intIP"), StringPtr("interface LMG"), IntPtr(*lmgitfcidx+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+												if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(g), StringPtr("intIP"), StringPtr("interface LMG"), IntPtr(*lmgitfcidx+g-1), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+													return err
+												}
 											}
 										}
 									}
@@ -1352,17 +1478,28 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 								case "amf":
 									// IPv4 and IPv6
 									// allocate 4 addresses out of the multus network
-									itfcidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("int"))
+									itfcidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("int"))
+									if err != nil {
+										return err
+									}
 									for i := 0; i < 4; i++ {
-										AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface AMF"), IntPtr(*itfcidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if _, _, err := AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("intIP"), StringPtr("interface AMF"), IntPtr(*itfcidx+i), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
+									}
+									fipidx, err := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("fip"))
+									if err != nil {
+										return err
+									}
+									fipv4, fipv6, err = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("fipIP"), StringPtr("interface AMF"), fipidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+									if err != nil {
+										return err
 									}
-									fipidx := p.GetApplicationIndex(StringPtr("itfce"), StringPtr(cnfName), StringPtr("fip"))
-									fipv4, fipv6 = AllocateIP(&ipAddresses, StringPtr(cnfName), IntPtr(0), StringPtr("fipIP"), StringPtr("interface AMF"), fipidx, ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
 
 									// TODO assignment of clientLinks[*connType][0] -> currently we take the first interface
 									//clientLink = clientLinks[*connType][0]
 									//clientLink = p.UpdateUniqueClientLink(cnfInfo, StringPtr("lmg"), connType, a.UniqueClientServer2NetworkLinks)
-									for clientIntfaceName := range p.ClientServer2NetworkLinks[*connType] {
+									for _, clientIntfaceName := range SortedKeys(p.ClientServer2NetworkLinks[*connType]) {
 										clientLink = &ClientLinkInfo{&clientIntfaceName, IntPtr(0)}
 									}
 
@@ -1371,7 +1508,9 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 									case "oam":
 									case "3GPP_External":
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n2"), StringPtr(multusGenericWlName), IntPtr(110), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n2"), StringPtr(multusGenericWlName), IntPtr(110), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
 									case "3GPP_Internal":
 
@@ -1379,65 +1518,121 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 
 									case "3GPP_SBA":
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n8"), StringPtr(multusGenericWlName), IntPtr(108), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n8"), StringPtr(multusGenericWlName), IntPtr(108), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n11"), StringPtr(multusGenericWlName), IntPtr(111), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n11"), StringPtr(multusGenericWlName), IntPtr(111), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n12"), StringPtr(multusGenericWlName), IntPtr(112), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n12"), StringPtr(multusGenericWlName), IntPtr(112), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n14"), StringPtr(multusGenericWlName), IntPtr(114), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n14"), StringPtr(multusGenericWlName), IntPtr(114), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n15"), StringPtr(multusGenericWlName), IntPtr(115), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n15"), StringPtr(multusGenericWlName), IntPtr(115), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n17"), StringPtr(multusGenericWlName), IntPtr(117), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n17"), StringPtr(multusGenericWlName), IntPtr(117), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n22"), StringPtr(multusGenericWlName), IntPtr(122), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n22"), StringPtr(multusGenericWlName), IntPtr(122), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n20"), StringPtr(multusGenericWlName), IntPtr(120), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n20"), StringPtr(multusGenericWlName), IntPtr(120), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n26"), StringPtr(multusGenericWlName), IntPtr(126), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("n26"), StringPtr(multusGenericWlName), IntPtr(126), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nnrf"), StringPtr(multusGenericWlName), IntPtr(109), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nnrf"), StringPtr(multusGenericWlName), IntPtr(109), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nsms"), StringPtr(multusGenericWlName), IntPtr(130), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nsms"), StringPtr(multusGenericWlName), IntPtr(130), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcDefaultIp"), StringPtr(multusGenericWlName), IntPtr(105), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcDefaultIp"), StringPtr(multusGenericWlName), IntPtr(105), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcLocIp"), StringPtr(multusGenericWlName), IntPtr(131), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcLocIp"), StringPtr(multusGenericWlName), IntPtr(131), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcComIp"), StringPtr(multusGenericWlName), IntPtr(132), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcComIp"), StringPtr(multusGenericWlName), IntPtr(132), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcEeIp"), StringPtr(multusGenericWlName), IntPtr(133), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcEeIp"), StringPtr(multusGenericWlName), IntPtr(133), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcMtIp"), StringPtr(multusGenericWlName), IntPtr(134), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("amfSvcMtIp"), StringPtr(multusGenericWlName), IntPtr(134), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyEirIp"), StringPtr(multusGenericWlName), IntPtr(135), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyEirIp"), StringPtr(multusGenericWlName), IntPtr(135), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyAmfIp"), StringPtr(multusGenericWlName), IntPtr(136), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyAmfIp"), StringPtr(multusGenericWlName), IntPtr(136), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyAusfIp"), StringPtr(multusGenericWlName), IntPtr(137), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyAusfIp"), StringPtr(multusGenericWlName), IntPtr(137), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyNrfIp"), StringPtr(multusGenericWlName), IntPtr(138), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyNrfIp"), StringPtr(multusGenericWlName), IntPtr(138), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyNssfIp"), StringPtr(multusGenericWlName), IntPtr(139), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyNssfIp"), StringPtr(multusGenericWlName), IntPtr(139), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyPcfIp"), StringPtr(multusGenericWlName), IntPtr(140), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyPcfIp"), StringPtr(multusGenericWlName), IntPtr(140), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfySmfIp"), StringPtr(multusGenericWlName), IntPtr(141), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfySmfIp"), StringPtr(multusGenericWlName), IntPtr(141), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyudmIp"), StringPtr(multusGenericWlName), IntPtr(142), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("nfyudmIp"), StringPtr(multusGenericWlName), IntPtr(142), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("dns1"), StringPtr(multusGenericWlName), IntPtr(151), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("dns1"), StringPtr(multusGenericWlName), IntPtr(151), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("dns2"), StringPtr(multusGenericWlName), IntPtr(152), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("amf"), StringPtr("dns2"), StringPtr(multusGenericWlName), IntPtr(152), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("ausf"), StringPtr("ausf"), StringPtr(multusGenericWlName), IntPtr(107), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("ausf"), StringPtr("ausf"), StringPtr(multusGenericWlName), IntPtr(107), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 
-										appIPMap.AllocateAppLoopback(StringPtr("udm"), StringPtr("udm"), StringPtr(multusGenericWlName), IntPtr(106), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType])
+										if err := appIPMap.AllocateAppLoopback(StringPtr("udm"), StringPtr("udm"), StringPtr(multusGenericWlName), IntPtr(106), ipv4Net, ipv6Net, p.DeploymentIPAM[wlName][netwType]); err != nil {
+											return err
+										}
 									}
 								}
 							}
-							if len(ipAddresses) > 0 || len(ipAddresses) > 0 {
+							if len(ipAddresses) > 0 {
 
 								count++
 								log.Debugf("Count: %d", count)
@@ -1459,9 +1654,10 @@ func (a *AppConfig) InitializeCnfNetworkData(cnfName string, cnfInfo *CnfInfo, p
 			}
 		}
 	}
+	return nil
 }
 
-func (p *Parser) UpdateUniqueClientLink(cnfInfo *CnfInfo, podName, connType *string, uniqueClientServer2NetworkLinks map[string]map[string]map[int]map[string][]*string) *ClientLinkInfo {
+func (p *Parser) UpdateUniqueClientLink(cnfInfo *CnfInfo, podName, connType *string, uniqueClientServer2NetworkLinks map[string]map[string]map[int]map[string][]*string) (*ClientLinkInfo, error) {
 	// for connecton type sriov we need to understand the numa placement
 	var n int // numa
 	if x, ok := cnfInfo.Pods[*podName]["numa"]; ok {
@@ -1469,14 +1665,15 @@ func (p *Parser) UpdateUniqueClientLink(cnfInfo *CnfInfo, podName, connType *str
 		case int:
 			n = v
 		default:
-			log.Fatal("Numa should be specified in LLB or LMG pods as integer")
+			return nil, &ConfigError{Path: "application.paco.cnfs." + *podName + ".numa", Msg: "numa should be specified in llb or lmg pods as integer"}
 		}
 	} else {
-		log.Fatal("Numa should be specified in LLB or LMG pods")
+		return nil, &ConfigError{Path: "application.paco.cnfs." + *podName + ".numa", Msg: "numa should be specified in llb or lmg pods"}
 	}
 
-	for clientLinkName, clientLinkInfo := range p.ClientServer2NetworkLinks[*connType] {
-		for numa := range clientLinkInfo {
+	for _, clientLinkName := range SortedKeys(p.ClientServer2NetworkLinks[*connType]) {
+		clientLinkInfo := p.ClientServer2NetworkLinks[*connType][clientLinkName]
+		for _, numa := range SortedIntKeys(clientLinkInfo) {
 			if numa == n {
 				if _, ok := uniqueClientServer2NetworkLinks[*connType]; !ok {
 					uniqueClientServer2NetworkLinks[*connType] = make(map[string]map[int]map[string][]*string)
@@ -1493,13 +1690,12 @@ func (p *Parser) UpdateUniqueClientLink(cnfInfo *CnfInfo, podName, connType *str
 					}
 				}
 				uniqueClientServer2NetworkLinks[*connType][clientLinkName][numa] = p.ClientServer2NetworkLinks[*connType][clientLinkName][numa]
-				return &ClientLinkInfo{&clientLinkName, &numa}
+				return &ClientLinkInfo{&clientLinkName, &numa}, nil
 			}
 		}
 	}
 
-	log.Fatal("Numa not found")
-	return nil
+	return nil, &TopologyError{Element: "numa " + strconv.Itoa(n), Msg: "no " + *connType + " client link found for pod " + *podName}
 }
 
 func getLLBs(cnfInfo *CnfInfo) int {
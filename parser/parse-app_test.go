@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAllocateIPIndex(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		index   int
+		want    string
+		wantErr bool
+	}{
+		{cidr: "10.0.20.0/24", index: 1, want: "10.0.20.1"},
+		{cidr: "10.0.20.0/24", index: 151, want: "10.0.20.151"},
+		{cidr: "10.0.21.32/27", index: 4, want: "10.0.21.36"},
+		{cidr: "10.0.20.0/24", index: -1, want: "10.0.20.255"},
+		{cidr: "10.0.20.0/24", index: 256, wantErr: true},
+		{cidr: "2a02:1800:80:7200::/64", index: 151, want: "2a02:1800:80:7200::97"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			_, ipNet, err := net.ParseCIDR(tt.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ipam := make(map[string]*IpamApp)
+			got, err := AllocateIPIndex(StringPtr("amf"), StringPtr("interface AMF"), IntPtr(tt.index), ipNet, ipam)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", *got.IPAddress)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got.IPAddress != tt.want {
+				t.Errorf("got %s, want %s", *got.IPAddress, tt.want)
+			}
+			if *got.Application != "amf" || *got.Usage != "interface AMF" {
+				t.Errorf("got application %s usage %s", *got.Application, *got.Usage)
+			}
+			if len(ipam[tt.cidr].AllocatedIPs) != 1 {
+				t.Errorf("got %d allocations, want 1", len(ipam[tt.cidr].AllocatedIPs))
+			}
+		})
+	}
+}
+
+func TestAllocateIPIndexDuplicate(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("10.0.20.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipam := make(map[string]*IpamApp)
+	for _, index := range []int{1, 2, 1} {
+		if _, err := AllocateIPIndex(StringPtr("switch"), StringPtr("gateway"), IntPtr(index), ipNet, ipam); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the same address is recorded only once
+	if len(ipam["10.0.20.0/24"].AllocatedIPs) != 2 {
+		t.Errorf("got %d allocations, want 2", len(ipam["10.0.20.0/24"].AllocatedIPs))
+	}
+}
+
+func TestGetLMGs(t *testing.T) {
+	tests := []struct {
+		name           string
+		deployment     string
+		lmg            map[string]interface{}
+		k              int
+		wantLmgs       int
+		wantPodsPerGrp int
+	}{
+		{name: "ntok", deployment: "ntok", lmg: map[string]interface{}{"total": 15}, k: 1, wantLmgs: 14, wantPodsPerGrp: 1},
+		{name: "1to1", deployment: "1to1", lmg: map[string]interface{}{"total": 4}, k: 2, wantLmgs: 2, wantPodsPerGrp: 2},
+		{name: "max", deployment: "ntok", lmg: map[string]interface{}{"total": 20}, k: 1, wantLmgs: 15, wantPodsPerGrp: 1},
+		{name: "no total", deployment: "ntok", lmg: map[string]interface{}{}, k: 2, wantLmgs: 14, wantPodsPerGrp: 1},
+		{name: "unknown deployment", deployment: "nto1", lmg: map[string]interface{}{"total": 3}, k: 1, wantLmgs: 2, wantPodsPerGrp: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnfInfo := &CnfInfo{
+				Deployment: StringPtr(tt.deployment),
+				Pods:       map[string]map[string]interface{}{"lmg": tt.lmg},
+			}
+			lmgs, podsPerGroup := getLMGs(cnfInfo, IntPtr(tt.k))
+			if lmgs != tt.wantLmgs {
+				t.Errorf("got %d lmgs, want %d", lmgs, tt.wantLmgs)
+			}
+			if podsPerGroup != tt.wantPodsPerGrp {
+				t.Errorf("got %d lmg pods per group, want %d", podsPerGroup, tt.wantPodsPerGrp)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// underlay protocols of the fabric
+const (
+	// UnderlayEBGP peers the nodes of the fabric with eBGP on the isl subinterfaces
+	UnderlayEBGP = "ebgp"
+	// UnderlayISIS runs IS-IS on the isl subinterfaces and system0, the overlay
+	// is iBGP in the overlay AS
+	UnderlayISIS = "isis"
+	// UnderlayOSPF runs OSPFv3 on the isl subinterfaces and system0, the overlay
+	// is iBGP in the overlay AS
+	UnderlayOSPF = "ospf"
+)
+
+// underlayProtocols holds the supported underlay protocols
+var underlayProtocols = []string{UnderlayEBGP, UnderlayISIS, UnderlayOSPF}
+
+// isisLevels holds the supported IS-IS level capabilities
+var isisLevels = []string{"L1", "L2", "L1L2"}
+
+// the defaults of the igp parameters
+const (
+	defaultIsisLevel           = "L2"
+	defaultIsisArea            = "49.0001"
+	defaultOspfArea            = "0.0.0.0"
+	defaultIsisHelloInterval   = 9
+	defaultIsisHelloMultiplier = 3
+	defaultOspfHelloInterval   = 10
+	defaultOspfHelloMultiplier = 4
+	// ospfv3IPv4InstanceID is the first instance id of the ipv4 address family, RFC 5838
+	ospfv3IPv4InstanceID = 64
+)
+
+type k8ssrlprotocolsigp struct {
+	NetworkInstanceName string
+	Protocol            string // isis or ospf
+	RouterID            string
+	AddressFamilies     []string
+	// LevelCapability and Net are set for IS-IS
+	LevelCapability string
+	Levels          []int
+	Net             string
+	// Area is set for OSPF
+	Area string
+	// the timers are 0 when they are not set in the config
+	HelloInterval   int
+	HelloMultiplier int
+	DeadInterval    int
+	// Bfd enables bfd on the isl interfaces, the bfd of the subinterfaces
+	// follows the bgp_sessions
+	Bfd        bool
+	Interfaces []*igpInterface
+}
+
+type igpInterface struct {
+	Name    string // subinterface, e.g. ethernet-1/49.0
+	Passive bool
+}
+
+// ospfInstance is an OSPFv3 instance per address family
+type ospfInstance struct {
+	Name          string
+	AddressFamily string
+	InstanceID    int
+}
+
+// OspfInstances returns an OSPFv3 instance per address family of the igp
+func (igp *k8ssrlprotocolsigp) OspfInstances() []*ospfInstance {
+	instances := make([]*ospfInstance, 0, len(igp.AddressFamilies))
+	for _, af := range igp.AddressFamilies {
+		inst := &ospfInstance{Name: "underlay-v6", AddressFamily: af}
+		if af == "ipv4-unicast" {
+			inst.Name = "underlay-v4"
+			inst.InstanceID = ospfv3IPv4InstanceID
+		}
+		instances = append(instances, inst)
+	}
+	return instances
+}
+
+// underlayProtocol returns the underlay protocol of the fabric
+func (p *Parser) underlayProtocol() string {
+	protocols := p.Config.Infrastructure.Protocols
+	if protocols.Protocol == nil {
+		return ""
+	}
+	return *protocols.Protocol
+}
+
+// igpUnderlay returns true when the underlay is IS-IS or OSPF
+func (p *Parser) igpUnderlay() bool {
+	protocol := p.underlayProtocol()
+	return protocol == UnderlayISIS || protocol == UnderlayOSPF
+}
+
+// checkUnderlay returns an error for an underlay the parser cannot generate
+func (p *Parser) checkUnderlay() error {
+	protocols := p.Config.Infrastructure.Protocols
+	switch p.underlayProtocol() {
+	case UnderlayEBGP:
+		return nil
+	case UnderlayISIS, UnderlayOSPF:
+	default:
+		return &ConfigError{Path: "infrastructure.protocols.protocol", Msg: fmt.Sprintf("unknown protocol %s, supported values: %s", p.underlayProtocol(), strings.Join(underlayProtocols, ", "))}
+	}
+	if protocols.OverlayAs == nil {
+		return &ConfigError{Path: "infrastructure.protocols.overlay_as", Msg: fmt.Sprintf("protocol %s requires the overlay_as of the iBGP overlay", p.underlayProtocol())}
+	}
+	if p.overlayDesign() == OverlayEBGP {
+		return &ConfigError{Path: "infrastructure.protocols.overlay_design", Msg: fmt.Sprintf("overlay_design ebgp requires protocol ebgp, not %s", p.underlayProtocol())}
+	}
+	return nil
+}
+
+// igpProtocol returns the igp of a node of the fabric on its isl subinterfaces
+// and the passive system0
+func (p *Parser) igpProtocol(n *Node, islsubinterfaces []*k8ssrlsubinterface) *k8ssrlprotocolsigp {
+	protocols := p.Config.Infrastructure.Protocols
+	igp := &k8ssrlprotocolsigp{
+		NetworkInstanceName: "default",
+		Protocol:            p.underlayProtocol(),
+		RouterID:            routerID(n),
+		AddressFamilies:     addressFamilies(p.infraSchema()),
+		Interfaces:          make([]*igpInterface, 0, len(islsubinterfaces)+1),
+	}
+	for _, si := range islsubinterfaces {
+		igp.Interfaces = append(igp.Interfaces, &igpInterface{Name: si.InterfaceRealName + "." + si.VlanID})
+	}
+	igp.Interfaces = append(igp.Interfaces, &igpInterface{Name: "system0.0", Passive: true})
+
+	helloInterval, helloMultiplier := defaultIsisHelloInterval, defaultIsisHelloMultiplier
+	if igp.Protocol == UnderlayOSPF {
+		helloInterval, helloMultiplier = defaultOspfHelloInterval, defaultOspfHelloMultiplier
+	}
+	// the timers are only set when one of them is in the config, the other
+	// one then takes the default of the protocol
+	if protocols.HelloInterval != nil || protocols.HelloMultiplier != nil {
+		if protocols.HelloInterval != nil {
+			helloInterval = *protocols.HelloInterval
+		}
+		if protocols.HelloMultiplier != nil {
+			helloMultiplier = *protocols.HelloMultiplier
+		}
+		igp.HelloInterval = helloInterval
+		if igp.Protocol == UnderlayOSPF {
+			igp.DeadInterval = helloInterval * helloMultiplier
+		} else {
+			igp.HelloMultiplier = helloMultiplier
+		}
+	}
+
+	switch igp.Protocol {
+	case UnderlayISIS:
+		igp.LevelCapability = defaultIsisLevel
+		if protocols.IsisLevel != nil {
+			igp.LevelCapability = *protocols.IsisLevel
+		}
+		switch igp.LevelCapability {
+		case "L1":
+			igp.Levels = []int{1}
+		case "L2":
+			igp.Levels = []int{2}
+		default:
+			igp.Levels = []int{1, 2}
+		}
+		area := defaultIsisArea
+		if protocols.IsisArea != nil {
+			area = *protocols.IsisArea
+		}
+		igp.Net = isisNet(area, igp.RouterID)
+	case UnderlayOSPF:
+		igp.Area = defaultOspfArea
+		if protocols.OspfArea != nil {
+			igp.Area = *protocols.OspfArea
+		}
+	}
+	return igp
+}
+
+// isisNet returns the network entity title of the area and the system id
+// derived from the router id, e.g. 49.0001.1001.1210.0000.00 for
+// 100.112.100.0
+func isisNet(area, routerID string) string {
+	ip := net.ParseIP(routerID).To4()
+	if ip == nil {
+		return ""
+	}
+	digits := fmt.Sprintf("%03d%03d%03d%03d", ip[0], ip[1], ip[2], ip[3])
+	return fmt.Sprintf("%s.%s.%s.%s.00", area, digits[0:4], digits[4:8], digits[8:12])
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// igpConfig holds the igp and bgp of the default network instance
+type igpConfig struct {
+	Isis *struct {
+		Instance []struct {
+			Name            string   `json:"name"`
+			LevelCapability string   `json:"level-capability"`
+			Net             []string `json:"net"`
+			Interface       []struct {
+				InterfaceName string `json:"interface-name"`
+				Passive       bool   `json:"passive"`
+				Level         []struct {
+					LevelNumber int `json:"level-number"`
+					Timers      *struct {
+						HelloInterval   int `json:"hello-interval"`
+						HelloMultiplier int `json:"hello-multiplier"`
+					} `json:"timers"`
+				} `json:"level"`
+			} `json:"interface"`
+		} `json:"instance"`
+	} `json:"isis"`
+	Ospf *struct {
+		Instance []struct {
+			Name          string `json:"name"`
+			AddressFamily string `json:"address-family"`
+			InstanceID    int    `json:"instance-id"`
+			Area          []struct {
+				AreaID    string `json:"area-id"`
+				Interface []struct {
+					InterfaceName string `json:"interface-name"`
+					HelloInterval int    `json:"hello-interval"`
+					DeadInterval  int    `json:"dead-interval"`
+				} `json:"interface"`
+			} `json:"area"`
+		} `json:"instance"`
+	} `json:"ospf"`
+	Bgp struct {
+		AutonomousSystem uint32 `json:"autonomous-system"`
+		Group            []struct {
+			GroupName string `json:"group-name"`
+		} `json:"group"`
+		Neighbor []struct {
+			PeerAddress string `json:"peer-address"`
+			PeerGroup   string `json:"peer-group"`
+		} `json:"neighbor"`
+	} `json:"bgp"`
+}
+
+// generateIgp generates the fabric with an igp underlay and returns the
+// protocols of the default network instance of leaf1
+func generateIgp(t *testing.T, update func(p *Protocols)) *igpConfig {
+	t.Helper()
+	tree := struct {
+		NetworkInstance []struct {
+			Name      string     `json:"name"`
+			Protocols *igpConfig `json:"protocols"`
+		} `json:"network-instance"`
+	}{}
+	var overlayAs uint32
+	res := generateNodeJSON(t, func(cfg *Config) {
+		update(cfg.Infrastructure.Protocols)
+		overlayAs = *cfg.Infrastructure.Protocols.OverlayAs
+	}, "leaf1", &tree)
+	if *res.Nodes["dcgw1"].AS == overlayAs {
+		t.Errorf("got gateway AS %d, want an AS of the as pool", *res.Nodes["dcgw1"].AS)
+	}
+	for _, ni := range tree.NetworkInstance {
+		if ni.Name == "default" {
+			return ni.Protocols
+		}
+	}
+	t.Fatal("no default network instance")
+	return nil
+}
+
+func TestIgpUnderlay(t *testing.T) {
+	igp := generateIgp(t, func(p *Protocols) {
+		p.Protocol = StringPtr(UnderlayISIS)
+		p.IsisArea = StringPtr("49.0002")
+		p.HelloInterval = IntPtr(3)
+	})
+	if igp.Isis == nil || len(igp.Isis.Instance) != 1 {
+		t.Fatalf("got isis %v, want a single instance", igp.Isis)
+	}
+	inst := igp.Isis.Instance[0]
+	if inst.LevelCapability != "L2" || !reflect.DeepEqual(inst.Net, []string{"49.0002.1001.1210.0000.00"}) {
+		t.Errorf("got level %s and net %v", inst.LevelCapability, inst.Net)
+	}
+	itfces := make(map[string]bool)
+	for _, itfce := range inst.Interface {
+		itfces[itfce.InterfaceName] = itfce.Passive
+		if itfce.InterfaceName == "ethernet-1/49.0" {
+			if len(itfce.Level) != 1 || itfce.Level[0].Timers == nil || itfce.Level[0].Timers.HelloInterval != 3 || itfce.Level[0].Timers.HelloMultiplier != 3 {
+				t.Errorf("got levels %v, want level 2 with hello interval 3 and multiplier 3", itfce.Level)
+			}
+		}
+	}
+	if want := map[string]bool{"ethernet-1/49.0": false, "system0.0": true}; !reflect.DeepEqual(itfces, want) {
+		t.Errorf("got isis interfaces %v, want %v", itfces, want)
+	}
+	// the underlay sessions are replaced by the igp, the overlay is iBGP
+	if igp.Bgp.AutonomousSystem != 65002 {
+		t.Errorf("got AS %d, want the overlay AS", igp.Bgp.AutonomousSystem)
+	}
+	for _, g := range igp.Bgp.Group {
+		if g.GroupName == "underlay" {
+			t.Error("got an underlay peer group")
+		}
+	}
+	if len(igp.Bgp.Neighbor) != 1 || igp.Bgp.Neighbor[0].PeerAddress != "100.112.100.1" || igp.Bgp.Neighbor[0].PeerGroup != "overlay" {
+		t.Errorf("got neighbors %v, want the overlay session to leaf2", igp.Bgp.Neighbor)
+	}
+
+	igp = generateIgp(t, func(p *Protocols) {
+		p.Protocol = StringPtr(UnderlayOSPF)
+		p.HelloInterval = IntPtr(5)
+	})
+	if igp.Ospf == nil || len(igp.Ospf.Instance) != 2 {
+		t.Fatalf("got ospf %v, want an instance per address family", igp.Ospf)
+	}
+	for _, inst := range igp.Ospf.Instance {
+		if inst.AddressFamily == "ipv4-unicast" && inst.InstanceID != ospfv3IPv4InstanceID {
+			t.Errorf("got instance id %d for ipv4, want %d", inst.InstanceID, ospfv3IPv4InstanceID)
+		}
+		if len(inst.Area) != 1 || inst.Area[0].AreaID != "0.0.0.0" || len(inst.Area[0].Interface) != 2 {
+			t.Fatalf("got areas %v, want the backbone with two interfaces", inst.Area)
+		}
+		if itfce := inst.Area[0].Interface[0]; itfce.HelloInterval != 5 || itfce.DeadInterval != 20 {
+			t.Errorf("got hello %d and dead interval %d, want 5 and 20", itfce.HelloInterval, itfce.DeadInterval)
+		}
+	}
+}
+
+func TestIgpUnderlayWithEBGPOverlay(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Infrastructure.Protocols.Protocol = StringPtr(UnderlayISIS)
+	cfg.Infrastructure.Protocols.OverlayDesign = StringPtr(OverlayEBGP)
+	if _, err := Generate(context.Background(), cfg); err == nil {
+		t.Error("expected an error for the ebgp overlay over an igp underlay")
+	}
+}
+
+func TestIsisNet(t *testing.T) {
+	for routerID, want := range map[string]string{
+		"100.112.100.0": "49.0001.1001.1210.0000.00",
+		"10.0.0.1":      "49.0001.0100.0000.0001.00",
+		"2001:db8::1":   "",
+	} {
+		if got := isisNet("49.0001", routerID); got != want {
+			t.Errorf("isisNet(%s): got %s, want %s", routerID, got, want)
+		}
+	}
+}
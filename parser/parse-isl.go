@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// addressing modes of the inter switch links
+const (
+	// IslNumbered allocates a prefix per isl from the isl network
+	IslNumbered = "numbered"
+	// IslUnnumbered uses the ipv6 link-local addresses on the isls, ipv4 is
+	// unnumbered to system0 and the underlay bgp sessions are dynamic
+	// neighbors that carry the ipv4 routes with ipv6 next-hops
+	IslUnnumbered = "unnumbered"
+)
+
+// islAddressings holds the supported isl addressing modes
+var islAddressings = []string{IslNumbered, IslUnnumbered}
+
+// DynamicNeighbor accepts the bgp sessions on an interface from the link-local
+// address of the peer
+type DynamicNeighbor struct {
+	Interface    string // subinterface, e.g. ethernet-1/49.0
+	PeerGroup    string
+	AllowedPeers []uint32
+}
+
+// islAddressing returns the addressing mode of the isls, numbered by default
+func (p *Parser) islAddressing() string {
+	infra := p.Config.Infrastructure
+	if infra.IslAddressing == nil || *infra.IslAddressing == "" {
+		return IslNumbered
+	}
+	return *infra.IslAddressing
+}
+
+// unnumberedIsl returns true when the isls have no addresses of their own
+func (p *Parser) unnumberedIsl() bool {
+	return p.islAddressing() == IslUnnumbered
+}
+
+// checkIslAddressing returns an error for an unknown isl addressing mode
+func (p *Parser) checkIslAddressing() error {
+	switch p.islAddressing() {
+	case IslNumbered, IslUnnumbered:
+		return nil
+	}
+	return &ConfigError{Path: "infrastructure.isl_addressing", Msg: fmt.Sprintf("unknown isl_addressing %s, supported values: %s", p.islAddressing(), strings.Join(islAddressings, ", "))}
+}
+
+// dynamicNeighbor returns the dynamic neighbor of an unnumbered isl endpoint,
+// only the AS of the peer node is accepted
+func dynamicNeighbor(ep *Endpoint) *DynamicNeighbor {
+	return &DynamicNeighbor{
+		Interface:    *ep.RealName + "." + *ep.VlanID,
+		PeerGroup:    "underlay",
+		AllowedPeers: []uint32{*ep.PeerAS},
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUnnumberedIsl(t *testing.T) {
+	tree := struct {
+		Interface []struct {
+			Name         string `json:"name"`
+			Subinterface []struct {
+				IPv4 struct {
+					Address    []interface{} `json:"address"`
+					Unnumbered *struct {
+						Interface string `json:"interface"`
+					} `json:"unnumbered"`
+				} `json:"ipv4"`
+				IPv6 struct {
+					Address []interface{} `json:"address"`
+				} `json:"ipv6"`
+			} `json:"subinterface"`
+		} `json:"interface"`
+		NetworkInstance []struct {
+			Name      string `json:"name"`
+			Protocols struct {
+				Bgp struct {
+					Group []struct {
+						GroupName   string `json:"group-name"`
+						IPv4Unicast *struct {
+							AdvertiseIPv6NextHops bool `json:"advertise-ipv6-next-hops"`
+							ReceiveIPv6NextHops   bool `json:"receive-ipv6-next-hops"`
+						} `json:"ipv4-unicast"`
+					} `json:"group"`
+					DynamicNeighbors struct {
+						Interface []struct {
+							InterfaceName string        `json:"interface-name"`
+							PeerGroup     string        `json:"peer-group"`
+							AllowedPeerAS []json.Number `json:"allowed-peer-as"`
+						} `json:"interface"`
+					} `json:"dynamic-neighbors"`
+					Neighbor []struct {
+						PeerGroup string `json:"peer-group"`
+					} `json:"neighbor"`
+				} `json:"bgp"`
+			} `json:"protocols"`
+		} `json:"network-instance"`
+	}{}
+	res := generateNodeJSON(t, func(cfg *Config) {
+		cfg.Infrastructure.IslAddressing = StringPtr(IslUnnumbered)
+		// the isl network is not needed
+		delete(cfg.Infrastructure.Networks, "isl")
+	}, "leaf1", &tree)
+	for _, a := range res.Allocations {
+		if a.IPAM == "isl" {
+			t.Errorf("got isl allocation %v", a)
+		}
+	}
+	for _, itfce := range tree.Interface {
+		if itfce.Name != "ethernet-1/49" {
+			continue
+		}
+		si := itfce.Subinterface[0]
+		if si.IPv4.Unnumbered == nil || si.IPv4.Unnumbered.Interface != "system0.0" || len(si.IPv4.Address) != 0 || len(si.IPv6.Address) != 0 {
+			t.Errorf("got isl subinterface %+v, want unnumbered to system0.0 without addresses", si)
+		}
+	}
+	for _, ni := range tree.NetworkInstance {
+		if ni.Name != "default" {
+			continue
+		}
+		bgp := ni.Protocols.Bgp
+		for _, n := range bgp.Neighbor {
+			if n.PeerGroup == "underlay" {
+				t.Error("got an underlay neighbor with an address")
+			}
+		}
+		want := []json.Number{json.Number(fmt.Sprint(*res.Nodes["leaf2"].AS))}
+		if d := bgp.DynamicNeighbors.Interface; len(d) != 1 || d[0].InterfaceName != "ethernet-1/49.0" || d[0].PeerGroup != "underlay" || !reflect.DeepEqual(d[0].AllowedPeerAS, want) {
+			t.Errorf("got dynamic neighbors %+v, want ethernet-1/49.0 of the AS of leaf2", d)
+		}
+		for _, g := range bgp.Group {
+			if g.GroupName == "underlay" && (g.IPv4Unicast == nil || !g.IPv4Unicast.AdvertiseIPv6NextHops || !g.IPv4Unicast.ReceiveIPv6NextHops) {
+				t.Error("underlay group without ipv6 next-hops for ipv4")
+			}
+		}
+	}
+}
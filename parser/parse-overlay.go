@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// overlay designs of the evpn overlay
+const (
+	// OverlayFullMesh peers every node of the fabric with all other nodes of the fabric
+	OverlayFullMesh = "full-mesh"
+	// OverlayRouteReflector peers the nodes of the fabric with the route
+	// reflectors, the nodes with the role spine or rr label
+	OverlayRouteReflector = "route-reflector"
+	// OverlayEBGP carries the overlay over the eBGP underlay sessions
+	OverlayEBGP = "ebgp"
+)
+
+// overlayDesigns holds the supported overlay designs
+var overlayDesigns = []string{OverlayFullMesh, OverlayRouteReflector, OverlayEBGP}
+
+// overlayDesign returns the overlay design of the fabric, full mesh by default
+func (p *Parser) overlayDesign() string {
+	protocols := p.Config.Infrastructure.Protocols
+	if protocols.OverlayDesign == nil || *protocols.OverlayDesign == "" {
+		return OverlayFullMesh
+	}
+	return *protocols.OverlayDesign
+}
+
+// overlayProtocol returns the overlay protocol, empty when there is no overlay
+func (p *Parser) overlayProtocol() string {
+	protocols := p.Config.Infrastructure.Protocols
+	if protocols.OverlayProtocol == nil {
+		return ""
+	}
+	return *protocols.OverlayProtocol
+}
+
+// fabricNodes returns the network nodes with inter switch links, these nodes
+// take part in the underlay and the overlay
+func (p *Parser) fabricNodes() []string {
+	fabric := make([]string, 0)
+	for _, nodeName := range SortedKeys(p.Nodes) {
+		n := p.Nodes[nodeName]
+		if *n.Position != "network" {
+			continue
+		}
+		for _, ep := range n.Endpoints {
+			if *ep.Kind == "isl" {
+				fabric = append(fabric, nodeName)
+				break
+			}
+		}
+	}
+	return fabric
+}
+
+// isRouteReflector returns true for a node with the role spine or rr label
+func isRouteReflector(n *Node) bool {
+	if role, ok := nodeLabel(n, "role"); ok && role == "spine" {
+		return true
+	}
+	rr, ok := nodeLabel(n, "rr")
+	return ok && rr == "true"
+}
+
+// routeReflectors returns the route reflectors of the fabric in the route
+// reflector design, nil in the other designs
+func (p *Parser) routeReflectors(fabric []string) ([]string, error) {
+	design := p.overlayDesign()
+	switch design {
+	case OverlayFullMesh, OverlayEBGP:
+		return nil, nil
+	case OverlayRouteReflector:
+	default:
+		return nil, &ConfigError{Path: "infrastructure.protocols.overlay_design", Msg: fmt.Sprintf("unknown overlay_design %s, supported values: %s", design, strings.Join(overlayDesigns, ", "))}
+	}
+	rrs := make([]string, 0)
+	for _, nodeName := range fabric {
+		if isRouteReflector(p.Nodes[nodeName]) {
+			rrs = append(rrs, nodeName)
+		}
+	}
+	if len(rrs) == 0 {
+		return nil, &ConfigError{Path: "infrastructure.protocols.overlay_design", Msg: "route-reflector design without a node with the label role: spine or rr: true"}
+	}
+	return rrs, nil
+}
+
+// overlayNeighbors returns the iBGP overlay neighbors of a node of the fabric:
+// all other nodes in the full mesh design, the route reflectors for a client
+// and all other nodes for a route reflector; the eBGP design has no overlay
+// neighbors
+func (p *Parser) overlayNeighbors(n *Node, fabric, rrs []string) []*Neighbor {
+	if p.overlayDesign() == OverlayEBGP {
+		return nil
+	}
+	peers := fabric
+	group := "overlay"
+	if rrs != nil && !isRouteReflector(n) {
+		peers = rrs
+	}
+	neighbors := make([]*Neighbor, 0)
+	for _, peerName := range peers {
+		if peerName == *n.ShortName {
+			continue
+		}
+		peer := p.Nodes[peerName]
+		log.Debugf("Node Name: %s, Neighbor Node Name: %s", *n.ShortName, peerName)
+		peerGroup := group
+		// the route reflector peers with its clients in their own group
+		if rrs != nil && isRouteReflector(n) && !isRouteReflector(peer) {
+			peerGroup = "overlay-rr-clients"
+		}
+		neighbors = append(neighbors, &Neighbor{
+			PeerIP:           p.loopbackAddress(peer),
+			PeerAS:           *p.Config.Infrastructure.Protocols.OverlayAs,
+			PeerGroup:        peerGroup,
+			LocalAS:          *p.Config.Infrastructure.Protocols.OverlayAs,
+			TransportAddress: p.loopbackAddress(n),
+		})
+	}
+	return neighbors
+}
+
+// overlayPeerGroups adds the overlay to the peer groups of a node of the fabric:
+// an overlay group, on a route reflector also the group of its clients with
+// the cluster id of the route reflectors, or the overlay protocol in the
+// underlay group in the eBGP design
+func (p *Parser) overlayPeerGroups(nodeName string, peerGroups []*PeerGroup, rrs []string) []*PeerGroup {
+	protocol := p.overlayProtocol()
+	if protocol == "" {
+		return peerGroups
+	}
+	if p.overlayDesign() == OverlayEBGP {
+		for _, pg := range peerGroups {
+			if pg.Name == "underlay" {
+				pg.Protocols = append(pg.Protocols, protocol)
+				pg.NextHopUnchanged = true
+			}
+		}
+		return peerGroups
+	}
+	peerGroups = append(peerGroups, &PeerGroup{
+		Name:      "overlay",
+		Protocols: []string{protocol},
+	})
+	if rrs != nil && isRouteReflector(p.Nodes[nodeName]) {
+		// the route reflectors form a single cluster, with the router id of
+		// the first route reflector as cluster id
+		peerGroups = append(peerGroups, &PeerGroup{
+			Name:      "overlay-rr-clients",
+			Protocols: []string{protocol},
+			ClusterID: routerID(p.Nodes[rrs[0]]),
+		})
+	}
+	return peerGroups
+}
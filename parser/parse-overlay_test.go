@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// overlayBgp holds the bgp groups and neighbors of the default network instance
+type overlayBgp struct {
+	Group []struct {
+		GroupName      string `json:"group-name"`
+		NextHopSelf    bool   `json:"next-hop-self"`
+		RouteReflector *struct {
+			Client    bool   `json:"client"`
+			ClusterID string `json:"cluster-id"`
+		} `json:"route-reflector"`
+		Evpn *struct{} `json:"evpn"`
+	} `json:"group"`
+	Neighbor []struct {
+		PeerAddress string `json:"peer-address"`
+		PeerGroup   string `json:"peer-group"`
+	} `json:"neighbor"`
+}
+
+// overlayTree holds the default network instance in the SR Linux json
+type overlayTree struct {
+	NetworkInstance []struct {
+		Name      string `json:"name"`
+		Protocols struct {
+			Bgp *overlayBgp `json:"bgp"`
+		} `json:"protocols"`
+	} `json:"network-instance"`
+}
+
+// generateOverlay generates the fabric with a third leaf that is only
+// connected to leaf2 and returns the bgp of the default network instance per leaf
+func generateOverlay(t *testing.T, design string, labels map[string]string) map[string]*overlayBgp {
+	t.Helper()
+	trees := map[string]*overlayTree{"leaf1": {}, "leaf2": {}, "leaf3": {}}
+	res := generateNodeJSON(t, func(cfg *Config) {
+		cfg.Infrastructure.Protocols.OverlayDesign = StringPtr(design)
+		cfg.Topology.Nodes["leaf3"] = &NodeConfig{
+			Kind:     StringPtr("srl"),
+			MgmtIPv4: StringPtr("172.20.20.5"),
+			Labels:   map[string]*string{"target": StringPtr("leaf-grp1")},
+		}
+		cfg.Topology.Links = append(cfg.Topology.Links, &LinkConfig{
+			Endpoints: []*string{StringPtr("leaf2:e1-48"), StringPtr("leaf3:e1-48")},
+			Labels:    map[string]*string{"kind": StringPtr("isl")},
+		})
+		for nodeName, v := range labels {
+			k := "rr"
+			if v == "spine" {
+				k = "role"
+			}
+			cfg.Topology.Nodes[nodeName].Labels[k] = StringPtr(v)
+		}
+	}, "leaf1", trees["leaf1"])
+	decodeNodeJSON(t, res, "leaf2", trees["leaf2"])
+	decodeNodeJSON(t, res, "leaf3", trees["leaf3"])
+	bgps := make(map[string]*overlayBgp)
+	for nodeName, tree := range trees {
+		for _, ni := range tree.NetworkInstance {
+			if ni.Name == "default" {
+				bgps[nodeName] = ni.Protocols.Bgp
+			}
+		}
+	}
+	return bgps
+}
+
+// overlayPeers returns the neighbors per peer group
+func overlayPeers(bgp *overlayBgp) map[string][]string {
+	peers := make(map[string][]string)
+	for _, n := range bgp.Neighbor {
+		peers[n.PeerGroup] = append(peers[n.PeerGroup], n.PeerAddress)
+	}
+	return peers
+}
+
+func TestOverlayDesigns(t *testing.T) {
+	// the loopbacks of leaf1, leaf2 and leaf3
+	lo := []string{"100.112.100.0", "100.112.100.1", "100.112.100.2"}
+
+	// leaf1 and leaf3 have no isl, they peer in the full mesh
+	bgps := generateOverlay(t, OverlayFullMesh, nil)
+	for nodeName, want := range map[string][]string{
+		"leaf1": {lo[1], lo[2]},
+		"leaf2": {lo[0], lo[2]},
+		"leaf3": {lo[0], lo[1]},
+	} {
+		if got := overlayPeers(bgps[nodeName])["overlay"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("full-mesh %s: got overlay neighbors %v, want %v", nodeName, got, want)
+		}
+	}
+
+	bgps = generateOverlay(t, OverlayRouteReflector, map[string]string{"leaf2": "spine"})
+	if got := overlayPeers(bgps["leaf2"]); !reflect.DeepEqual(got["overlay-rr-clients"], []string{lo[0], lo[2]}) || len(got["overlay"]) != 0 {
+		t.Errorf("route-reflector leaf2: got neighbors %v", got)
+	}
+	for _, nodeName := range []string{"leaf1", "leaf3"} {
+		if got := overlayPeers(bgps[nodeName])["overlay"]; !reflect.DeepEqual(got, []string{lo[1]}) {
+			t.Errorf("route-reflector %s: got overlay neighbors %v, want the route reflector", nodeName, got)
+		}
+	}
+	found := false
+	for _, g := range bgps["leaf2"].Group {
+		if g.GroupName == "overlay-rr-clients" {
+			found = g.RouteReflector != nil && g.RouteReflector.Client && g.RouteReflector.ClusterID == lo[1]
+		}
+	}
+	if !found {
+		t.Error("route-reflector leaf2 has no client group with cluster-id")
+	}
+
+	// two route reflectors peer with each other in the overlay group
+	bgps = generateOverlay(t, OverlayRouteReflector, map[string]string{"leaf1": "true", "leaf2": "true"})
+	if got := overlayPeers(bgps["leaf1"]); !reflect.DeepEqual(got["overlay"], []string{lo[1]}) || !reflect.DeepEqual(got["overlay-rr-clients"], []string{lo[2]}) {
+		t.Errorf("route-reflector leaf1: got neighbors %v", got)
+	}
+	if got := overlayPeers(bgps["leaf3"])["overlay"]; !reflect.DeepEqual(got, []string{lo[0], lo[1]}) {
+		t.Errorf("route-reflector leaf3: got overlay neighbors %v, want both route reflectors", got)
+	}
+
+	bgps = generateOverlay(t, OverlayEBGP, nil)
+	for nodeName, bgp := range bgps {
+		if got := overlayPeers(bgp); len(got["overlay"]) != 0 {
+			t.Errorf("ebgp %s: got overlay neighbors %v", nodeName, got["overlay"])
+		}
+		for _, g := range bgp.Group {
+			if g.GroupName == "underlay" && (g.Evpn == nil || g.NextHopSelf) {
+				t.Errorf("ebgp %s: underlay group without evpn or with next-hop-self", nodeName)
+			}
+			if g.GroupName == "overlay" {
+				t.Errorf("ebgp %s: has an overlay group", nodeName)
+			}
+		}
+	}
+}
+
+func TestOverlayDesignRouteReflectorWithoutRR(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Infrastructure.Protocols.OverlayDesign = StringPtr(OverlayRouteReflector)
+	if _, err := Generate(context.Background(), cfg); err == nil {
+		t.Error("expected an error for the route-reflector design without route reflector")
+	}
+}
@@ -0,0 +1,340 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// k8ssrlRoutingPolicy holds the prefix-sets, community-sets and policies of a
+// routing policy resource
+type k8ssrlRoutingPolicy struct {
+	PrefixSets    []*k8ssrlPrefixSet
+	CommunitySets []*k8ssrlCommunitySet
+	Policies      []*k8ssrlPolicy
+}
+
+type k8ssrlPrefixSet struct {
+	Name     string
+	Prefixes []*k8ssrlPrefix
+}
+
+type k8ssrlPrefix struct {
+	IPPrefix        string
+	MaskLengthRange string // e.g. 32..32
+}
+
+type k8ssrlCommunitySet struct {
+	Name   string
+	Member string // e.g. 505:1
+}
+
+type k8ssrlPolicy struct {
+	Name string
+	// DefaultAccept accepts the routes that match none of the statements
+	DefaultAccept bool
+	Statements    []*k8ssrlPolicyStatement
+}
+
+type k8ssrlPolicyStatement struct {
+	SequenceID   int
+	PrefixSet    string
+	CommunitySet string
+	// AddCommunitySet tags the accepted routes with the members of the set
+	AddCommunitySet string
+	Reject          bool
+}
+
+// communities of the classes of cnf routes, the community of a class is
+// <vlan id of the ip-vrf>:<class>
+const (
+	communityUePool     = 1
+	communityLoopbacks  = 2
+	communitySignalling = 3
+)
+
+// cnfRoutes holds the cnf prefixes of a workload that are advertised to the
+// gateways, per class
+type cnfRoutes struct {
+	UePools    []string
+	Loopbacks  []string
+	Signalling []string
+}
+
+// summarizeLoopbacks returns true when the loopback prefix-sets hold the
+// supernets of the loopback cidrs
+func (p *Parser) summarizeLoopbacks() bool {
+	protocols := p.Config.Infrastructure.Protocols
+	return protocols.SummarizeLoopbacks != nil && *protocols.SummarizeLoopbacks
+}
+
+// summarizeCidrs returns the smallest set of prefixes that covers the cidrs:
+// the cidrs that are part of another cidr are removed and adjacent cidrs of
+// the same length are merged into their supernet
+func summarizeCidrs(cidrs []string) []string {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		if _, n, err := net.ParseCIDR(c); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		sort.Slice(nets, func(i, j int) bool {
+			if c := bytes.Compare(nets[i].IP.To16(), nets[j].IP.To16()); c != 0 {
+				return c < 0
+			}
+			li, _ := nets[i].Mask.Size()
+			lj, _ := nets[j].Mask.Size()
+			return li < lj
+		})
+		summary := make([]*net.IPNet, 0, len(nets))
+		for _, n := range nets {
+			if len(summary) == 0 {
+				summary = append(summary, n)
+				continue
+			}
+			last := summary[len(summary)-1]
+			lastLen, bits := last.Mask.Size()
+			nLen, _ := n.Mask.Size()
+			switch {
+			case last.Contains(n.IP) && lastLen <= nLen:
+				// part of the previous prefix
+			case lastLen == nLen && nLen > 0 && supernet(last, bits).String() == supernet(n, bits).String():
+				summary[len(summary)-1] = supernet(last, bits)
+				changed = true
+			default:
+				summary = append(summary, n)
+			}
+		}
+		nets = summary
+	}
+	prefixes := make([]string, 0, len(nets))
+	for _, n := range nets {
+		prefixes = append(prefixes, n.String())
+	}
+	return prefixes
+}
+
+// supernet returns the prefix that is one bit shorter
+func supernet(n *net.IPNet, bits int) *net.IPNet {
+	l, _ := n.Mask.Size()
+	mask := net.CIDRMask(l-1, bits)
+	return &net.IPNet{IP: n.IP.Mask(mask), Mask: mask}
+}
+
+// cidrStrings returns the cidrs, the empty ones are skipped
+func cidrStrings(cidrs []*string) []string {
+	s := make([]string, 0, len(cidrs))
+	for _, c := range cidrs {
+		if c != nil && *c != "" {
+			s = append(s, *c)
+		}
+	}
+	return s
+}
+
+// hostPrefixSet returns the prefix-set that matches the host routes of the
+// cidrs, e.g. the loopbacks of the nodes
+func hostPrefixSet(name string, cidrs []string, hostLength int) *k8ssrlPrefixSet {
+	set := &k8ssrlPrefixSet{Name: name, Prefixes: make([]*k8ssrlPrefix, 0, len(cidrs))}
+	for _, c := range cidrs {
+		set.Prefixes = append(set.Prefixes, &k8ssrlPrefix{
+			IPPrefix:        c,
+			MaskLengthRange: fmt.Sprintf("%d..%d", hostLength, hostLength),
+		})
+	}
+	return set
+}
+
+// longerPrefixSet returns the prefix-set that matches the cidrs and their
+// more specific routes, e.g. the routes of a ue pool
+func longerPrefixSet(name string, cidrs []string, hostLength int) *k8ssrlPrefixSet {
+	set := &k8ssrlPrefixSet{Name: name, Prefixes: make([]*k8ssrlPrefix, 0, len(cidrs))}
+	for _, c := range cidrs {
+		// the prefix-set holds the network address, e.g. of 10.100.11.2/24
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			continue
+		}
+		l, _ := n.Mask.Size()
+		set.Prefixes = append(set.Prefixes, &k8ssrlPrefix{
+			IPPrefix:        n.String(),
+			MaskLengthRange: fmt.Sprintf("%d..%d", l, hostLength),
+		})
+	}
+	return set
+}
+
+// splitFamilies splits the cidrs in ipv4 and ipv6 cidrs
+func splitFamilies(cidrs []string) ([]string, []string) {
+	var ipv4Cidrs, ipv6Cidrs []string
+	for _, c := range cidrs {
+		ip, _, err := net.ParseCIDR(c)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4Cidrs = append(ipv4Cidrs, c)
+		} else {
+			ipv6Cidrs = append(ipv6Cidrs, c)
+		}
+	}
+	return ipv4Cidrs, ipv6Cidrs
+}
+
+// underlayRoutingPolicy returns the export policy of the underlay groups, it
+// advertises the loopbacks of the nodes from all loopback cidrs of the
+// addressing schema, summarized to their supernets when configured
+func (p *Parser) underlayRoutingPolicy() *k8ssrlRoutingPolicy {
+	ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.infraSchema(), p.Config.Infrastructure.Networks["loopback"])
+	v4, v6 := cidrStrings(ipv4Cidrs), cidrStrings(ipv6Cidrs)
+	if p.summarizeLoopbacks() {
+		v4, v6 = summarizeCidrs(v4), summarizeCidrs(v6)
+	}
+	rp := &k8ssrlRoutingPolicy{}
+	policy := &k8ssrlPolicy{Name: "export-underlay-local"}
+	if len(v4) > 0 {
+		rp.PrefixSets = append(rp.PrefixSets, hostPrefixSet("system-v4", v4, 32))
+		policy.Statements = append(policy.Statements, &k8ssrlPolicyStatement{SequenceID: 10, PrefixSet: "system-v4"})
+	}
+	if len(v6) > 0 {
+		rp.PrefixSets = append(rp.PrefixSets, hostPrefixSet("system-v6", v6, 128))
+		policy.Statements = append(policy.Statements, &k8ssrlPolicyStatement{SequenceID: 20, PrefixSet: "system-v6"})
+	}
+	rp.Policies = []*k8ssrlPolicy{policy}
+	return rp
+}
+
+// uePoolWorkloads returns the workloads of the internet multus networks, which
+// carry the ue pools, and the ue pools of the applications
+func (p *Parser) uePoolWorkloads() (map[string]bool, []string) {
+	uePools := make([]string, 0)
+	internetWls := make(map[string]bool)
+	for _, appName := range SortedKeys(p.Config.Application) {
+		pacoInfo := p.Config.Application[appName]
+		if pacoInfo.Deployment != nil && pacoInfo.Deployment.UePoolCidr != nil {
+			uePools = append(uePools, *pacoInfo.Deployment.UePoolCidr)
+		}
+		if pacoInfo.Global != nil {
+			if multus, ok := pacoInfo.Global.Multus["3GPP_Internet"]; ok && multus.WorkloadName != nil {
+				internetWls[*multus.WorkloadName] = true
+			}
+		}
+	}
+	return internetWls, uePools
+}
+
+// cnfRoutes returns the cnf prefixes of a workload: the ue pools on the
+// internet workload, the loopback cidrs of the workload and the signalling
+// reference points on the workloads of the other 3GPP multus networks
+func (p *Parser) cnfRoutes(wlName string) *cnfRoutes {
+	routes := &cnfRoutes{}
+	internetWls, uePools := p.uePoolWorkloads()
+	if internetWls[wlName] {
+		routes.UePools = uePools
+	}
+	for _, cgName := range SortedKeys(p.Config.Workloads[wlName]) {
+		wlInfo := p.Config.Workloads[wlName][cgName]
+		if wlInfo == nil {
+			continue
+		}
+		for _, lbName := range SortedKeys(wlInfo.Loopbacks) {
+			if netwInfo := wlInfo.Loopbacks[lbName]; netwInfo != nil {
+				schema := p.networkSchema(netwInfo)
+				ipv4Cidrs, ipv6Cidrs := schemaCidrs(schema, netwInfo)
+				routes.Loopbacks = append(routes.Loopbacks, cidrStrings(ipv4Cidrs)...)
+				routes.Loopbacks = append(routes.Loopbacks, cidrStrings(ipv6Cidrs)...)
+			}
+		}
+	}
+	for _, appName := range SortedKeys(p.Config.Application) {
+		pacoInfo := p.Config.Application[appName]
+		if pacoInfo.Global == nil || pacoInfo.Deployment == nil || pacoInfo.Deployment.SigRefPoints == nil {
+			continue
+		}
+		for _, multusName := range SortedKeys(pacoInfo.Global.Multus) {
+			multus := pacoInfo.Global.Multus[multusName]
+			if !strings.HasPrefix(multusName, "3GPP_") || multusName == "3GPP_Internet" {
+				continue
+			}
+			if multus.WorkloadName != nil && *multus.WorkloadName == wlName {
+				routes.Signalling = append(routes.Signalling, *pacoInfo.Deployment.SigRefPoints)
+			}
+		}
+	}
+	return routes
+}
+
+// workloadRoutingPolicy returns the policies of the gateway group of a routed
+// workload: the export policy tags the cnf routes with the community of their
+// class and the import policy rejects these routes when they return from the
+// gateways; nil when the workload has no cnf routes
+func (p *Parser) workloadRoutingPolicy(wlName string, vlanID int, schema string) *k8ssrlRoutingPolicy {
+	routes := p.cnfRoutes(wlName)
+	rp := &k8ssrlRoutingPolicy{}
+	export := &k8ssrlPolicy{Name: "export-" + wlName + "-cnf", DefaultAccept: true}
+	imp := &k8ssrlPolicy{Name: "import-" + wlName + "-cnf", DefaultAccept: true}
+	for i, class := range []struct {
+		name      string
+		cidrs     []string
+		community int
+		host      bool
+	}{
+		{"uepool", routes.UePools, communityUePool, false},
+		{"loopbacks", routes.Loopbacks, communityLoopbacks, true},
+		{"signalling", routes.Signalling, communitySignalling, false},
+	} {
+		ipv4Cidrs, ipv6Cidrs := splitFamilies(class.cidrs)
+		if schema == AddressingIPv6Only {
+			ipv4Cidrs = nil
+		}
+		if schema == AddressingIPv4Only {
+			ipv6Cidrs = nil
+		}
+		if len(ipv4Cidrs) == 0 && len(ipv6Cidrs) == 0 {
+			continue
+		}
+		setName := wlName + "-" + class.name
+		rp.CommunitySets = append(rp.CommunitySets, &k8ssrlCommunitySet{
+			Name:   setName,
+			Member: fmt.Sprintf("%d:%d", vlanID, class.community),
+		})
+		for j, family := range []struct {
+			suffix     string
+			cidrs      []string
+			hostLength int
+		}{
+			{"v4", ipv4Cidrs, 32},
+			{"v6", ipv6Cidrs, 128},
+		} {
+			if len(family.cidrs) == 0 {
+				continue
+			}
+			var set *k8ssrlPrefixSet
+			if class.host {
+				set = hostPrefixSet(setName+"-"+family.suffix, family.cidrs, family.hostLength)
+			} else {
+				set = longerPrefixSet(setName+"-"+family.suffix, family.cidrs, family.hostLength)
+			}
+			rp.PrefixSets = append(rp.PrefixSets, set)
+			export.Statements = append(export.Statements, &k8ssrlPolicyStatement{
+				SequenceID:      (i+1)*10 + j,
+				PrefixSet:       set.Name,
+				AddCommunitySet: setName,
+			})
+		}
+		imp.Statements = append(imp.Statements, &k8ssrlPolicyStatement{
+			SequenceID:   (i + 1) * 10,
+			CommunitySet: setName,
+			Reject:       true,
+		})
+	}
+	if len(export.Statements) == 0 {
+		return nil
+	}
+	rp.Policies = []*k8ssrlPolicy{export, imp}
+	return rp
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeCidrs(t *testing.T) {
+	for _, tc := range []struct {
+		cidrs, want []string
+	}{
+		{[]string{"100.112.100.0/24"}, []string{"100.112.100.0/24"}},
+		// adjacent cidrs are merged, also over more than one level
+		{[]string{"10.0.1.0/24", "10.0.0.0/24", "10.0.2.0/23"}, []string{"10.0.0.0/22"}},
+		// a cidr inside another one is dropped
+		{[]string{"10.0.0.0/16", "10.0.4.0/24", "10.2.0.0/16"}, []string{"10.0.0.0/16", "10.2.0.0/16"}},
+		// 10.0.1.0/24 and 10.0.2.0/24 are adjacent but have no common /23
+		{[]string{"10.0.1.0/24", "10.0.2.0/24"}, []string{"10.0.1.0/24", "10.0.2.0/24"}},
+		{[]string{"3100:100::/48", "3100:100:1::/48"}, []string{"3100:100::/47"}},
+	} {
+		if got := summarizeCidrs(tc.cidrs); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("summarizeCidrs(%v): got %v, want %v", tc.cidrs, got, tc.want)
+		}
+	}
+}
+
+// routingPolicyTree holds the routing policy of a node in the SR Linux json
+type routingPolicyTree struct {
+	RoutingPolicy struct {
+		PrefixSet []struct {
+			Name   string `json:"name"`
+			Prefix []struct {
+				IPPrefix        string `json:"ip-prefix"`
+				MaskLengthRange string `json:"mask-length-range"`
+			} `json:"prefix"`
+		} `json:"prefix-set"`
+		CommunitySet []struct {
+			Name   string   `json:"name"`
+			Member []string `json:"member"`
+		} `json:"community-set"`
+		Policy []struct {
+			Name string `json:"name"`
+		} `json:"policy"`
+	} `json:"routing-policy"`
+	NetworkInstance []struct {
+		Name      string `json:"name"`
+		Protocols struct {
+			Bgp struct {
+				Group []struct {
+					GroupName    string `json:"group-name"`
+					ExportPolicy string `json:"export-policy"`
+					ImportPolicy string `json:"import-policy"`
+				} `json:"group"`
+			} `json:"bgp"`
+		} `json:"protocols"`
+	} `json:"network-instance"`
+}
+
+func TestRoutingPolicies(t *testing.T) {
+	tree := &routingPolicyTree{}
+	generateNodeJSON(t, func(cfg *Config) {
+		loopback := cfg.Infrastructure.Networks["loopback"]
+		loopback.Ipv4Cidr = append(loopback.Ipv4Cidr, StringPtr("100.112.101.0/24"), StringPtr("100.112.120.0/24"))
+		loopback.Ipv6Cidr = append(loopback.Ipv6Cidr, StringPtr("3100:100:1::/48"))
+		cfg.Infrastructure.Protocols.SummarizeLoopbacks = BoolPtr(true)
+	}, "leaf1", tree)
+	prefixSets := make(map[string][]string)
+	for _, set := range tree.RoutingPolicy.PrefixSet {
+		for _, pfx := range set.Prefix {
+			prefixSets[set.Name] = append(prefixSets[set.Name], pfx.IPPrefix+" "+pfx.MaskLengthRange)
+		}
+	}
+	for name, want := range map[string][]string{
+		"system-v4":                    {"100.112.100.0/23 32..32", "100.112.120.0/24 32..32"},
+		"system-v6":                    {"3100:100::/47 128..128"},
+		"multus-internet-uepool-v4":    {"10.0.128.0/17 17..32"},
+		"multus-internet-loopbacks-v6": {"2010:254:55::/64 128..128"},
+		// the network address of the signalling reference points 10.100.11.2/24
+		"multus-sba-signalling-v4": {"10.100.11.0/24 24..32"},
+	} {
+		if !reflect.DeepEqual(prefixSets[name], want) {
+			t.Errorf("prefix-set %s: got %v, want %v", name, prefixSets[name], want)
+		}
+	}
+	if _, ok := prefixSets["multus-internet-signalling-v4"]; ok {
+		t.Error("got signalling prefixes on the internet workload")
+	}
+
+	communities := make(map[string][]string)
+	for _, set := range tree.RoutingPolicy.CommunitySet {
+		communities[set.Name] = set.Member
+	}
+	for name, want := range map[string]string{"multus-internet-uepool": "505:1", "multus-internet-loopbacks": "505:2", "multus-sba-signalling": "405:3"} {
+		if !reflect.DeepEqual(communities[name], []string{want}) {
+			t.Errorf("community-set %s: got %v, want [%s]", name, communities[name], want)
+		}
+	}
+
+	for _, ni := range tree.NetworkInstance {
+		for _, g := range ni.Protocols.Bgp.Group {
+			switch {
+			case ni.Name == "default" && g.GroupName == "underlay":
+				if g.ExportPolicy != "export-underlay-local" {
+					t.Errorf("got underlay export policy %q", g.ExportPolicy)
+				}
+			case ni.Name == "multus-ipvrf-itfce-505" && g.GroupName == "dcgw":
+				if g.ExportPolicy != "export-multus-internet-cnf" || g.ImportPolicy != "import-multus-internet-cnf" {
+					t.Errorf("got dcgw policies %q and %q, want the cnf policies of multus-internet", g.ExportPolicy, g.ImportPolicy)
+				}
+			}
+		}
+	}
+}
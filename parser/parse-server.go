@@ -6,14 +6,19 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (p *Parser) ParseServerData() {
+func (p *Parser) ParseServerData() error {
 	log.Infof("Rendering Server Data into k8s .yaml files...")
 	// Create directory where the Server manifest files will be stored
 	dirName := filepath.Join(*p.BaseServerDir)
-	p.CreateDirectory(dirName, 0777)
+	if err := p.CreateDirectory(dirName, 0777); err != nil {
+		return err
+	}
 
 	// Parse the application templates
-	t := ParseTemplates("./templates/server")
+	t, err := ParseTemplates("server", p.templateFS()...)
+	if err != nil {
+		return err
+	}
 
 	// identify the server nodes and its respective leaf/tor switches
 	p.InitializeSwitchToServerInformation()
@@ -22,22 +27,25 @@ func (p *Parser) ParseServerData() {
 	p.showSwitchToServerInformation()
 
 	// render the config map for SRIOV
-	p.RenderSriovConfigMap(t, &dirName, p.ClientServer2NetworkLinks)
-
+	return p.RenderSriovConfigMap(t, &dirName, p.ClientServer2NetworkLinks)
 }
 
 func (p *Parser) showSwitchToServerInformation() {
-	for linkName, linkInfo := range p.ClientServer2NetworkLinks["sriov"] {
-		for numaID, numaInfo := range linkInfo {
-			for switchName, switchInfo := range numaInfo {
+	for _, linkName := range SortedKeys(p.ClientServer2NetworkLinks["sriov"]) {
+		linkInfo := p.ClientServer2NetworkLinks["sriov"][linkName]
+		for _, numaID := range SortedIntKeys(linkInfo) {
+			numaInfo := linkInfo[numaID]
+			for _, switchName := range SortedKeys(numaInfo) {
+				switchInfo := numaInfo[switchName]
 				for _, pfName := range switchInfo {
 					log.Infof("SRIOV linkName, NumaID, switchName, pfName: %s, %d, %s, %s", linkName, numaID, switchName, *pfName)
 				}
 			}
 		}
 	}
-	for linkName, linkInfo := range p.ClientServer2NetworkLinks["sriov"] {
-		for numaID := range linkInfo {
+	for _, linkName := range SortedKeys(p.ClientServer2NetworkLinks["sriov"]) {
+		linkInfo := p.ClientServer2NetworkLinks["sriov"][linkName]
+		for _, numaID := range SortedIntKeys(linkInfo) {
 			log.Infof("IPVLAN linkName, NumaID: %s, %d", linkName, numaID)
 		}
 	}
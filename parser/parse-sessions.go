@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// authentication types of the bgp sessions
+const (
+	// BgpAuthMD5 signs the bgp sessions with the TCP MD5 signature option
+	BgpAuthMD5 = "md5"
+	// BgpAuthTCPAO signs the bgp sessions with the TCP authentication option
+	BgpAuthTCPAO = "tcp-ao"
+)
+
+// bgpAuthTypes holds the supported authentication types
+var bgpAuthTypes = []string{BgpAuthMD5, BgpAuthTCPAO}
+
+// the defaults of bfd give a failure detection of 300ms
+const (
+	defaultBfdInterval   = 100
+	defaultBfdMultiplier = 3
+)
+
+// infraKeychain is the keychain of the underlay and overlay groups
+const infraKeychain = "paco-infra"
+
+// SecretResolver returns the value of a secret reference of the config, e.g.
+// the key of the bgp authentication
+type SecretResolver func(ref string) (string, error)
+
+// WithSecretResolver resolves the secret references of the config with the
+// resolver instead of the environment or the file system
+func WithSecretResolver(r SecretResolver) ParserOption {
+	return func(p *Parser) error {
+		p.secrets = r
+		return nil
+	}
+}
+
+// WithRenderSecrets writes the resolved keys of the bgp authentication in the
+// output instead of a placeholder, the output then holds the keys in plaintext
+func WithRenderSecrets(r bool) ParserOption {
+	return func(p *Parser) error {
+		p.renderSecrets = r
+		return nil
+	}
+}
+
+// secretPlaceholder returns the placeholder that is rendered instead of the key
+// of a keychain: ${NAME} for an env:NAME reference, ${PACO_<KEYCHAIN>_KEY} for
+// the other references; e.g. envsubst fills it in when the output is applied
+func secretPlaceholder(ref, keychain string) string {
+	split := strings.SplitN(ref, ":", 2)
+	if len(split) == 2 && split[0] == "env" && envVarName.MatchString(split[1]) {
+		return "${" + split[1] + "}"
+	}
+	return "${" + strings.ToUpper(nonEnvVarChars.ReplaceAllString(keychain, "_")) + "_KEY}"
+}
+
+var (
+	envVarName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	nonEnvVarChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// resolveSecret resolves a secret reference env:NAME to the environment
+// variable or file:PATH to the content of the file
+func resolveSecret(ref string) (string, error) {
+	split := strings.SplitN(ref, ":", 2)
+	if len(split) != 2 || split[1] == "" {
+		return "", fmt.Errorf("secret reference %s is not env:NAME or file:PATH", ref)
+	}
+	kind, name := split[0], split[1]
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s of secret reference %s is not set", name, ref)
+		}
+		return v, nil
+	case "file":
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("secret reference %s: %w", ref, err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return "", fmt.Errorf("secret reference %s is not env:NAME or file:PATH", ref)
+}
+
+// bgpSession holds the resolved settings of the sessions of a bgp group
+type bgpSession struct {
+	// BfdInterval is 0 without bfd
+	BfdInterval   int // milliseconds
+	BfdMultiplier int
+	// Keychain is empty without authentication
+	Keychain string
+	AuthType string
+	// AuthKey is the placeholder of the key unless the secrets are rendered
+	AuthKey           string
+	HoldTime          int // 0 leaves the default of the node
+	KeepaliveInterval int
+	MaxPrefix         int
+}
+
+// BfdMicroseconds returns the bfd interval in microseconds
+func (s *bgpSession) BfdMicroseconds() int {
+	return s.BfdInterval * 1000
+}
+
+// KeychainType returns the SR Linux keychain type of the authentication
+func (s *bgpSession) KeychainType() string {
+	if s.AuthType == BgpAuthTCPAO {
+		return "tcp-ao"
+	}
+	return "tcp-md5"
+}
+
+// KeyAlgorithm returns the algorithm of the key of the authentication
+func (s *bgpSession) KeyAlgorithm() string {
+	if s.AuthType == BgpAuthTCPAO {
+		return "aes-128-cmac-96"
+	}
+	return "md5"
+}
+
+// k8ssrlBfd holds the bfd of the subinterfaces of the bgp sessions
+type k8ssrlBfd struct {
+	Session       *bgpSession
+	SubInterfaces []string // e.g. ethernet-1/49.0
+}
+
+// mergeBgpSessions returns the sessions with the fields of the override that
+// are set replacing the base
+func mergeBgpSessions(base, override *BgpSessions) *BgpSessions {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		return override
+	}
+	merged := *base
+	if override.Bfd != nil {
+		merged.Bfd = override.Bfd
+	}
+	if override.Auth != nil {
+		merged.Auth = override.Auth
+	}
+	if override.HoldTime != nil {
+		merged.HoldTime = override.HoldTime
+	}
+	if override.KeepaliveInterval != nil {
+		merged.KeepaliveInterval = override.KeepaliveInterval
+	}
+	if override.MaxPrefix != nil {
+		merged.MaxPrefix = override.MaxPrefix
+	}
+	return &merged
+}
+
+// infraSessions returns the session settings of the underlay and overlay groups
+func (p *Parser) infraSessions() *BgpSessions {
+	return p.Config.Infrastructure.Protocols.BgpSessions
+}
+
+// workloadSessions returns the session settings of the bgp sessions of a
+// routed itfce, the settings of the itfce override the ones of the protocols
+func (p *Parser) workloadSessions(netwInfo *NetworkInfo) *BgpSessions {
+	return mergeBgpSessions(p.infraSessions(), netwInfo.BgpSessions)
+}
+
+// workloadKeychain returns the keychain of the bgp sessions of a routed itfce,
+// paco-<workload>-<vlan>, or the keychain of the infrastructure when the itfce
+// has no authentication of its own; the sessions of a workload towards the
+// gateways are grouped per vlan, so is the keychain, and the vlan keeps it
+// apart from paco-infra
+func workloadKeychain(wlName string, netwInfo *NetworkInfo) string {
+	if netwInfo.BgpSessions == nil || netwInfo.BgpSessions.Auth == nil {
+		return infraKeychain
+	}
+	vlanID := 0
+	if netwInfo.VlanID != nil {
+		vlanID = *netwInfo.VlanID
+	}
+	return "paco-" + wlName + "-" + strconv.Itoa(vlanID)
+}
+
+// bgpSession resolves the session settings of a bgp group with the keychain of
+// the authentication, nil without settings
+func (p *Parser) bgpSession(path string, sessions *BgpSessions, keychain string) (*bgpSession, error) {
+	if sessions == nil {
+		return nil, nil
+	}
+	s := &bgpSession{}
+	if sessions.Bfd != nil {
+		s.BfdInterval, s.BfdMultiplier = defaultBfdInterval, defaultBfdMultiplier
+		if sessions.Bfd.Interval != nil {
+			s.BfdInterval = *sessions.Bfd.Interval
+		}
+		if sessions.Bfd.Multiplier != nil {
+			s.BfdMultiplier = *sessions.Bfd.Multiplier
+		}
+	}
+	if auth := sessions.Auth; auth != nil {
+		s.AuthType = BgpAuthMD5
+		if auth.Type != nil {
+			s.AuthType = *auth.Type
+		}
+		if auth.Secret == nil {
+			return nil, &ConfigError{Path: path + ".auth.secret", Msg: "bgp authentication without a secret reference"}
+		}
+		s.Keychain = keychain
+		// the key is only resolved when it is written in the output
+		s.AuthKey = secretPlaceholder(*auth.Secret, keychain)
+		if p.renderSecrets {
+			key, err := p.secrets(*auth.Secret)
+			if err != nil {
+				return nil, &ConfigError{Path: path + ".auth.secret", Msg: err.Error()}
+			}
+			s.AuthKey = key
+		}
+	}
+	if sessions.HoldTime != nil {
+		s.HoldTime = *sessions.HoldTime
+	}
+	if sessions.KeepaliveInterval != nil {
+		s.KeepaliveInterval = *sessions.KeepaliveInterval
+	}
+	if sessions.MaxPrefix != nil {
+		s.MaxPrefix = *sessions.MaxPrefix
+	}
+	return s, nil
+}
+
+// cnfSessionsWarning tells that the bgp sessions of the cnfs are not covered
+const cnfSessionsWarning = "the bgp sessions of the cnfs towards the bgp loopbacks of the leafs get no bfd, authentication, timers or prefix limit, the helm values of the cnfs have no fields for them"
+
+// overlayBfdWarning tells that bfd does not cover the overlay sessions
+const overlayBfdWarning = "the multi-hop overlay sessions between the loopbacks run no bfd, they fail over when the underlay with bfd withdraws the loopback of the peer"
+
+// setPeerGroupSessions sets the session settings on the underlay and overlay
+// groups; the overlay sessions between the loopbacks are multi-hop, their
+// failure detection follows the underlay
+func setPeerGroupSessions(peerGroups []*PeerGroup, session *bgpSession) {
+	if session == nil {
+		return
+	}
+	for _, pg := range peerGroups {
+		if strings.HasPrefix(pg.Name, "underlay") {
+			pg.Session = session
+			continue
+		}
+		multihop := *session
+		multihop.BfdInterval, multihop.BfdMultiplier = 0, 0
+		pg.Session = &multihop
+	}
+}
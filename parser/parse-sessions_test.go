@@ -0,0 +1,364 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sessionGroup holds the settings of a bgp group in the SR Linux json
+type sessionGroup struct {
+	GroupName        string `json:"group-name"`
+	FailureDetection *struct {
+		EnableBfd bool `json:"enable-bfd"`
+	} `json:"failure-detection"`
+	Authentication *struct {
+		Keychain string `json:"keychain"`
+	} `json:"authentication"`
+	Timers *struct {
+		HoldTime          int `json:"hold-time"`
+		KeepaliveInterval int `json:"keepalive-interval"`
+	} `json:"timers"`
+	IPv4Unicast *struct {
+		PrefixLimit *struct {
+			MaxReceivedRoutes int `json:"max-received-routes"`
+		} `json:"prefix-limit"`
+	} `json:"ipv4-unicast"`
+}
+
+// keychainTree holds the keychains of a node in the SR Linux json
+type keychainTree struct {
+	System struct {
+		Authentication struct {
+			Keychain []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+				Key  []struct {
+					AuthenticationKey string `json:"authentication-key"`
+				} `json:"key"`
+			} `json:"keychain"`
+		} `json:"authentication"`
+	} `json:"system"`
+}
+
+// keys returns the key per keychain name and type
+func (tree *keychainTree) keys() map[string]string {
+	keys := make(map[string]string)
+	for _, kc := range tree.System.Authentication.Keychain {
+		if len(kc.Key) == 1 {
+			keys[kc.Name+"/"+kc.Type] = kc.Key[0].AuthenticationKey
+		}
+	}
+	return keys
+}
+
+func TestBgpSessions(t *testing.T) {
+	if err := os.Setenv("PACO_TEST_BGP_KEY", "infra-key"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Unsetenv("PACO_TEST_BGP_KEY") })
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("wl-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tree := struct {
+		NetworkInstance []struct {
+			Name      string `json:"name"`
+			Protocols struct {
+				Bgp struct {
+					Group []*sessionGroup `json:"group"`
+				} `json:"bgp"`
+			} `json:"protocols"`
+		} `json:"network-instance"`
+		Bfd struct {
+			Subinterface []struct {
+				ID                             string `json:"id"`
+				DesiredMinimumTransmitInterval int    `json:"desired-minimum-transmit-interval"`
+				DetectionMultiplier            int    `json:"detection-multiplier"`
+			} `json:"subinterface"`
+		} `json:"bfd"`
+		keychainTree
+	}{}
+	update := func(cfg *Config) {
+		cfg.Infrastructure.Protocols.BgpSessions = &BgpSessions{
+			Bfd:               &Bfd{},
+			Auth:              &BgpAuth{Secret: StringPtr("env:PACO_TEST_BGP_KEY")},
+			HoldTime:          IntPtr(9),
+			KeepaliveInterval: IntPtr(3),
+			MaxPrefix:         IntPtr(1000),
+		}
+		// the itfce towards the gateways has its own key and prefix limit
+		cfg.Workloads["infrastructure"]["dcgw-grp1"].Itfces["itfce"].BgpSessions = &BgpSessions{
+			Auth:      &BgpAuth{Type: StringPtr(BgpAuthTCPAO), Secret: StringPtr("file:" + keyFile)},
+			MaxPrefix: IntPtr(50),
+		}
+	}
+	res := generateNodeJSON(t, update, "leaf1", &tree)
+	groups := make(map[string]*sessionGroup)
+	for _, ni := range tree.NetworkInstance {
+		for _, g := range ni.Protocols.Bgp.Group {
+			groups[ni.Name+"/"+g.GroupName] = g
+		}
+	}
+
+	underlay, ok := groups["default/underlay"]
+	if !ok {
+		t.Fatal("no underlay group")
+	}
+	if underlay.FailureDetection == nil || !underlay.FailureDetection.EnableBfd {
+		t.Error("underlay group without bfd")
+	}
+	if underlay.Authentication == nil || underlay.Authentication.Keychain != infraKeychain {
+		t.Errorf("got underlay authentication %+v, want keychain %s", underlay.Authentication, infraKeychain)
+	}
+	if underlay.Timers == nil || underlay.Timers.HoldTime != 9 || underlay.Timers.KeepaliveInterval != 3 {
+		t.Errorf("got underlay timers %+v, want 9 and 3", underlay.Timers)
+	}
+	if underlay.IPv4Unicast == nil || underlay.IPv4Unicast.PrefixLimit == nil || underlay.IPv4Unicast.PrefixLimit.MaxReceivedRoutes != 1000 {
+		t.Error("underlay group without a prefix limit of 1000")
+	}
+	// the multi-hop overlay sessions do not run bfd
+	if overlay, ok := groups["default/overlay"]; !ok || overlay.FailureDetection != nil || overlay.Authentication == nil {
+		t.Errorf("got overlay group %+v, want authentication without bfd", overlay)
+	}
+
+	dcgw, ok := groups["infrastructure-ipvrf-itfce-45/dcgw"]
+	if !ok {
+		t.Fatal("no dcgw group")
+	}
+	if dcgw.Authentication == nil || dcgw.Authentication.Keychain != "paco-infrastructure-45" {
+		t.Errorf("got dcgw authentication %+v, want keychain paco-infrastructure-45", dcgw.Authentication)
+	}
+	if dcgw.FailureDetection == nil || dcgw.Timers == nil || dcgw.Timers.HoldTime != 9 {
+		t.Error("dcgw group does not inherit the bfd and timers of the protocols")
+	}
+	if dcgw.IPv4Unicast == nil || dcgw.IPv4Unicast.PrefixLimit == nil || dcgw.IPv4Unicast.PrefixLimit.MaxReceivedRoutes != 50 {
+		t.Error("dcgw group without a prefix limit of 50")
+	}
+
+	bfd := make(map[string]int)
+	for _, si := range tree.Bfd.Subinterface {
+		if si.DetectionMultiplier != defaultBfdMultiplier {
+			t.Errorf("%s: got bfd multiplier %d, want %d", si.ID, si.DetectionMultiplier, defaultBfdMultiplier)
+		}
+		bfd[si.ID] = si.DesiredMinimumTransmitInterval
+	}
+	for _, id := range []string{"ethernet-1/49.0", "ethernet-1/50.45"} {
+		if bfd[id] != defaultBfdInterval*1000 {
+			t.Errorf("%s: got bfd interval %d us, want %d", id, bfd[id], defaultBfdInterval*1000)
+		}
+	}
+
+	// the keys are not part of the output, their placeholders are filled in
+	// when the output is applied
+	keys := tree.keys()
+	for kc, want := range map[string]string{"paco-infra/tcp-md5": "${PACO_TEST_BGP_KEY}", "paco-infrastructure-45/tcp-ao": "${PACO_INFRASTRUCTURE_45_KEY}"} {
+		if keys[kc] != want {
+			t.Errorf("keychain %s: got key %q, want %q", kc, keys[kc], want)
+		}
+	}
+	sros := string(res.SrosConfigs["dcgw1"])
+	for _, out := range []string{sros, string(res.SwitchConfigs["leaf1"]), string(res.SwitchConfigs["leaf2"])} {
+		if strings.Contains(out, "infra-key") || strings.Contains(out, "wl-key") {
+			t.Fatal("got a key in plaintext in the output")
+		}
+	}
+	for _, want := range []string{
+		`/configure system security keychains keychain "paco-infrastructure-45" bidirectional entry 0 authentication-key "${PACO_INFRASTRUCTURE_45_KEY}"`,
+		`/configure service vprn "infrastructure" interface "paco-leaf1-45" ipv4 bfd transmit-interval 100`,
+		`/configure service vprn "infrastructure" bgp group "paco-leaf" bfd-liveness true`,
+		`/configure service vprn "infrastructure" bgp group "paco-leaf" authentication-keychain "paco-infrastructure-45"`,
+		`/configure service vprn "infrastructure" bgp group "paco-leaf" hold-time seconds 9`,
+		`/configure service vprn "infrastructure" bgp group "paco-leaf" prefix-limit ipv4 maximum 50`,
+	} {
+		if !strings.Contains(sros, want) {
+			t.Errorf("dcgw1 configuration misses %q", want)
+		}
+	}
+
+	// the keys are resolved from the references when the secrets are rendered
+	rendered := &keychainTree{}
+	res = generateNodeJSON(t, update, "leaf1", rendered, WithRenderSecrets(true))
+	keys = rendered.keys()
+	for kc, want := range map[string]string{"paco-infra/tcp-md5": "infra-key", "paco-infrastructure-45/tcp-ao": "wl-key"} {
+		if keys[kc] != want {
+			t.Errorf("rendered keychain %s: got key %q, want %q", kc, keys[kc], want)
+		}
+	}
+	if want := `authentication-key "wl-key"`; !strings.Contains(string(res.SrosConfigs["dcgw1"]), want) {
+		t.Errorf("rendered dcgw1 configuration misses %q", want)
+	}
+}
+
+func TestBgpSessionsSecretResolver(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Infrastructure.Protocols.BgpSessions = &BgpSessions{
+		Auth: &BgpAuth{Secret: StringPtr("vault:bgp")},
+	}
+	// the reference is not resolved for a placeholder
+	if _, err := Generate(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	// the default resolver only knows env: and file:
+	_, err = Generate(context.Background(), cfg, WithRenderSecrets(true))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != "infrastructure.protocols.bgp_sessions.auth.secret" {
+		t.Fatalf("got error %v, want ConfigError of the secret", err)
+	}
+
+	tree := &keychainTree{}
+	generateNodeJSON(t, func(cfg *Config) {
+		cfg.Infrastructure.Protocols.BgpSessions = &BgpSessions{
+			Auth: &BgpAuth{Secret: StringPtr("vault:bgp")},
+		}
+	}, "leaf1", tree, WithRenderSecrets(true), WithSecretResolver(func(ref string) (string, error) {
+		return "from-" + ref, nil
+	}))
+	if got := tree.keys()["paco-infra/tcp-md5"]; got != "from-vault:bgp" {
+		t.Errorf("got key %q, want the key of the resolver", got)
+	}
+}
+
+func TestSecretPlaceholder(t *testing.T) {
+	for _, tc := range []struct {
+		ref, keychain, want string
+	}{
+		{"env:PACO_BGP_KEY", infraKeychain, "${PACO_BGP_KEY}"},
+		{"file:/run/secrets/bgp", infraKeychain, "${PACO_INFRA_KEY}"},
+		{"vault:bgp", "paco-multus-sba", "${PACO_MULTUS_SBA_KEY}"},
+		// not a variable name of the shell
+		{"env:bgp-key", "paco-infrastructure", "${PACO_INFRASTRUCTURE_KEY}"},
+	} {
+		if got := secretPlaceholder(tc.ref, tc.keychain); got != tc.want {
+			t.Errorf("secretPlaceholder(%s, %s): got %s, want %s", tc.ref, tc.keychain, got, tc.want)
+		}
+	}
+}
+
+func TestBgpSessionsIgpUnderlay(t *testing.T) {
+	type bfdAf struct {
+		EnableBfd bool `json:"enable-bfd"`
+	}
+	for _, protocol := range []string{UnderlayISIS, UnderlayOSPF} {
+		tree := struct {
+			NetworkInstance []struct {
+				Name      string `json:"name"`
+				Protocols struct {
+					Isis *struct {
+						Instance []struct {
+							Interface []struct {
+								InterfaceName string `json:"interface-name"`
+								IPv4Unicast   *bfdAf `json:"ipv4-unicast"`
+								IPv6Unicast   *bfdAf `json:"ipv6-unicast"`
+							} `json:"interface"`
+						} `json:"instance"`
+					} `json:"isis"`
+					Ospf *struct {
+						Instance []struct {
+							Area []struct {
+								Interface []struct {
+									InterfaceName    string `json:"interface-name"`
+									FailureDetection *bfdAf `json:"failure-detection"`
+								} `json:"interface"`
+							} `json:"area"`
+						} `json:"instance"`
+					} `json:"ospf"`
+				} `json:"protocols"`
+			} `json:"network-instance"`
+			Bfd struct {
+				Subinterface []struct {
+					ID string `json:"id"`
+				} `json:"subinterface"`
+			} `json:"bfd"`
+		}{}
+		generateNodeJSON(t, func(cfg *Config) {
+			cfg.Infrastructure.Protocols.Protocol = StringPtr(protocol)
+			cfg.Infrastructure.Protocols.BgpSessions = &BgpSessions{Bfd: &Bfd{}}
+		}, "leaf1", &tree)
+
+		// the igp of the isl runs bfd, the passive system0 does not
+		bfdItfces := make(map[string]bool)
+		for _, ni := range tree.NetworkInstance {
+			if ni.Name != "default" {
+				continue
+			}
+			if isis := ni.Protocols.Isis; isis != nil {
+				for _, itfce := range isis.Instance[0].Interface {
+					bfdItfces[itfce.InterfaceName] = itfce.IPv4Unicast != nil && itfce.IPv4Unicast.EnableBfd && itfce.IPv6Unicast != nil && itfce.IPv6Unicast.EnableBfd
+				}
+			}
+			if ospf := ni.Protocols.Ospf; ospf != nil {
+				for _, inst := range ospf.Instance {
+					for _, itfce := range inst.Area[0].Interface {
+						bfdItfces[itfce.InterfaceName] = itfce.FailureDetection != nil && itfce.FailureDetection.EnableBfd
+					}
+				}
+			}
+		}
+		if want := map[string]bool{"ethernet-1/49.0": true, "system0.0": false}; !reflect.DeepEqual(bfdItfces, want) {
+			t.Errorf("%s: got bfd per igp interface %v, want %v", protocol, bfdItfces, want)
+		}
+		found := false
+		for _, si := range tree.Bfd.Subinterface {
+			found = found || si.ID == "ethernet-1/49.0"
+		}
+		if !found {
+			t.Errorf("%s: no bfd on the isl subinterface", protocol)
+		}
+	}
+}
+
+func TestBgpSessionsOverlayBfdWarning(t *testing.T) {
+	b, err := os.ReadFile(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bfd of the underlay does not cover the multi-hop overlay sessions
+	b = []byte(strings.Replace(string(b), "  protocols:\n", "  protocols:\n    bgp_sessions: {bfd: {}}\n", 1))
+	file := filepath.Join(t.TempDir(), "paco.yaml")
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	verrs, err := ValidateConfig(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// neither do the settings cover the sessions of the cnfs
+	if len(verrs) != 2 || !verrs[0].Warning || verrs[0].Path != "infrastructure.protocols.bgp_sessions" ||
+		!verrs[1].Warning || verrs[1].Path != "infrastructure.protocols.bgp_sessions.bfd" {
+		t.Errorf("got %v, want a warning about the sessions of the cnfs and the bfd of the overlay", verrs)
+	}
+}
+
+func TestBgpSessionsKeychainConflict(t *testing.T) {
+	b, err := os.ReadFile(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same vlan of a workload towards two client groups with another secret
+	itfce := "        itfce: {vlan_id: 45, kind: routed, ipv4_cidr: [10.100.40.0/24], ipv6_cidr: [2010:100:40::/48], ipv4_itfce_prefix_length: 31, ipv6_itfce_prefix_length: 127, addressing_schema: \"dual-stack\""
+	if !strings.Contains(string(b), itfce) {
+		t.Fatal("the routed itfce of the infrastructure workload is not found")
+	}
+	b = []byte(strings.Replace(string(b), itfce+"}\n", itfce+", bgp_sessions: {auth: {secret: env:KEY1}}}\n"+
+		"    dcgw-grp2:\n      itfces:\n"+itfce+", bgp_sessions: {auth: {secret: env:KEY2}}}\n", 1))
+	file := filepath.Join(t.TempDir(), "paco.yaml")
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	verrs, err := ValidateConfig(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verrs) != 1 || verrs[0].Warning || verrs[0].Path != "workloads.infrastructure.dcgw-grp2.itfces.itfce.bgp_sessions.auth" ||
+		!strings.Contains(verrs[0].Message, "keychain paco-infrastructure-45 is also used by workloads.infrastructure.dcgw-grp1.itfces.itfce.bgp_sessions.auth") {
+		t.Errorf("got %v, want a conflict of keychain paco-infrastructure-45", verrs)
+	}
+}
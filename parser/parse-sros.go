@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// srosRenderer renders the SR OS nodes, the data center gateways of the
+// routed workloads; the fabric intent is not supported
+type srosRenderer struct {
+	unsupportedRenderer
+	p         *Parser
+	templates map[string]*template.Template
+	// gateways holds the gateway configuration per node name
+	gateways map[string]*srosGateway
+}
+
+func init() {
+	registerSwitchBackend("sros", []string{"sros", "vr-sros"}, map[string]string{
+		"srosGateway": goSrosGatewayTemplate,
+	}, newSrosRenderer)
+}
+
+func newSrosRenderer(p *Parser, templates map[string]*template.Template) switchRenderer {
+	return &srosRenderer{
+		unsupportedRenderer: unsupportedRenderer{kind: "sros"},
+		p:                   p,
+		templates:           templates,
+		gateways:            make(map[string]*srosGateway),
+	}
+}
+
+// srosGateway holds the configuration of an SR OS data center gateway, the
+// gateway side of the routed workload links to the leafs
+type srosGateway struct {
+	Name string
+	AS   uint32
+	// Ports holds the SR OS ports towards the leafs
+	Ports []*srosPort
+	// Vprns holds a vprn per workload, key is the workload name
+	Vprns map[string]*srosVprn
+	// UePoolCidrs holds the ue pools that are exported to the wan
+	UePoolCidrs []string
+	// Keychains holds the tcp-ao keychains of the vprns by keychain name
+	Keychains map[string]*bgpSession
+}
+
+type srosPort struct {
+	Name        string
+	Description string
+}
+
+type srosVprn struct {
+	Name         string
+	ServiceID    int
+	Interfaces   []*srosInterface
+	Neighbors    []*Neighbor
+	ExportUePool bool
+	// WanNeighbors holds the wan peers of the gateway the ue pools are
+	// exported to
+	WanNeighbors []*Neighbor
+	// Session holds the bfd, authentication, timers and prefix limit of the
+	// sessions towards the leafs
+	Session *bgpSession
+}
+
+type srosInterface struct {
+	Name             string
+	Port             string
+	VlanID           int
+	IPv4Address      string
+	IPv4PrefixLength int
+	IPv6Address      string
+	IPv6PrefixLength int
+}
+
+// GatewayLink records the gateway side of a routed workload link between a
+// leaf and an SR OS node; the leaf and the gateway use the address of their
+// own endpoint of the link, whichever end of the link they are. It returns the
+// eBGP neighbors of the leaf towards the gateway.
+func (r *srosRenderer) GatewayLink(wlName string, netwInfo *NetworkInfo, leaf *Node, leafEp *Endpoint, link *Link, ipv4prefix, ipv6prefix string, session *bgpSession) []*Neighbor {
+	gwEp := link.B
+	if link.B == leafEp {
+		gwEp = link.A
+	}
+	gwNode := gwEp.Node
+	if _, ok := r.gateways[*gwNode.ShortName]; !ok {
+		r.gateways[*gwNode.ShortName] = &srosGateway{
+			Name:  *gwNode.ShortName,
+			AS:    *gwNode.AS,
+			Ports: make([]*srosPort, 0),
+			Vprns: make(map[string]*srosVprn),
+		}
+	}
+	gw := r.gateways[*gwNode.ShortName]
+
+	port := *gwEp.RealName
+	found := false
+	for _, pt := range gw.Ports {
+		if pt.Name == port {
+			found = true
+		}
+	}
+	if !found {
+		gw.Ports = append(gw.Ports, &srosPort{
+			Name:        port,
+			Description: "paco-" + *leaf.ShortName + "-" + *leafEp.ShortName,
+		})
+	}
+
+	if _, ok := gw.Vprns[wlName]; !ok {
+		gw.Vprns[wlName] = &srosVprn{
+			Name:       wlName,
+			ServiceID:  *netwInfo.VlanID, // see assignServiceIDs
+			Interfaces: make([]*srosInterface, 0),
+			Neighbors:  make([]*Neighbor, 0),
+			Session:    session,
+		}
+	}
+	vprn := gw.Vprns[wlName]
+
+	itfce := &srosInterface{
+		Name:   "paco-" + *leaf.ShortName + "-" + strconv.Itoa(*netwInfo.VlanID),
+		Port:   port,
+		VlanID: *netwInfo.VlanID,
+	}
+	leafNeighbors := make([]*Neighbor, 0)
+	if ipv4prefix != "" && *gwEp.IPv4Address != "" {
+		itfce.IPv4Address = *gwEp.IPv4Address
+		itfce.IPv4PrefixLength = *gwEp.IPv4PrefixLength
+		vprn.Neighbors = append(vprn.Neighbors, &Neighbor{
+			PeerIP:    *leafEp.IPv4Address,
+			PeerAS:    *leaf.AS,
+			PeerGroup: "paco-leaf",
+		})
+		leafNeighbors = append(leafNeighbors, &Neighbor{
+			PeerIP:    *gwEp.IPv4Address,
+			PeerAS:    gw.AS,
+			PeerGroup: "dcgw",
+		})
+	}
+	if ipv6prefix != "" && *gwEp.IPv6Address != "" {
+		itfce.IPv6Address = *gwEp.IPv6Address
+		itfce.IPv6PrefixLength = *gwEp.IPv6PrefixLength
+		vprn.Neighbors = append(vprn.Neighbors, &Neighbor{
+			PeerIP:    *leafEp.IPv6Address,
+			PeerAS:    *leaf.AS,
+			PeerGroup: "paco-leaf",
+		})
+		leafNeighbors = append(leafNeighbors, &Neighbor{
+			PeerIP:    *gwEp.IPv6Address,
+			PeerAS:    gw.AS,
+			PeerGroup: "dcgw",
+		})
+	}
+	vprn.Interfaces = append(vprn.Interfaces, itfce)
+	return leafNeighbors
+}
+
+// Finish writes the MD-CLI configuration of the SR OS gateways
+func (r *srosRenderer) Finish() error {
+	if len(r.gateways) == 0 {
+		return nil
+	}
+	log.Infof("Writing SR OS gateway configuration...")
+
+	// the ue pools are exported to the wan from the vprn of the internet workload
+	internetWls, uePools := r.p.uePoolWorkloads()
+
+	if err := r.p.CreateDirectory(*r.p.BaseSrosDir, 0777); err != nil {
+		return err
+	}
+	for _, gwName := range SortedKeys(r.gateways) {
+		gw := r.gateways[gwName]
+		sort.Slice(gw.Ports, func(i, j int) bool { return gw.Ports[i].Name < gw.Ports[j].Name })
+		gw.Keychains = make(map[string]*bgpSession)
+		wanNeighbors, err := r.wanNeighbors(gwName)
+		if err != nil {
+			return err
+		}
+		for wlName, vprn := range gw.Vprns {
+			vprn.ExportUePool = internetWls[wlName] && len(uePools) > 0
+			if vprn.ExportUePool && len(wanNeighbors) == 0 {
+				log.Warnf("%s has no wan_peers, the ue pools of %s are not exported", gwName, wlName)
+				vprn.ExportUePool = false
+			}
+			if vprn.ExportUePool {
+				vprn.WanNeighbors = wanNeighbors
+			}
+			if vprn.Session != nil && vprn.Session.AuthType == BgpAuthTCPAO {
+				gw.Keychains[vprn.Session.Keychain] = vprn.Session
+			}
+		}
+		if len(wanNeighbors) > 0 {
+			gw.UePoolCidrs = uePools
+		}
+		gw.assignServiceIDs()
+		if err := r.writeConfig(r.p.BaseSrosDir, StringPtr(gwName+".cfg"), gw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// srosServiceIDBase is the first service-id that is allocated to a vprn, it is
+// above the vlan range such that it does not collide with the service-ids that
+// are taken from the vlan of the workload
+const srosServiceIDBase = 5000
+
+// assignServiceIDs sets the service-id of the vprns of the gateway; a vprn
+// keeps the vlan of its workload as service-id, unless the vlan is 0, e.g. for
+// a vlan aware workload, or the vlan is already used by another vprn, then the
+// next id from srosServiceIDBase is allocated
+func (gw *srosGateway) assignServiceIDs() {
+	used := make(map[int]bool)
+	next := srosServiceIDBase
+	for _, wlName := range SortedKeys(gw.Vprns) {
+		vprn := gw.Vprns[wlName]
+		if vprn.ServiceID != 0 && !used[vprn.ServiceID] {
+			used[vprn.ServiceID] = true
+			continue
+		}
+		vprn.ServiceID = next
+		next++
+	}
+}
+
+// wanNeighbors returns the eBGP neighbors of the gateway towards the wan, from
+// the wan_peers of the node in the topology
+func (r *srosRenderer) wanNeighbors(gwName string) ([]*Neighbor, error) {
+	neighbors := make([]*Neighbor, 0)
+	nodeCfg, ok := r.p.Config.Topology.Nodes[gwName]
+	if !ok || nodeCfg == nil {
+		return neighbors, nil
+	}
+	for i, peer := range nodeCfg.WanPeers {
+		if peer == nil || peer.PeerAddress == nil || net.ParseIP(*peer.PeerAddress) == nil || peer.PeerAS == nil {
+			return nil, &ConfigError{Path: fmt.Sprintf("topology.nodes.%s.wan_peers[%d]", gwName, i), Msg: "wan peer requires a peer_address and a peer_as"}
+		}
+		neighbors = append(neighbors, &Neighbor{
+			PeerIP:    *peer.PeerAddress,
+			PeerAS:    *peer.PeerAS,
+			PeerGroup: "wan",
+		})
+	}
+	return neighbors, nil
+}
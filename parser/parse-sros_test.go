@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSrosPortName(t *testing.T) {
+	for name, want := range map[string]string{
+		"eth1":   "1/1/1",
+		"eth12":  "1/1/12",
+		"1/1/c1": "1/1/c1",
+		"ethx":   "ethx",
+	} {
+		if got := srosPortName(name); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestSrosGateways(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the gateways get their AS after the leafs
+	for name, as := range map[string]uint32{"leaf1": 65001, "leaf2": 65002, "dcgw1": 65003, "dcgw2": 65004} {
+		if *res.Nodes[name].AS != as {
+			t.Errorf("%s: got AS %d, want %d", name, *res.Nodes[name].AS, as)
+		}
+	}
+
+	b, ok := res.SrosConfigs["dcgw1"]
+	if !ok {
+		t.Fatal("no configuration for dcgw1")
+	}
+	cfgText := string(b)
+	for _, want := range []string{
+		`/configure service vprn "infrastructure" interface "paco-leaf1-45" sap 1/1/1:45`,
+		`/configure service vprn "infrastructure" interface "paco-leaf1-45" ipv4 primary address 10.100.40.1 prefix-length 31`,
+		`/configure service vprn "infrastructure" bgp neighbor "10.100.40.0" peer-as 65001`,
+		`/configure policy-options prefix-list "paco-uepool" prefix 10.0.128.0/17 type longer`,
+		`/configure service vprn "multus-internet" bgp group "wan" export policy ["paco-export-uepool"]`,
+		`/configure service vprn "multus-internet" bgp neighbor "192.0.2.1" group "wan"`,
+		`/configure service vprn "multus-internet" bgp neighbor "192.0.2.1" peer-as 64512`,
+	} {
+		if !strings.Contains(cfgText, want) {
+			t.Errorf("dcgw1 configuration misses %q", want)
+		}
+	}
+	if strings.Contains(cfgText, `vprn "multus-sba" bgp group "wan"`) {
+		t.Error("ue pool is exported from multus-sba")
+	}
+
+	// the leaf side of the ebgp sessions
+	bgp := switchManifest(t, res, "leaf1", "infrastructure-45-protocolbgp-leaf1")
+	if got := bgpNeighbors(bgp)["10.100.40.1"]; got != 65003 {
+		t.Errorf("leaf1 has no ebgp session to dcgw1: got peer-as %v", got)
+	}
+}
+
+// switchManifest returns the k8s switch resource of a node by name
+func switchManifest(t *testing.T, res *Result, nodeName, name string) *Manifest {
+	t.Helper()
+	for _, m := range res.SwitchResources[nodeName] {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("%s has no switch resource %s", nodeName, name)
+	return nil
+}
+
+// bgpNeighbors returns the peer-as per peer-address of a bgp resource
+func bgpNeighbors(m *Manifest) map[string]interface{} {
+	neighbors := make(map[string]interface{})
+	spec, _ := m.Object["spec"].(map[string]interface{})
+	bgp, _ := spec["bgp"].(map[string]interface{})
+	list, _ := bgp["neighbor"].([]interface{})
+	for _, e := range list {
+		if n, ok := e.(map[string]interface{}); ok {
+			neighbors[stringField(n, "peer-address")] = n["peer-as"]
+		}
+	}
+	return neighbors
+}
+
+func TestSrosGatewayLinkDirection(t *testing.T) {
+	// the gateway as the first endpoint of its links gets the first address
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range cfg.Topology.Links {
+		if strings.HasPrefix(*l.Endpoints[1], "dcgw") {
+			l.Endpoints[0], l.Endpoints[1] = l.Endpoints[1], l.Endpoints[0]
+		}
+	}
+	res, err := Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := string(res.SrosConfigs["dcgw1"])
+	for _, want := range []string{
+		`/configure service vprn "infrastructure" interface "paco-leaf1-45" ipv4 primary address 10.100.40.0 prefix-length 31`,
+		`/configure service vprn "infrastructure" bgp neighbor "10.100.40.1" peer-as 65001`,
+		`/configure service vprn "infrastructure" bgp neighbor "2010:100:40::1" peer-as 65001`,
+	} {
+		if !strings.Contains(gw, want) {
+			t.Errorf("dcgw1 configuration misses %q", want)
+		}
+	}
+	if n := strings.Count(gw, `interface "paco-leaf1-45" admin-state enable`); n != 1 {
+		t.Errorf("got the interface towards leaf1 %d times, want once", n)
+	}
+	bgp := switchManifest(t, res, "leaf1", "infrastructure-45-protocolbgp-leaf1")
+	if got := bgpNeighbors(bgp)["10.100.40.0"]; got != 65003 {
+		t.Errorf("leaf1 has no ebgp session to dcgw1: got peer-as %v", got)
+	}
+	leafItfce := switchManifest(t, res, "leaf1", "infrastructure-subinterface-e1-50-leaf1")
+	if b := res.Files[leafItfce.Path]; !strings.Contains(string(b), "ip-prefix: 10.100.40.1/31") {
+		t.Errorf("leaf1 has not the second address of the link:\n%s", b)
+	}
+}
+
+func TestSrosServiceIDs(t *testing.T) {
+	gw := &srosGateway{Vprns: map[string]*srosVprn{
+		"infrastructure":  {ServiceID: 0},
+		"multus-internet": {ServiceID: 1550},
+		"multus-mgmt":     {ServiceID: 1550},
+		"multus-sba":      {ServiceID: 0},
+	}}
+	gw.assignServiceIDs()
+	for wlName, want := range map[string]int{
+		"infrastructure":  srosServiceIDBase,
+		"multus-internet": 1550,
+		"multus-mgmt":     srosServiceIDBase + 1,
+		"multus-sba":      srosServiceIDBase + 2,
+	} {
+		if got := gw.Vprns[wlName].ServiceID; got != want {
+			t.Errorf("%s: got service-id %d, want %d", wlName, got, want)
+		}
+	}
+}
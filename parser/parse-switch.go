@@ -32,6 +32,10 @@ type k8ssrlsubinterface struct {
 	Kind               string // routed or bridged
 	IPv4Prefix         string
 	IPv6Prefix         string
+	// Unnumbered is set for an isl with only link-local addresses, with
+	// IPv4Unnumbered ipv4 is unnumbered to system0
+	Unnumbered     bool
+	IPv4Unnumbered bool
 }
 
 type k8ssrlirbsubinterface struct {
@@ -52,7 +56,21 @@ type k8ssrlTunnelInterface struct {
 type k8ssrlVxlanInterface struct {
 	TunnelInterfaceName string
 	Kind                string // routed or bridged
-	VlanID              string
+	Index               string // index and vni of the vxlan-interface, see vxlanIndex
+}
+
+// routedVxlanOffset offsets the index and vni of the vxlan-interfaces of routed
+// networks, such that a routed network and the bridged part of an irb network
+// on the same vlan each get their own vxlan-interface
+const routedVxlanOffset = 10000
+
+// vxlanIndex returns the index of the vxlan-interface of a network of the kind
+// (routed or bridged) on the vlan
+func vxlanIndex(kind string, vlanID int) int {
+	if kind == "routed" {
+		return routedVxlanOffset + vlanID
+	}
+	return vlanID
 }
 
 type k8ssrlNetworkInstance struct {
@@ -69,14 +87,30 @@ type k8ssrlprotocolsbgp struct {
 	NetworkInstanceName string
 	AS                  uint32
 	RouterID            string
+	AddressFamilies     []string
 	PeerGroups          []*PeerGroup
 	Neighbors           []*Neighbor
+	DynamicNeighbors    []*DynamicNeighbor
 }
 
 type PeerGroup struct {
 	Name       string
 	PolicyName string
 	Protocols  []string
+	// ImportPolicyName is the import policy of the group, e.g. of the routes
+	// of the gateways
+	ImportPolicyName string
+	// ClusterID is set for the group of the route reflector clients
+	ClusterID string
+	// NextHopUnchanged keeps the next-hop of the routes, e.g. the vtep of the
+	// evpn routes over the underlay sessions
+	NextHopUnchanged bool
+	// IPv6NextHops carries the ipv4 routes with ipv6 next-hops over the
+	// sessions of the unnumbered isls
+	IPv6NextHops bool
+	// Session holds the bfd, authentication, timers and prefix limit of the
+	// sessions of the group
+	Session *bgpSession
 }
 
 type Neighbor struct {
@@ -92,29 +126,26 @@ type k8ssrlESI struct {
 	LagName string
 }
 
-type k8ssrlRoutingPolicy struct {
-	Name              string
-	IPv4Prefix        string
-	IPv4PrefixSetName string
-	IPv6Prefix        string
-	IPv6PrefixSetName string
-}
-
-func (p *Parser) WriteBase() {
-	p.CreateDirectory(*p.BaseSwitchDir, 0777)
+func (p *Parser) WriteBase() error {
+	return p.createSwitchDirectory(*p.BaseSwitchDir)
 }
 
-func (p *Parser) WriteFinalBase(kdirs []string) {
+func (p *Parser) WriteFinalBase(kdirs []string) error {
 	dirName := filepath.Join(*p.BaseSwitchDir, "base")
-	p.CreateDirectory(dirName, 0777)
-	p.WriteKustomize(StringPtr(dirName), StringPtr("kustomization.yaml"), kdirs)
+	if err := p.createSwitchDirectory(dirName); err != nil {
+		return err
+	}
+	return p.WriteKustomize(StringPtr(dirName), StringPtr("kustomization.yaml"), kdirs)
 }
 
-func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
+func (p *Parser) WriteInfrastructure() ([]string, error) {
+	var kuztomizedirs []string
 	var fileName string
 	log.Infof("Writing infrastructure k8s yaml objects...")
 	dirName := filepath.Join(*p.BaseSwitchDir, "infra")
-	p.CreateDirectory(dirName, 0777)
+	if err := p.createSwitchDirectory(dirName); err != nil {
+		return nil, err
+	}
 
 	kuztomizedirs = append(kuztomizedirs, "../infra")
 
@@ -140,11 +171,13 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 	k8ssrlinterfaces = append(k8ssrlinterfaces, irbInterface)
 
 	fileName = "interface-system.yaml"
-	p.WriteSrlInterface(&dirName,
+	if err := p.WriteSrlInterface(&dirName,
 		StringPtr(fileName),
 		StringPtr("infra-interface-system0"),
 		StringPtr("leaf-grp1"),
-		k8ssrlinterfaces)
+		k8ssrlinterfaces); err != nil {
+		return nil, err
+	}
 	resources = append(resources, fileName)
 
 	tunnelinterfaces := make([]*k8ssrlTunnelInterface, 0)
@@ -154,38 +187,58 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 	tunnelinterfaces = append(tunnelinterfaces, tunnelInterface)
 
 	fileName = "tunnel-interface-vxlan0.yaml"
-	p.WriteSrlTunnelInterface(&dirName,
+	if err := p.WriteSrlTunnelInterface(&dirName,
 		StringPtr(fileName),
 		StringPtr("infra-tunnel-interface-vxlan0"),
 		StringPtr("leaf-grp1"),
-		tunnelinterfaces)
-	resources = append(resources, fileName)
-
-	// TODO need to add supernet
-	var ipv4Cidr *string
-	var ipv6Cidr *string
-	for i := 0; i < len(p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr); i++ {
-		ipv4Cidr = p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr[i]
-		ipv6Cidr = p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr[i]
-	}
-
-	routingPolicy := &k8ssrlRoutingPolicy{
-		Name:              "export-underlay-local",
-		IPv4Prefix:        *ipv4Cidr,
-		IPv6Prefix:        *ipv6Cidr,
-		IPv4PrefixSetName: "system-v4",
-		IPv6PrefixSetName: "system-v6",
+		tunnelinterfaces); err != nil {
+		return nil, err
 	}
+	resources = append(resources, fileName)
 
 	fileName = "routing-policy.yaml"
-	p.WriteSrlRoutingPolicy(&dirName,
+	if err := p.WriteSrlRoutingPolicy(&dirName,
 		StringPtr(fileName),
 		StringPtr("infra-routing-policy"),
 		StringPtr("leaf-grp1"),
-		routingPolicy)
+		p.underlayRoutingPolicy()); err != nil {
+		return nil, err
+	}
 	resources = append(resources, fileName)
 
-	for nodeName, n := range p.Nodes {
+	if err := p.checkUnderlay(); err != nil {
+		return nil, err
+	}
+	session, err := p.bgpSession("infrastructure.protocols.bgp_sessions", p.infraSessions(), infraKeychain)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil && session.BfdInterval != 0 && p.overlayDesign() != OverlayEBGP {
+		log.Warn(overlayBfdWarning)
+	}
+	if session != nil {
+		log.Warn(cnfSessionsWarning)
+	}
+	if session != nil && session.Keychain != "" {
+		fileName = "keychain.yaml"
+		if err := p.WriteSrlKeychain(&dirName,
+			StringPtr(fileName),
+			StringPtr("infra-keychain"),
+			StringPtr("leaf-grp1"),
+			session); err != nil {
+			return nil, err
+		}
+		resources = append(resources, fileName)
+	}
+	// the nodes of the fabric that take part in the overlay
+	fabric := p.fabricNodes()
+	rrs, err := p.routeReflectors(fabric)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeName := range SortedKeys(p.Nodes) {
+		n := p.Nodes[nodeName]
 		// reinitialize parameters per node
 		found := false
 		islinterfaces := make([]*k8ssrlinterface, 0)
@@ -193,10 +246,10 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 		systemsubinterfaces := make([]*k8ssrlsubinterface, 0)
 		allsubinterfaces := make([]*k8ssrlsubinterface, 0)
 		neighbors := make([]*Neighbor, 0)
-		neighborLoopBackIPv4s := make(map[string]string)
-		neighborLoopBackIPv6s := make(map[string]string)
+		dynamicNeighbors := make([]*DynamicNeighbor, 0)
 		if *n.Position == "network" {
-			for epName, ep := range n.Endpoints {
+			for _, epName := range SortedKeys(n.Endpoints) {
+				ep := n.Endpoints[epName]
 				if *ep.Kind == "isl" {
 					found = true
 					log.Debugf("Node name: %s, Interface: %s, %s, %t", nodeName, *ep.RealName, *ep.IPv4Prefix, *ep.VlanTagging)
@@ -218,24 +271,26 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 						VlanTagging:        *ep.VlanTagging,
 						VlanID:             *ep.VlanID,
 						Kind:               "routed",
-						IPv4Prefix:         *ep.IPv4Address + "/" + strconv.Itoa(*ep.IPv4PrefixLength),
-						IPv6Prefix:         *ep.IPv6Address + "/" + strconv.Itoa(*ep.IPv6PrefixLength),
+						IPv4Prefix:         addressPrefix(ep.IPv4Address, ep.IPv4PrefixLength),
+						IPv6Prefix:         addressPrefix(ep.IPv6Address, ep.IPv6PrefixLength),
 					}
-					neighbor := &Neighbor{
-						PeerIP:           *ep.IPv4NeighborAddress,
-						PeerAS:           *ep.PeerAS,
-						PeerGroup:        "underlay",
-						LocalAS:          0,
-						TransportAddress: "",
+					if p.unnumberedIsl() {
+						islsubinterface.IPv4Prefix = ""
+						islsubinterface.IPv6Prefix = ""
+						islsubinterface.Unnumbered = true
+						// ipv4 is only unnumbered when the fabric carries ipv4
+						islsubinterface.IPv4Unnumbered = p.infraSchema() != AddressingIPv6Only
 					}
 					islinterfaces = append(islinterfaces, islinterface)
 					islsubinterfaces = append(islsubinterfaces, islsubinterface)
 					allsubinterfaces = append(allsubinterfaces, islsubinterface)
-					neighbors = append(neighbors, neighbor)
-					// build a list of neighboring IPs to which BGP peering could be established in full mesh BGP w/o RR
-					if _, ok := neighborLoopBackIPv4s[*ep.PeerNode.ShortName]; !ok {
-						neighborLoopBackIPv4s[*ep.PeerNode.ShortName] = *ep.PeerNode.Endpoints["lo0"].IPv4Address
-						neighborLoopBackIPv6s[*ep.PeerNode.ShortName] = *ep.PeerNode.Endpoints["lo0"].IPv6Address
+					// with an igp underlay there are no underlay bgp sessions
+					switch {
+					case p.igpUnderlay():
+					case p.unnumberedIsl():
+						dynamicNeighbors = append(dynamicNeighbors, dynamicNeighbor(ep))
+					default:
+						neighbors = append(neighbors, p.underlayNeighbors(ep)...)
 					}
 				}
 				if *ep.Kind == "loopback" {
@@ -245,8 +300,8 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 						VlanTagging:        false,
 						VlanID:             "0",
 						Kind:               "loopback", // used to indicate not to write the routed or bridged type
-						IPv4Prefix:         *ep.IPv4Address + "/" + strconv.Itoa(*ep.IPv4PrefixLength),
-						IPv6Prefix:         *ep.IPv6Address + "/" + strconv.Itoa(*ep.IPv6PrefixLength),
+						IPv4Prefix:         addressPrefix(ep.IPv4Address, ep.IPv4PrefixLength),
+						IPv6Prefix:         addressPrefix(ep.IPv6Address, ep.IPv6PrefixLength),
 					}
 					systemsubinterfaces = append(systemsubinterfaces, systemsubinterface)
 					allsubinterfaces = append(allsubinterfaces, systemsubinterface)
@@ -254,45 +309,44 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 			}
 		}
 		if found {
-			for neighborNodeName, neighborIP := range neighborLoopBackIPv4s {
-				log.Debugf("Node Name: %s, Neighbor Node Name: %s", *n.ShortName, neighborNodeName)
-				neighbor := &Neighbor{
-					PeerIP:           neighborIP,
-					PeerAS:           *p.Config.Infrastructure.Protocols.OverlayAs,
-					PeerGroup:        "overlay",
-					LocalAS:          *p.Config.Infrastructure.Protocols.OverlayAs,
-					TransportAddress: *n.Endpoints["lo0"].IPv4Address,
-				}
-				neighbors = append(neighbors, neighbor)
-			}
+			neighbors = append(neighbors, p.overlayNeighbors(n, fabric, rrs)...)
 			// write isl interfaces
 			// we have to send per device since the ip addresses are unique
 			fileName = "interface-isl-" + nodeName + ".yaml"
-			p.WriteSrlInterface(&dirName,
+			if err := p.WriteSrlInterface(&dirName,
 				StringPtr(fileName),
 				StringPtr("infra-isl-interface"+nodeName),
 				StringPtr(nodeName),
-				islinterfaces)
+				islinterfaces); err != nil {
+				return nil, err
+			}
 			resources = append(resources, fileName)
 
 			// write isl subinterfaces
-			// we have to send per device since the ip addresses are unique
-			fileName = "subinterface-isl-" + islsubinterfaces[0].InterfaceShortName + "-" + nodeName + ".yaml"
-			p.WriteSrlSubInterface(&dirName,
-				StringPtr(fileName),
-				StringPtr("infra-isl-subinterface"+islsubinterfaces[0].InterfaceShortName+"-"+nodeName),
-				StringPtr(nodeName),
-				islsubinterfaces)
-			resources = append(resources, fileName)
+			// we have to send per device and interface since the ip addresses are unique
+			// and a resource holds the subinterfaces of a single interface
+			for _, islsubinterface := range islsubinterfaces {
+				fileName = "subinterface-isl-" + islsubinterface.InterfaceShortName + "-" + nodeName + ".yaml"
+				if err := p.WriteSrlSubInterface(&dirName,
+					StringPtr(fileName),
+					StringPtr("infra-isl-subinterface"+islsubinterface.InterfaceShortName+"-"+nodeName),
+					StringPtr(nodeName),
+					[]*k8ssrlsubinterface{islsubinterface}); err != nil {
+					return nil, err
+				}
+				resources = append(resources, fileName)
+			}
 
 			// write system0 subinterface
 			// we have to send per device since the ip addresses are unique
 			fileName = "subinterface-" + "system0" + "-" + nodeName + ".yaml"
-			p.WriteSrlSubInterface(&dirName,
+			if err := p.WriteSrlSubInterface(&dirName,
 				StringPtr(fileName),
 				StringPtr("infra-system0-subinterface"+"-"+nodeName),
 				StringPtr(nodeName),
-				systemsubinterfaces)
+				systemsubinterfaces); err != nil {
+				return nil, err
+			}
 			resources = append(resources, fileName)
 
 			defaultNetworkInstance := &k8ssrlNetworkInstance{
@@ -304,73 +358,94 @@ func (p *Parser) WriteInfrastructure() (kuztomizedirs []string) {
 			// write network instance default
 			// we assume symetric config, so we send to all devices at once
 			fileName = "network-instance-default" + "-" + nodeName + ".yaml"
-			p.WriteSrlNetworkInstance(&dirName,
+			if err := p.WriteSrlNetworkInstance(&dirName,
 				StringPtr(fileName),
 				StringPtr("infra-default-network-instance"+"-"+nodeName),
 				StringPtr(nodeName), // we send it to all leafs at once assuming the configuration is symmetric
-				defaultNetworkInstance)
+				defaultNetworkInstance); err != nil {
+				return nil, err
+			}
 			resources = append(resources, fileName)
 
-			peerGroups := make([]*PeerGroup, 0)
-			switch *p.Config.Infrastructure.AddressingSchema {
-			case "dual-stack":
-				underlayPeerGroup := &PeerGroup{
-					Name:       "underlay",
-					PolicyName: "export-underlay-local",
-					Protocols:  []string{"ipv4-unicast", "ipv6-unicast"},
-				}
-				peerGroups = append(peerGroups, underlayPeerGroup)
-			case "v4-only":
-				underlayPeerGroup := &PeerGroup{
-					Name:      "underlay",
-					Protocols: []string{"ipv4-unicast"},
-				}
-				peerGroups = append(peerGroups, underlayPeerGroup)
-			case "v6-only":
-				underlayPeerGroup := &PeerGroup{
-					Name:      "underlay",
-					Protocols: []string{"ipv6-unicast"},
+			if p.igpUnderlay() {
+				// write the igp of the default network instance
+				igp := p.igpProtocol(n, islsubinterfaces)
+				igp.Bfd = session != nil && session.BfdInterval != 0
+				fileName = "protocols-" + p.underlayProtocol() + "-default-" + nodeName + ".yaml"
+				if err := p.WriteSrlProtocolsIgp(&dirName,
+					StringPtr(fileName),
+					StringPtr("infra-default-protocols-"+p.underlayProtocol()+"-"+nodeName),
+					StringPtr(nodeName),
+					igp); err != nil {
+					return nil, err
 				}
-				peerGroups = append(peerGroups, underlayPeerGroup)
+				resources = append(resources, fileName)
 			}
 
-			if p.Config.Infrastructure.Protocols.OverlayProtocol != nil && *p.Config.Infrastructure.Protocols.OverlayProtocol != "" {
-				overlayPeerGroup := &PeerGroup{
-					Name:      "overlay",
-					Protocols: []string{*p.Config.Infrastructure.Protocols.OverlayProtocol},
+			peerGroups := make([]*PeerGroup, 0)
+			// with an igp underlay the overlay group is the only group
+			if !p.igpUnderlay() {
+				peerGroups = append(peerGroups, p.underlayPeerGroups()...)
+			}
+			peerGroups = p.overlayPeerGroups(nodeName, peerGroups, rrs)
+			setPeerGroupSessions(peerGroups, session)
+
+			// bfd runs on the isls of the single-hop underlay sessions or the igp
+			if session != nil && session.BfdInterval != 0 {
+				bfd := &k8ssrlBfd{Session: session}
+				for _, si := range islsubinterfaces {
+					bfd.SubInterfaces = append(bfd.SubInterfaces, si.InterfaceRealName+"."+si.VlanID)
 				}
-				peerGroups = append(peerGroups, overlayPeerGroup)
+				fileName = "bfd-isl-" + nodeName + ".yaml"
+				if err := p.WriteSrlBfd(&dirName,
+					StringPtr(fileName),
+					StringPtr("infra-isl-bfd-"+nodeName),
+					StringPtr(nodeName),
+					bfd); err != nil {
+					return nil, err
+				}
+				resources = append(resources, fileName)
 			}
 
 			defaultProtocolBgp := &k8ssrlprotocolsbgp{
 				NetworkInstanceName: "default",
 				AS:                  *n.AS,
-				RouterID:            *n.Endpoints["lo0"].IPv4Address,
+				RouterID:            routerID(n),
+				AddressFamilies:     addressFamilies(p.infraSchema()),
 				PeerGroups:          peerGroups,
 				Neighbors:           neighbors,
+				DynamicNeighbors:    dynamicNeighbors,
 			}
 
 			// TODO Add Policies
 			// write protocols bgp
 			fileName = "protocols-bgp-default" + nodeName + ".yaml"
-			p.WriteSrlProtocolsBgp(&dirName,
+			if err := p.WriteSrlProtocolsBgp(&dirName,
 				StringPtr(fileName),
 				StringPtr("infra-default-protocols-bgp"+nodeName),
 				StringPtr(nodeName),
-				defaultProtocolBgp)
+				defaultProtocolBgp); err != nil {
+				return nil, err
+			}
 			resources = append(resources, fileName)
 		}
 	}
-	p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources)
-	return kuztomizedirs
+	if err := p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources); err != nil {
+		return nil, err
+	}
+	return kuztomizedirs, nil
 }
 
-func (p *Parser) WriteClientsGroups() (kuztomizedirs []string) {
+func (p *Parser) WriteClientsGroups() ([]string, error) {
+	var kuztomizedirs []string
 	log.Infof("Writing Client group k8s yaml objects...")
 
-	for cgName, clients := range p.ClientGroups {
+	for _, cgName := range SortedKeys(p.ClientGroups) {
+		clients := p.ClientGroups[cgName]
 		dirName := filepath.Join(*p.BaseSwitchDir, "client-"+cgName)
-		p.CreateDirectory(dirName, 0777)
+		if err := p.createSwitchDirectory(dirName); err != nil {
+			return nil, err
+		}
 
 		kuztomizedirs = append(kuztomizedirs, "../client-"+cgName)
 
@@ -379,7 +454,8 @@ func (p *Parser) WriteClientsGroups() (kuztomizedirs []string) {
 		// we add all clientinterfaces to a list which we write at the end of the loop
 		// to the respective file/directory
 
-		for nodeName, itfces := range clients.Interfaces {
+		for _, nodeName := range SortedKeys(clients.Interfaces) {
+			itfces := clients.Interfaces[nodeName]
 			if nodeName != *clients.TargetGroup {
 				clientInterfaces := make([]*k8ssrlinterface, 0)
 				for _, itfce := range itfces {
@@ -433,11 +509,13 @@ func (p *Parser) WriteClientsGroups() (kuztomizedirs []string) {
 				// write client interfaces
 				// we need to write per device since pxe/lacp-fallback is different per node
 				fileName := "interface-cg-" + cgName + "-" + nodeName + ".yaml"
-				p.WriteSrlInterface(&dirName,
+				if err := p.WriteSrlInterface(&dirName,
 					StringPtr(fileName),
 					StringPtr("cg-"+cgName+"-"+"interface-"+nodeName),
 					StringPtr(nodeName),
-					clientInterfaces)
+					clientInterfaces); err != nil {
+					return nil, err
+				}
 				resources = append(resources, fileName)
 			} else {
 				// Target group Name
@@ -467,27 +545,35 @@ func (p *Parser) WriteClientsGroups() (kuztomizedirs []string) {
 					// TODO we need to make this more flexible and have a resource per client group
 					// so split in infra part + client group part
 					fileName := "system-network-instance-" + cgName + ".yaml"
-					p.WriteSrlSystemNetworkInstance(&dirName,
+					if err := p.WriteSrlSystemNetworkInstance(&dirName,
 						StringPtr(fileName),
 						StringPtr("cg-"+cgName+"-"+"system-network-instance-"+nodeName),
 						StringPtr(*clients.TargetGroup),
-						esis)
+						esis); err != nil {
+						return nil, err
+					}
 					resources = append(resources, fileName)
 				}
 			}
 		}
-		p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources)
+		if err := p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources); err != nil {
+			return nil, err
+		}
 	}
-	return kuztomizedirs
+	return kuztomizedirs, nil
 }
 
-func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
+func (p *Parser) WriteWorkloads() ([]string, error) {
+	var kuztomizedirs []string
 	log.Infof("Writing workload k8s yaml objects...")
 
-	for wlName, clients := range p.Config.Workloads {
+	for _, wlName := range SortedKeys(p.Config.Workloads) {
+		clients := p.Config.Workloads[wlName]
 		log.Debugf("Workload Name: %s", wlName)
 		dirName := filepath.Join(*p.BaseSwitchDir, "workload-"+wlName)
-		p.CreateDirectory(dirName, 0777)
+		if err := p.createSwitchDirectory(dirName); err != nil {
+			return nil, err
+		}
 		kuztomizedirs = append(kuztomizedirs, "../workload-"+wlName)
 
 		// subinterface vxlan
@@ -504,12 +590,21 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 		niIrbSubInterfaces := make(map[string]map[int][]*k8ssrlsubinterface)
 		niCsiSubInterfaces := make(map[string]map[int][]*k8ssrlsubinterface)
 		networkInstance := make(map[string]map[int]*k8ssrlNetworkInstance)
+		// bgp neighbors towards the gateways
+		// first (string) key represents node name, 2nd key represents the VlanId or network instance Id
+		gwNeighbors := make(map[string]map[int][]*Neighbor)
+		// addressing schema of the network towards the gateways, the key is the VlanId
+		gwSchemas := make(map[int]string)
+		// settings of the bgp sessions towards the gateways, the key is the VlanId
+		gwSessions := make(map[int]*bgpSession)
 
 		// records the target group, such that we can write to the target group for the resources that allow it
 		var targetGroup string
-		for cgName, wlInfo := range clients {
+		for _, cgName := range SortedKeys(clients) {
+			wlInfo := clients[cgName]
 			// netwType = itfce, ipvlan, sriov; netwInfo:
-			for netwType, netwInfo := range wlInfo.Itfces {
+			for _, netwType := range SortedKeys(wlInfo.Itfces) {
+				netwInfo := wlInfo.Itfces[netwType]
 				// used for vxlan write operation, so that we can send it to all devices in the group at once
 				targetGroup = *p.ClientGroups[cgName].TargetGroup
 				switch netwType {
@@ -519,7 +614,8 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 
 						// no irb interface required for bridged networks
 
-						for nodeName, itfces := range p.ClientGroups[cgName].Interfaces {
+						for _, nodeName := range SortedKeys(p.ClientGroups[cgName].Interfaces) {
+							itfces := p.ClientGroups[cgName].Interfaces[nodeName]
 							// client interfaces are implemented individually per node
 							if nodeName != targetGroup {
 								if _, ok := vxlanSubInterfaces[nodeName]; !ok {
@@ -527,7 +623,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								}
 								vxlanSubInterface := &k8ssrlVxlanInterface{
 									TunnelInterfaceName: "vxlan0",
-									VlanID:              strconv.Itoa(*netwInfo.VlanID),
+									Index:               strconv.Itoa(vxlanIndex("bridged", *netwInfo.VlanID)),
 									Kind:                "bridged",
 								}
 								vxlanSubInterfaces[nodeName] = append(vxlanSubInterfaces[nodeName], vxlanSubInterface)
@@ -592,7 +688,8 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 					case "routed":
 						// bridged part of the config, also the irb part
 
-						for nodeName, itfces := range p.ClientGroups[cgName].Interfaces {
+						for _, nodeName := range SortedKeys(p.ClientGroups[cgName].Interfaces) {
+							itfces := p.ClientGroups[cgName].Interfaces[nodeName]
 							// client interfaces are implemented individually per node
 							if nodeName != targetGroup {
 								if _, ok := vxlanSubInterfaces[nodeName]; !ok {
@@ -600,7 +697,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								}
 								vxlanSubInterface := &k8ssrlVxlanInterface{
 									TunnelInterfaceName: "vxlan0",
-									VlanID:              strconv.Itoa(*netwInfo.VlanID),
+									Index:               strconv.Itoa(vxlanIndex("routed", *netwInfo.VlanID)),
 									Kind:                "routed",
 								}
 								vxlanSubInterfaces[nodeName] = append(vxlanSubInterfaces[nodeName], vxlanSubInterface)
@@ -619,7 +716,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 										Name:                niName,
 										Kind:                "ip-vrf",
 										Type:                "routed",
-										TunnelInterfaceName: "vxlan0" + "." + strconv.Itoa(*netwInfo.VlanID),
+										TunnelInterfaceName: "vxlan0" + "." + strconv.Itoa(vxlanIndex("routed", *netwInfo.VlanID)),
 										RouteTarget:         "target:" + strconv.Itoa(int(*p.Config.Infrastructure.Protocols.OverlayAs)) + ":" + strconv.Itoa(*netwInfo.VlanID),
 										Evi:                 evi,
 									}
@@ -657,27 +754,34 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 										if foundA || foundB {
 											log.Debugf("Link Found")
 											ipamName := wlName + cgName + strconv.Itoa(*netwInfo.VlanID)
-											var ipv4Cidr *string
-											var ipv6Cidr *string
-											for i := 0; i < len(netwInfo.Ipv4Cidr); i++ {
-												ipv4Cidr = netwInfo.Ipv4Cidr[i]
-												ipv6Cidr = netwInfo.Ipv6Cidr[i]
-												if err := p.IPAM[ipamName].IPAMAllocateLinkPrefix(link, ipv4Cidr, ipv6Cidr); err != nil {
-													log.Error(err)
-												}
-												if foundA {
-													ipv4prefix = *link.A.IPv4Prefix
-													ipv6prefix = *link.A.IPv6Prefix
-													log.Debugf("IP Address: %s %s %s", *link.A.IPv4Prefix, *link.A.RealName, *link.B.RealName)
-												}
-												if foundB {
-													ipv4prefix = *link.A.IPv4Prefix
-													ipv6prefix = *link.A.IPv6Prefix
-													log.Debugf("IP Address: %s %s %s", *link.B.IPv4Prefix, *link.B.RealName, *link.A.RealName)
+											// only the families of the addressing schema of the network are allocated
+											ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.networkSchema(netwInfo), netwInfo)
+											for _, cidrs := range cidrPairs(ipv4Cidrs, ipv6Cidrs) {
+												if err := p.IPAM[ipamName].IPAMAllocateLinkPrefix(link, cidrs[0], cidrs[1]); err != nil {
+													return nil, err
 												}
+												// the leaf may be either end of the link
+												ipv4prefix = endpointPrefix(itfce.Endpoint.IPv4Address, itfce.Endpoint.IPv4PrefixLength)
+												ipv6prefix = endpointPrefix(itfce.Endpoint.IPv6Address, itfce.Endpoint.IPv6PrefixLength)
+												log.Debugf("IP Address: %s %s", ipv4prefix, *itfce.Endpoint.RealName)
 											}
 										} else {
-											log.Fatalf("Link Not found")
+											return nil, &TopologyError{Element: "endpoint " + *itfce.Endpoint.ShortName, Msg: "no link found for client interface"}
+										}
+										// the gateway side of the link is written by the renderer of the gateway
+										if gw, ok := p.switchRenderer(*itfce.Endpoint.PeerNode.Kind).(gatewayRenderer); ok {
+											if _, ok := gwNeighbors[nodeName]; !ok {
+												gwNeighbors[nodeName] = make(map[int][]*Neighbor)
+											}
+											session, err := p.bgpSession("workloads."+wlName+"."+cgName+".itfces."+netwType+".bgp_sessions",
+												p.workloadSessions(netwInfo), workloadKeychain(wlName, netwInfo))
+											if err != nil {
+												return nil, err
+											}
+											gwSchemas[*netwInfo.VlanID] = p.networkSchema(netwInfo)
+											gwSessions[*netwInfo.VlanID] = session
+											gwNeighbors[nodeName][*netwInfo.VlanID] = append(gwNeighbors[nodeName][*netwInfo.VlanID],
+												gw.GatewayLink(wlName, netwInfo, itfce.Endpoint.Node, itfce.Endpoint, link, ipv4prefix, ipv6prefix, session)...)
 										}
 
 										//avoids using the srl long interface name with the ethernet-1/50
@@ -717,7 +821,8 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 					case "irb":
 						// bridged part of the config
 
-						for nodeName, itfces := range p.ClientGroups[cgName].Interfaces {
+						for _, nodeName := range SortedKeys(p.ClientGroups[cgName].Interfaces) {
+							itfces := p.ClientGroups[cgName].Interfaces[nodeName]
 							// client interfaces are implemented individually per node
 							if nodeName != targetGroup {
 								if _, ok := vxlanSubInterfaces[nodeName]; !ok {
@@ -725,7 +830,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								}
 								vxlanSubInterface := &k8ssrlVxlanInterface{
 									TunnelInterfaceName: "vxlan0",
-									VlanID:              strconv.Itoa(*netwInfo.VlanID),
+									Index:               strconv.Itoa(vxlanIndex("bridged", *netwInfo.VlanID)),
 									Kind:                "bridged",
 								}
 								vxlanSubInterfaces[nodeName] = append(vxlanSubInterfaces[nodeName], vxlanSubInterface)
@@ -794,25 +899,21 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 									irbSubInterfaces[nodeName] = make([]*k8ssrlirbsubinterface, 0)
 								}
 
-								var ipv4Cidr *string
-								var ipv6Cidr *string
-								var ipv4prefixlist []string
-								var ipv6prefixlist []string
-								for i := 0; i < len(netwInfo.Ipv4Cidr); i++ {
-									ipv4Cidr = netwInfo.Ipv4Cidr[i]
-									ipv6Cidr = netwInfo.Ipv6Cidr[i]
-
-									ipv4prefixlist := make([]string, 0)
+								// the anycast gateway has the last address of the cidrs of the addressing schema
+								ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.networkSchema(netwInfo), netwInfo)
+								ipv4prefixlist := make([]string, 0, len(ipv4Cidrs))
+								for _, ipv4Cidr := range ipv4Cidrs {
 									ipv4prefix, err := getLastIPPrefixInCidr(ipv4Cidr)
 									if err != nil {
-										log.Fatal(err)
+										return nil, err
 									}
 									ipv4prefixlist = append(ipv4prefixlist, *ipv4prefix)
-
-									ipv6prefixlist := make([]string, 0)
+								}
+								ipv6prefixlist := make([]string, 0, len(ipv6Cidrs))
+								for _, ipv6Cidr := range ipv6Cidrs {
 									ipv6prefix, err := getLastIPPrefixInCidr(ipv6Cidr)
 									if err != nil {
-										log.Fatal(err)
+										return nil, err
 									}
 									ipv6prefixlist = append(ipv6prefixlist, *ipv6prefix)
 								}
@@ -851,7 +952,8 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 					}
 				case "sriov1", "sriov2":
 					// we assume sriov is always irb based
-					for nodeName, itfces := range p.ClientGroups[cgName].Interfaces {
+					for _, nodeName := range SortedKeys(p.ClientGroups[cgName].Interfaces) {
+						itfces := p.ClientGroups[cgName].Interfaces[nodeName]
 						// client interfaces are implemented individually per node
 						// we only add the interface if they belong to a target group in the netwInfo
 						if nodeName != targetGroup && netwInfo.Target != nil && *netwInfo.Target == nodeName {
@@ -860,7 +962,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 							}
 							vxlanSubInterface := &k8ssrlVxlanInterface{
 								TunnelInterfaceName: "vxlan0",
-								VlanID:              strconv.Itoa(*netwInfo.VlanID),
+								Index:               strconv.Itoa(vxlanIndex("bridged", *netwInfo.VlanID)),
 								Kind:                "bridged",
 							}
 							vxlanSubInterfaces[nodeName] = append(vxlanSubInterfaces[nodeName], vxlanSubInterface)
@@ -932,15 +1034,16 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 
 							}
 
+							ipv4Cidrs, ipv6Cidrs := schemaCidrs(p.networkSchema(netwInfo), netwInfo)
 							ipv4prefixlist := make([]string, 0)
 							ipv6prefixlist := make([]string, 0)
 							var ipNet *net.IPNet
 							var err error
 							//var ipNetList []net.IPNet
-							for i := 0; i < len(netwInfo.Ipv4Cidr); i++ {
-								_, ipNet, err = net.ParseCIDR(*netwInfo.Ipv4Cidr[i])
+							for _, ipv4Cidr := range ipv4Cidrs {
+								_, ipNet, err = net.ParseCIDR(*ipv4Cidr)
 								if err != nil {
-									log.Fatal(err)
+									return nil, err
 								}
 								/*
 									ipNetList, err = ipam.Split(*ipNet, 8)
@@ -958,13 +1061,14 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								*/
 								ipv4prefix, err := getLastIPPrefixInIPnet(*ipNet)
 								if err != nil {
-									log.Fatal(err)
+									return nil, err
 								}
 								ipv4prefixlist = append(ipv4prefixlist, *ipv4prefix)
-
-								_, ipNet, err = net.ParseCIDR(*netwInfo.Ipv6Cidr[i])
+							}
+							for _, ipv6Cidr := range ipv6Cidrs {
+								_, ipNet, err = net.ParseCIDR(*ipv6Cidr)
 								if err != nil {
-									log.Fatal(err)
+									return nil, err
 								}
 								/*
 									ipNetList, err = ipam.Split(*ipNet, 8)
@@ -982,7 +1086,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								*/
 								ipv6prefix, err := getLastIPPrefixInIPnet(*ipNet)
 								if err != nil {
-									log.Fatal(err)
+									return nil, err
 								}
 								ipv6prefixlist = append(ipv6prefixlist, *ipv6prefix)
 							}
@@ -1013,7 +1117,7 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 								AnycastGW:         false,
 								VrID:              10,
 								IPv4Prefix:        ipv4prefixlist,
-								//IPv6Prefix:        ipv6prefixlist,
+								IPv6Prefix:        ipv6prefixlist,
 							}
 							irbSubInterfaces[nodeName] = append(irbSubInterfaces[nodeName], irb)
 
@@ -1038,38 +1142,47 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 		resources := make([]string, 0)
 
 		// we have to create seperate files, since the interface is unique
-		for nodeName, clientSubInterface := range clientSubInterfaces {
+		for _, nodeName := range SortedKeys(clientSubInterfaces) {
+			clientSubInterface := clientSubInterfaces[nodeName]
 			if _, ok := vxlanSubInterfaces[nodeName]; ok {
 				fileName := "vxlaninterface" + "-" + "vxlan0" + "-" + nodeName + ".yaml"
-				p.WriteSrlVxlanInterface(&dirName,
+				if err := p.WriteSrlVxlanInterface(&dirName,
 					StringPtr(fileName),
 					StringPtr(wlName+"-vxlaninterface-"+"vxlan0"+"-"+nodeName),
 					StringPtr(nodeName),
-					vxlanSubInterfaces[nodeName])
+					vxlanSubInterfaces[nodeName]); err != nil {
+					return nil, err
+				}
 				resources = append(resources, fileName)
 			}
 
-			for itfceName, csi := range clientSubInterface {
+			for _, itfceName := range SortedKeys(clientSubInterface) {
+				csi := clientSubInterface[itfceName]
 				fileName := "subinterface" + "-" + itfceName + "-" + nodeName + ".yaml"
-				p.WriteSrlSubInterface(&dirName,
+				if err := p.WriteSrlSubInterface(&dirName,
 					StringPtr(fileName),
 					StringPtr(wlName+"-subinterface-"+itfceName+"-"+nodeName),
 					StringPtr(nodeName),
-					csi)
+					csi); err != nil {
+					return nil, err
+				}
 				resources = append(resources, fileName)
 			}
 			if _, ok := irbSubInterfaces[nodeName]; ok {
 				fileName := "subinterface" + "-" + "irb0" + "-" + nodeName + ".yaml"
-				p.WriteSrlIrbSubInterface(&dirName,
+				if err := p.WriteSrlIrbSubInterface(&dirName,
 					StringPtr(fileName),
 					StringPtr(wlName+"-subinterface-"+"irb0"+"-"+nodeName),
 					StringPtr(nodeName),
-					irbSubInterfaces[nodeName])
+					irbSubInterfaces[nodeName]); err != nil {
+					return nil, err
+				}
 				resources = append(resources, fileName)
 			}
 
 			if _, ok := networkInstance[nodeName]; ok {
-				for id, niInfo := range networkInstance[nodeName] {
+				for _, id := range SortedIntKeys(networkInstance[nodeName]) {
+					niInfo := networkInstance[nodeName][id]
 					log.Debugf("NetworkInstance Info: %s, %v %v", nodeName, id, niInfo)
 					switch niInfo.Type {
 					case "bridged":
@@ -1078,7 +1191,8 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 					case "routed":
 						niInfo.SubInterfaces = append(niInfo.SubInterfaces, niCsiSubInterfaces[nodeName][id]...)
 						// add all irb interfaces of this workload to the routed interface/IPvrf
-						for _, irb := range niIrbSubInterfaces[nodeName] {
+						for _, vlanID := range SortedIntKeys(niIrbSubInterfaces[nodeName]) {
+							irb := niIrbSubInterfaces[nodeName][vlanID]
 							niInfo.SubInterfaces = append(niInfo.SubInterfaces, irb...)
 						}
 					case "irb":
@@ -1086,11 +1200,13 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 						niInfo.SubInterfaces = append(niInfo.SubInterfaces, niIrbSubInterfaces[nodeName][id]...)
 					}
 					fileName := "network-instance-" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
-					p.WriteSrlNetworkInstance(&dirName,
+					if err := p.WriteSrlNetworkInstance(&dirName,
 						StringPtr(fileName),
 						StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-network-instance"+"-"+nodeName),
 						StringPtr(nodeName),
-						niInfo)
+						niInfo); err != nil {
+						return nil, err
+					}
 					resources = append(resources, fileName)
 
 					if strings.Contains(niInfo.Name, "provisioning") {
@@ -1098,32 +1214,105 @@ func (p *Parser) WriteWorkloads() (kuztomizedirs []string) {
 					}
 
 					fileName = "network-instance-protocol-bgpvpn" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
-					p.WriteSrlNetworkInstanceBgpVpn(&dirName,
+					if err := p.WriteSrlNetworkInstanceBgpVpn(&dirName,
 						StringPtr(fileName),
 						StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-protocolbgpvpn"+"-"+nodeName),
 						StringPtr(nodeName),
-						niInfo)
+						niInfo); err != nil {
+						return nil, err
+					}
 					resources = append(resources, fileName)
 
 					fileName = "network-instance-protocol-bgpevpn" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
-					p.WriteSrlNetworkInstanceBgpEvpn(&dirName,
+					if err := p.WriteSrlNetworkInstanceBgpEvpn(&dirName,
 						StringPtr(fileName),
 						StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-protocolbgpevpn"+"-"+nodeName),
 						StringPtr(nodeName),
-						niInfo)
+						niInfo); err != nil {
+						return nil, err
+					}
 					resources = append(resources, fileName)
 
 					fileName = "network-instance-protocol-linux" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
-					p.WriteSrlNetworkInstanceLinux(&dirName,
+					if err := p.WriteSrlNetworkInstanceLinux(&dirName,
 						StringPtr(fileName),
 						StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-protocollinux"+"-"+nodeName),
 						StringPtr(nodeName),
-						niInfo)
+						niInfo); err != nil {
+						return nil, err
+					}
 					resources = append(resources, fileName)
+
+					// ebgp towards the gateways in the ip-vrf
+					if neighbors, ok := gwNeighbors[nodeName][id]; ok && niInfo.Type == "routed" {
+						protocolBgp := &k8ssrlprotocolsbgp{
+							NetworkInstanceName: niInfo.Name,
+							AS:                  *p.Nodes[nodeName].AS,
+							RouterID:            routerID(p.Nodes[nodeName]),
+							AddressFamilies:     addressFamilies(gwSchemas[id]),
+							PeerGroups: []*PeerGroup{{
+								Name:      "dcgw",
+								Protocols: addressFamilies(gwSchemas[id]),
+								Session:   gwSessions[id],
+							}},
+							Neighbors: neighbors,
+						}
+						// the cnf routes of the workload towards the gateways
+						if rp := p.workloadRoutingPolicy(wlName, id, gwSchemas[id]); rp != nil {
+							protocolBgp.PeerGroups[0].PolicyName = rp.Policies[0].Name
+							protocolBgp.PeerGroups[0].ImportPolicyName = rp.Policies[1].Name
+							fileName = "routing-policy-" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
+							if err := p.WriteSrlRoutingPolicy(&dirName,
+								StringPtr(fileName),
+								StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-routing-policy"+"-"+nodeName),
+								StringPtr(nodeName),
+								rp); err != nil {
+								return nil, err
+							}
+							resources = append(resources, fileName)
+						}
+						if session := gwSessions[id]; session != nil && session.Keychain != "" && session.Keychain != infraKeychain {
+							fileName = "keychain-" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
+							if err := p.WriteSrlKeychain(&dirName,
+								StringPtr(fileName),
+								StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-keychain"+"-"+nodeName),
+								StringPtr(nodeName),
+								session); err != nil {
+								return nil, err
+							}
+							resources = append(resources, fileName)
+						}
+						if session := gwSessions[id]; session != nil && session.BfdInterval != 0 {
+							bfd := &k8ssrlBfd{Session: session}
+							for _, si := range niCsiSubInterfaces[nodeName][id] {
+								bfd.SubInterfaces = append(bfd.SubInterfaces, si.InterfaceRealName+"."+si.VlanID)
+							}
+							fileName = "bfd-" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
+							if err := p.WriteSrlBfd(&dirName,
+								StringPtr(fileName),
+								StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-bfd"+"-"+nodeName),
+								StringPtr(nodeName),
+								bfd); err != nil {
+								return nil, err
+							}
+							resources = append(resources, fileName)
+						}
+						fileName = "network-instance-protocol-bgp" + strconv.Itoa(id) + "-" + nodeName + ".yaml"
+						if err := p.WriteSrlProtocolsBgp(&dirName,
+							StringPtr(fileName),
+							StringPtr(wlName+"-"+strconv.Itoa(niInfo.Evi)+"-protocolbgp"+"-"+nodeName),
+							StringPtr(nodeName),
+							protocolBgp); err != nil {
+							return nil, err
+						}
+						resources = append(resources, fileName)
+					}
 				}
 			}
-			p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources)
+			if err := p.WriteKustomize(&dirName, StringPtr("kustomization.yaml"), resources); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return kuztomizedirs
+	return kuztomizedirs, nil
 }
@@ -1,7 +1,20 @@
 package parser
 
 import (
-	log "github.com/sirupsen/logrus"
+	"fmt"
+	"os"
+)
+
+// output directories, relative to the root of the output sink
+const (
+	switchDir       = "switch/kustomize"
+	switchConfigDir = "switch/config"
+	switchGnmiDir   = "switch/gnmi"
+	appValuesDir    = "app-values"
+	appKustomizeDir = "app-kustomize"
+	serverDir       = "server"
+	appIpamDir      = "app-ipam-csv"
+	srosDir         = "sros"
 )
 
 type Parser struct {
@@ -10,19 +23,29 @@ type Parser struct {
 	BaseAppKustomizesDir *string
 	BaseServerDir        *string
 	BaseAppIpamDir       *string
+	BaseSrosDir          *string
+	TemplateDir          *string
+	SwitchFormat         *string
+	Sink                 OutputSink
 	ConfigFile           *ConfigFile
 	Config               *Config
 	Nodes                map[string]*Node
 	Links                []*Link
 	IPAM                 map[string]*Ipam
+	IPAMState            *IPAMState
+	IPAMStateFile        *string
 	NextAS               *uint32
 	Workloads            map[string]*Workload
 	ClientGroups         map[string]*ClientGroup
+	// renderers holds the switch renderer per backend name
+	renderers map[string]switchRenderer
 	// DeploymentIPAM is a map where
 	// first string key = multusNetworkName
 	// 2nd Key string = ipvlan, sriov1, sriov2
 	// 3rd key string = IP subnet, could be v4 or v6
 	DeploymentIPAM map[string]map[string]map[string]*IpamApp
+	// AppConfigs holds the application configuration per enabled cnf
+	AppConfigs map[string]*AppConfig
 
 	// get the sriov and ipvlan networks and naming, etc
 	// clientLinks["ipvlan"] -> map with sriov/ipvlan -> interface name of the server (bond0 or bond1 or multiple bonds
@@ -40,47 +63,108 @@ type Parser struct {
 	ClientServer2NetworkLinks map[string]map[string]map[int]map[string][]*string
 	SwitchInfo                *switchInfo
 
+	// secrets resolves the secret references of the config
+	secrets SecretResolver
+	// renderSecrets writes the resolved keys instead of their placeholders
+	renderSecrets bool
+
 	debug bool
 }
 
-type ParserOption func(p *Parser)
+type ParserOption func(p *Parser) error
 
 // WithDebug initializes the debug flag
 func WithDebug(d bool) ParserOption {
-	return func(p *Parser) {
+	return func(p *Parser) error {
 		p.debug = d
+		return nil
 	}
 }
 
 // WithConfigFile initializes and marshals the config file
 func WithConfigFile(file *string) ParserOption {
-	return func(p *Parser) {
+	return func(p *Parser) error {
 		if *file == "" {
-			return
+			return nil
 		}
 		if err := p.GetConfig(file); err != nil {
-			log.Fatalf("failed to read topology file: %v", err)
+			return fmt.Errorf("failed to read topology file: %w", err)
 		}
+		return nil
+	}
+}
+
+// WithConfig initializes the parser with a parsed config
+func WithConfig(cfg *Config) ParserOption {
+	return func(p *Parser) error {
+		p.Config = cfg
+		return p.initConfig()
 	}
 }
 
-// WithOutput initializes the output variable
+// WithOutput writes the output files in the directory
 func WithOutput(o *string) ParserOption {
-	return func(p *Parser) {
-		p.BaseSwitchDir = StringPtr(*o + "/" + "switch/kustomize")
-		p.BaseAppValuesDir = StringPtr(*o + "/" + "app-values")
-		p.BaseAppKustomizesDir = StringPtr(*o + "/" + "app-kustomize")
-		p.BaseServerDir = StringPtr(*o + "/" + "server")
-		p.BaseAppIpamDir = StringPtr(*o + "/" + "app-ipam-csv")
+	return func(p *Parser) error {
+		p.Sink = NewDirSink(*o)
+		return nil
+	}
+}
+
+// WithIPAMState reads the ipam state file with the allocations of previous runs
+func WithIPAMState(file *string) ParserOption {
+	return func(p *Parser) (err error) {
+		if *file == "" {
+			return nil
+		}
+		p.IPAMStateFile = file
+		p.IPAMState, err = LoadIPAMState(*file)
+		if err != nil {
+			return fmt.Errorf("failed to read ipam state file: %w", err)
+		}
+		return nil
+	}
+}
+
+// WithTemplateDir initializes the template directory, the .tmpl files in its
+// app-helm, app-kustomize and server directories replace the built-in templates
+// with the same name
+func WithTemplateDir(dir *string) ParserOption {
+	return func(p *Parser) error {
+		if *dir == "" {
+			return nil
+		}
+		fi, err := os.Stat(*dir)
+		if err != nil {
+			return fmt.Errorf("failed to read template directory: %w", err)
+		}
+		if !fi.IsDir() {
+			return fmt.Errorf("template directory %s is not a directory", *dir)
+		}
+		p.TemplateDir = dir
+		return nil
+	}
+}
+
+// WithOutputSink writes the output files to the sink, e.g. a tarball or an
+// in-memory map, instead of the output directory
+func WithOutputSink(s OutputSink) ParserOption {
+	return func(p *Parser) error {
+		p.Sink = s
+		return nil
 	}
 }
 
 // NewParser function defines a new parser
-func NewParser(opts ...ParserOption) *Parser {
+func NewParser(opts ...ParserOption) (*Parser, error) {
 	p := &Parser{
-		BaseSwitchDir:        new(string),
-		BaseAppValuesDir:     new(string),
-		BaseAppKustomizesDir: new(string),
+		BaseSwitchDir:        StringPtr(switchDir),
+		BaseAppValuesDir:     StringPtr(appValuesDir),
+		BaseAppKustomizesDir: StringPtr(appKustomizeDir),
+		BaseServerDir:        StringPtr(serverDir),
+		BaseAppIpamDir:       StringPtr(appIpamDir),
+		BaseSrosDir:          StringPtr(srosDir),
+		SwitchFormat:         StringPtr(SwitchFormatK8s),
+		Sink:                 NewDirSink("out"),
 		Config:               new(Config),
 		ConfigFile:           new(ConfigFile),
 		Nodes:                make(map[string]*Node),
@@ -94,8 +178,11 @@ func NewParser(opts ...ParserOption) *Parser {
 		//ClientLinks:     make(map[string][]*ClientLinkInfo),
 		// key1: sriov or ipvlan, key2: ServerLogicalInterfacename (bond), key3: numa, key4: switch-name,value list of pfNames
 		ClientServer2NetworkLinks: make(map[string]map[string]map[int]map[string][]*string),
+		secrets:                   resolveSecret,
 	}
 
+	p.renderers = p.newSwitchRenderers()
+
 	// initialize the deployment IPAM, only use the ipvlan and sriov networks
 	p.SwitchInfo = &switchInfo{
 		switchesPerServer: new(int),
@@ -112,7 +199,9 @@ func NewParser(opts ...ParserOption) *Parser {
 	}
 
 	for _, o := range opts {
-		o(p)
+		if err := o(p); err != nil {
+			return nil, err
+		}
 	}
-	return p
+	return p, nil
 }
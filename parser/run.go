@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"context"
+)
+
+// Run parses the deployment and writes the switch, server and application
+// manifests to the output sink of the parser
+func (p *Parser) Run() error {
+	return p.run(context.Background())
+}
+
+// run executes the parser stages in order, the context is checked in between
+// the stages
+func (p *Parser) run(ctx context.Context) (err error) {
+	if p.Config.Infrastructure == nil {
+		return &ConfigError{Path: "infrastructure", Msg: "infrastructure is required"}
+	}
+	if err = p.checkIslAddressing(); err != nil {
+		return err
+	}
+	// initialize IPAM for the inter switch links (isl) links and elements,
+	// the unnumbered isls do not use an ipam
+	if !p.unnumberedIsl() {
+		if _, ok := p.Config.Infrastructure.Networks["isl"]; !ok {
+			return &ConfigError{Path: "infrastructure.networks.isl", Msg: "network is required"}
+		}
+		netwInfo := &NetworkInfo{
+			Kind:                  StringPtr("isl"),
+			AddressingSchema:      p.Config.Infrastructure.AddressingSchema,
+			Ipv4Cidr:              p.Config.Infrastructure.Networks["isl"].Ipv4Cidr,
+			Ipv4ItfcePrefixLength: p.Config.Infrastructure.Networks["isl"].Ipv4ItfcePrefixLength,
+			Ipv6Cidr:              p.Config.Infrastructure.Networks["isl"].Ipv6Cidr,
+			Ipv6ItfcePrefixLength: p.Config.Infrastructure.Networks["isl"].Ipv6ItfcePrefixLength,
+		}
+		if err = p.InitializeIPAM("isl", netwInfo); err != nil {
+			return err
+		}
+	}
+	// initialize IPAM for the loopbacks of the network elements
+	if _, ok := p.Config.Infrastructure.Networks["loopback"]; !ok {
+		return &ConfigError{Path: "infrastructure.networks.loopback", Msg: "network is required"}
+	}
+	netwInfo := &NetworkInfo{
+		Kind:                  StringPtr("loopback"),
+		AddressingSchema:      p.Config.Infrastructure.AddressingSchema,
+		Ipv4Cidr:              p.Config.Infrastructure.Networks["loopback"].Ipv4Cidr,
+		Ipv4ItfcePrefixLength: p.Config.Infrastructure.Networks["loopback"].Ipv4ItfcePrefixLength,
+		Ipv6Cidr:              p.Config.Infrastructure.Networks["loopback"].Ipv6Cidr,
+		Ipv6ItfcePrefixLength: p.Config.Infrastructure.Networks["loopback"].Ipv6ItfcePrefixLength,
+	}
+	if err = p.InitializeIPAM("loopback", netwInfo); err != nil {
+		return err
+	}
+
+	// Parse the topology part of the configuration
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = p.ParseTopology(); err != nil {
+		return err
+	}
+	//p.ShowTopology()
+
+	if err = p.ParseClientGroup(); err != nil {
+		return err
+	}
+	//p.ShowClientGroup()
+
+	if err = p.InitializeIPAMWorkloads(); err != nil {
+		return err
+	}
+
+	// Parse the workload part of the configuration
+	/*
+		if err = p.ParseWorkload(); err != nil {
+			return err
+		}
+		p.ShowWorkload()
+	*/
+
+	// Write the switch configuration in K8s
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = p.WriteBase(); err != nil {
+		return err
+	}
+	// holds a structure with all directories that are used by kustomize
+	var kdirs []string
+	for _, write := range []func() ([]string, error){
+		p.WriteInfrastructure,
+		p.WriteClientsGroups,
+		p.WriteWorkloads,
+	} {
+		kd, err := write()
+		if err != nil {
+			return err
+		}
+		kdirs = append(kdirs, kd...)
+	}
+	if err = p.WriteFinalBase(kdirs); err != nil {
+		return err
+	}
+	// e.g. the configuration per node and the gateway side of the routed workloads
+	if err = p.finishSwitchRenderers(); err != nil {
+		return err
+	}
+
+	//Write the server yaml files
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = p.ParseServerData(); err != nil {
+		return err
+	}
+
+	//Write the values.yaml file for the respective applications in k8s
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = p.ParseApplicationData(); err != nil {
+		return err
+	}
+
+	// persist the ipam allocations for the next run
+	p.recordDeploymentIPAM()
+	return p.WriteIPAMState()
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var testConfigs = []string{
+	"conf/paco-deployment.yaml",
+	"conf/paco-deployment-telenet-multinet.yaml",
+	"conf/paco-deployment-telenet-vlanawareapp.yaml",
+}
+
+func TestMain(m *testing.M) {
+	// the templates and configs are resolved relative to the repository root
+	if err := os.Chdir(".."); err != nil {
+		log.Fatal(err)
+	}
+	log.SetLevel(log.WarnLevel)
+	os.Exit(m.Run())
+}
+
+// runParser runs the parser for the config file into the output directory
+func runParser(t *testing.T, config, output string, opts ...ParserOption) {
+	t.Helper()
+	opts = append([]ParserOption{
+		WithConfigFile(StringPtr(config)),
+		WithOutput(StringPtr(output)),
+		WithIPAMState(StringPtr(filepath.Join(output, IPAMStateFileName))),
+	}, opts...)
+	p, err := NewParser(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// generateNodeJSON generates the first test config after the update in the SR
+// Linux json format, decodes the config of the node in v and returns the result
+func generateNodeJSON(t *testing.T, update func(cfg *Config), node string, v interface{}, opts ...ParserOption) *Result {
+	t.Helper()
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update != nil {
+		update(cfg)
+	}
+	res, err := Generate(context.Background(), cfg, append([]ParserOption{WithSwitchFormat(SwitchFormatJSON)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeNodeJSON(t, res, node, v)
+	return res
+}
+
+// decodeNodeJSON decodes the SR Linux json config of the node in v
+func decodeNodeJSON(t *testing.T, res *Result, node string, v interface{}) {
+	t.Helper()
+	b, ok := res.SwitchConfigs[node]
+	if !ok {
+		t.Fatalf("no config of %s", node)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTree returns the content of all files in the directory keyed by relative path
+func readTree(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel], err = ioutil.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func compareTrees(t *testing.T, want, got map[string][]byte) {
+	t.Helper()
+	for _, name := range SortedKeys(want) {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s: missing", name)
+			continue
+		}
+		if !bytes.Equal(want[name], got[name]) {
+			t.Errorf("%s: content differs", name)
+		}
+	}
+	for _, name := range SortedKeys(got) {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s: unexpected file", name)
+		}
+	}
+}
+
+func TestRunDeterministic(t *testing.T) {
+	for _, config := range testConfigs {
+		t.Run(filepath.Base(config), func(t *testing.T) {
+			first := t.TempDir()
+			runParser(t, config, first)
+			want := readTree(t, first)
+
+			// a fresh output directory without ipam state
+			second := t.TempDir()
+			runParser(t, config, second)
+			compareTrees(t, want, readTree(t, second))
+
+			// a re-run on the same output directory with ipam state
+			runParser(t, config, first)
+			compareTrees(t, want, readTree(t, first))
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the $id of the json schema of the paco deployment file
+const SchemaID = "https://raw.githubusercontent.com/nokia-paco-automation/paco-parser/main/paco-deployment.schema.json"
+
+// jsonSchema is the subset of json schema draft-07 that describes the deployment file
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AllOf                []*jsonSchema          `json:"allOf,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Definitions          map[string]*jsonSchema `json:"definitions,omitempty"`
+}
+
+// schemaEnums holds the allowed values of the string fields, keyed by type and yaml key
+var schemaEnums = map[string][]string{
+	"Infrastructure.addressing_schema":    addressingSchemas,
+	"NetworkInfo.addressing_schema":       addressingSchemas,
+	"Infrastructure.isl_addressing":       islAddressings,
+	"PacoDeploymentInfo.connectivitymode": {"multiNet", "vlanAwareApp"},
+	"CnfInfo.deployment":                  {"ntok", "1to1"},
+	"PacoNetworkInfo.type":                {"ipvlan", "sriov"},
+	"Protocols.protocol":                  underlayProtocols,
+	"Protocols.isis_level":                isisLevels,
+	"Protocols.overlay_protocol":          {"evpn"},
+	"Protocols.overlay_design":            overlayDesigns,
+	"NodeConfig.position":                 {"network", "access"},
+	"BgpAuth.type":                        bgpAuthTypes,
+}
+
+// schemaDescriptions documents the fields of the deployment file, keyed by type and yaml key
+var schemaDescriptions = map[string]string{
+	"Config.name":                         "name of the paco deployment",
+	"Config.credentials":                  "credentials to access the servers",
+	"Config.topology":                     "nodes and links of the fabric and the servers",
+	"Config.cluster":                      "k8s cluster the cnfs are deployed on",
+	"Config.container_registry":           "registry with the cnf images",
+	"Config.infrastructure":               "addressing and protocols of the fabric",
+	"Config.workloads":                    "networks per workload and server group",
+	"Config.application":                  "paco application and cnf parameters",
+	"Config.appnetwindexes":               "network indexes per workload, itfce or loopback and cnf",
+	"Infrastructure.addressing_schema":    "ip address families of the fabric: the loopbacks, isls, underlay and overlay sessions",
+	"Infrastructure.isl_addressing":       "numbered isls with a prefix of the isl network (default), or unnumbered isls with bgp over the ipv6 link-local addresses",
+	"Protocols.as_pool":                   "AS numbers the switches are allocated from",
+	"Protocols.protocol":                  "underlay of the fabric: ebgp on the isl subinterfaces, or isis or ospf (OSPFv3) with iBGP in the overlay_as",
+	"Protocols.isis_area":                 "IS-IS area of the net, 49.0001 by default",
+	"Protocols.ospf_area":                 "OSPF area of the isl subinterfaces and system0, 0.0.0.0 by default",
+	"Protocols.hello_interval":            "igp hello interval in seconds on the isl subinterfaces",
+	"Protocols.hello_multiplier":          "igp hellos until the adjacency is down, the ospf dead interval is the hello interval times the multiplier",
+	"Protocols.overlay_design":            "full-mesh of the fabric nodes (default), route-reflector on the nodes with the label role: spine or rr: true, or ebgp over the underlay sessions",
+	"Protocols.summarize_loopbacks":       "advertise the loopbacks of the loopback cidrs by their supernets in the export policy of the underlay",
+	"Protocols.bgp_sessions":              "bfd, authentication, timers and prefix limit of the underlay and overlay groups, the default of the bgp sessions of the workloads",
+	"NetworkInfo.addressing_schema":       "ip address families of the network, the addressing_schema of the infrastructure when not set",
+	"NetworkInfo.bgp_sessions":            "bgp sessions towards the gateways of a routed itfce, the fields that are set override the bgp_sessions of the protocols",
+	"BgpSessions.bfd":                     "bfd on the isl subinterfaces, for the ebgp underlay or the igp, and on the subinterfaces towards the gateways; the multi-hop overlay sessions run no bfd",
+	"BgpSessions.hold_time":               "hold time in seconds, 0 disables the keepalives",
+	"BgpSessions.keepalive_interval":      "keepalive interval in seconds, less than the hold time",
+	"BgpSessions.max_prefix":              "maximum number of routes received per address family",
+	"Bfd.interval":                        "transmit and receive interval in ms, 100 by default",
+	"Bfd.multiplier":                      "missed packets until the session is down, 3 by default",
+	"BgpAuth.type":                        "md5 (default) or tcp-ao",
+	"BgpAuth.secret":                      "reference to the key, env:NAME or file:PATH; the key is neither part of the deployment file nor of the output, which holds a ${VAR} placeholder of the key",
+	"NetworkInfo.ipv4_cidr":               "ipv4 prefixes the addresses are allocated from",
+	"NetworkInfo.ipv6_cidr":               "ipv6 prefixes the addresses are allocated from",
+	"NetworkInfo.target":                  "server group the network applies to",
+	"NodeConfig.kind":                     "kind of the node, e.g. srl, sros or linux",
+	"NodeConfig.type":                     "hardware type of the node, e.g. ixrd2 or sr-1s",
+	"NodeConfig.image":                    "container image of the node in a containerlab lab",
+	"NodeConfig.position":                 "network for the fabric, access for the servers",
+	"LinkConfig.endpoints":                "the two endpoints of the link as node:interface",
+	"PacoDeploymentInfo.connectivitymode": "how the cnfs connect to the fabric",
+	"CnfInfo.deployment":                  "ntok shares the lmg pods, 1to1 pairs them",
+	"CnfInfo.k":                           "number of redundant lmg pods",
+	"CnfInfo.pods":                        "pod parameters per pod type",
+	"PacoNetworkInfo.type":                "interface type of the cnf pods",
+	"MultusInfo.wl-name":                  "workload with the networks of the multus interface",
+}
+
+// ConfigSchema returns the json schema of the paco deployment file, derived
+// from the yaml tags of Config and the types it refers to
+func ConfigSchema() ([]byte, error) {
+	defs := make(map[string]*jsonSchema)
+	root := schemaFor(reflect.TypeOf(Config{}), defs)
+	s := &jsonSchema{
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		ID:          SchemaID,
+		Title:       "paco deployment",
+		Description: "paco deployment definition file of paco-parser",
+		Ref:         root.Ref,
+		Definitions: defs,
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// schemaFor returns the schema of the type, structs are added to the
+// definitions and referenced by name
+func schemaFor(t reflect.Type, defs map[string]*jsonSchema) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem(), defs)
+	case reflect.String:
+		// yaml decodes any scalar in a string, e.g. the labels true or 0
+		return &jsonSchema{Type: []string{"string", "number", "boolean"}}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer", Minimum: IntPtr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		ref := &jsonSchema{Ref: "#/definitions/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		s := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema),
+			AdditionalProperties: false,
+		}
+		// added before the fields are walked to stop at recursive types
+		defs[t.Name()] = s
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			// the fields without a yaml tag are filled in by the parser
+			key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+			fs := schemaFor(f.Type, defs)
+			if enum, ok := schemaEnums[t.Name()+"."+key]; ok {
+				fs.Type = "string"
+				fs.Enum = enum
+			}
+			if desc, ok := schemaDescriptions[t.Name()+"."+key]; ok {
+				// a $ref ignores its siblings in draft-07, so it is wrapped
+				if fs.Ref != "" {
+					fs = &jsonSchema{AllOf: []*jsonSchema{fs}}
+				}
+				fs.Description = desc
+			}
+			s.Properties[key] = fs
+		}
+		return ref
+	}
+	// interface{} values such as the pod parameters accept any value
+	return &jsonSchema{}
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const schemaFile = "paco-deployment.schema.json"
+
+func TestConfigSchemaFile(t *testing.T) {
+	b, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(schemaFile, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, b) {
+		t.Errorf("%s is out of date, run the tests with -update", schemaFile)
+	}
+}
+
+func TestConfigSchemaSamples(t *testing.T) {
+	b, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := new(jsonSchema)
+	if err := json.Unmarshal(b, schema); err != nil {
+		t.Fatal(err)
+	}
+	for _, config := range testConfigs {
+		yb, err := ioutil.ReadFile(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal(yb, &doc); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range checkSchema(schema, schema, doc, "") {
+			t.Errorf("%s: %s", config, e)
+		}
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte("infrastructure:\n  addressing_schema: v4-only\n  protocol: {}\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if errs := checkSchema(schema, schema, doc, ""); len(errs) != 2 {
+		t.Errorf("got errors %v, want an enum and an unknown field error", errs)
+	}
+}
+
+func TestParseConfigUnknownField(t *testing.T) {
+	b := []byte("topology:\n  nodes:\n    leaf1:\n      knd: srl\n")
+	if _, err := ParseConfig(b); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	file := filepath.Join(t.TempDir(), "paco.yaml")
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	verrs, err := ValidateConfig(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, verr := range verrs {
+		if verr.Line == 4 && verr.Message == `unknown field "knd"` {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("got %v, want an unknown field error on line 4", verrs)
+	}
+}
+
+// checkSchema checks a decoded yaml document against the subset of json schema
+// that ConfigSchema uses
+func checkSchema(root, s *jsonSchema, v interface{}, path string) []string {
+	if s.Ref != "" {
+		return checkSchema(root, root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")], v, path)
+	}
+	errs := make([]string, 0)
+	for _, sub := range s.AllOf {
+		errs = append(errs, checkSchema(root, sub, v, path)...)
+	}
+	types, ok := s.Type.([]interface{})
+	if !ok {
+		types = []interface{}{s.Type}
+	}
+	if len(types) > 1 {
+		// a string also accepts the other scalars
+		if _, ok := v.(map[interface{}]interface{}); ok {
+			errs = append(errs, fmt.Sprintf("%s: not a scalar", path))
+		}
+		if _, ok := v.([]interface{}); ok {
+			errs = append(errs, fmt.Sprintf("%s: not a scalar", path))
+		}
+		return errs
+	}
+	switch types[0] {
+	case "object":
+		m, ok := v.(map[interface{}]interface{})
+		if !ok {
+			if v != nil {
+				errs = append(errs, fmt.Sprintf("%s: not an object", path))
+			}
+			return errs
+		}
+		for k, val := range m {
+			key := fmt.Sprint(k)
+			if ps, ok := s.Properties[key]; ok {
+				errs = append(errs, checkSchema(root, ps, val, path+"/"+key)...)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					errs = append(errs, fmt.Sprintf("%s: unknown field %s", path, key))
+				}
+			case map[string]interface{}:
+				b, _ := json.Marshal(ap)
+				as := new(jsonSchema)
+				json.Unmarshal(b, as)
+				errs = append(errs, checkSchema(root, as, val, path+"/"+key)...)
+			}
+		}
+	case "array":
+		l, ok := v.([]interface{})
+		if !ok {
+			if v != nil {
+				errs = append(errs, fmt.Sprintf("%s: not an array", path))
+			}
+			return errs
+		}
+		for i, val := range l {
+			errs = append(errs, checkSchema(root, s.Items, val, fmt.Sprintf("%s/%d", path, i))...)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			if v != nil {
+				errs = append(errs, fmt.Sprintf("%s: not a string", path))
+			}
+			return errs
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			errs = append(errs, fmt.Sprintf("%s: %s not in %v", path, str, s.Enum))
+		}
+	case "integer":
+		if _, ok := v.(int); !ok && v != nil {
+			errs = append(errs, fmt.Sprintf("%s: not an integer", path))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok && v != nil {
+			errs = append(errs, fmt.Sprintf("%s: not a boolean", path))
+		}
+	}
+	return errs
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputSink is where the parser writes its output files, the paths are
+// relative to the root of the sink, e.g. switch/kustomize/kustomization.yaml
+type OutputSink interface {
+	// MkdirAll creates a directory, including the parent directories that do
+	// not exist yet
+	MkdirAll(path string, perm os.FileMode) error
+	// Create creates or truncates the file, the content is complete when the
+	// returned writer is closed
+	Create(name string) (io.WriteCloser, error)
+	// Close flushes the output, no files can be created afterwards
+	Close() error
+}
+
+// DirSink writes the output files in a local directory
+type DirSink struct {
+	Dir string
+}
+
+// NewDirSink returns a sink that writes the output files in the directory
+func NewDirSink(dir string) *DirSink {
+	return &DirSink{Dir: dir}
+}
+
+func (s *DirSink) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(filepath.Join(s.Dir, path), perm)
+}
+
+func (s *DirSink) Create(name string) (io.WriteCloser, error) {
+	return os.Create(filepath.Join(s.Dir, name))
+}
+
+func (s *DirSink) Close() error {
+	return nil
+}
+
+// MemSink keeps the output files in memory, such that library callers and
+// tests can inspect them or compare them with the output on disk
+type MemSink struct {
+	m     sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemSink returns an empty in-memory sink
+func NewMemSink() *MemSink {
+	return &MemSink{
+		files: make(map[string][]byte),
+	}
+}
+
+// MkdirAll is a no-op, directories exist implicitly in memory
+func (s *MemSink) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+func (s *MemSink) Create(name string) (io.WriteCloser, error) {
+	return newBufferedFile(name, func(name string, b []byte) error {
+		s.m.Lock()
+		defer s.m.Unlock()
+		s.files[name] = b
+		return nil
+	}), nil
+}
+
+func (s *MemSink) Close() error {
+	return nil
+}
+
+// Files returns the content of the output files keyed by path
+func (s *MemSink) Files() map[string][]byte {
+	s.m.Lock()
+	defer s.m.Unlock()
+	files := make(map[string][]byte, len(s.files))
+	for name, b := range s.files {
+		files[name] = b
+	}
+	return files
+}
+
+// TarSink writes the output files in a .tar.gz archive
+type TarSink struct {
+	m  sync.Mutex
+	w  io.WriteCloser
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+// NewTarSink returns a sink that writes a .tar.gz archive to w, the archive is
+// complete when the sink is closed
+func NewTarSink(w io.WriteCloser) *TarSink {
+	gz := gzip.NewWriter(w)
+	return &TarSink{
+		w:  w,
+		gz: gz,
+		tw: tar.NewWriter(gz),
+	}
+}
+
+// MkdirAll is a no-op, the directories are implied by the file paths in the archive
+func (s *TarSink) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+func (s *TarSink) Create(name string) (io.WriteCloser, error) {
+	return newBufferedFile(name, func(name string, b []byte) error {
+		s.m.Lock()
+		defer s.m.Unlock()
+		// a fixed modification time keeps the archive byte-stable across runs
+		if err := s.tw.WriteHeader(&tar.Header{
+			Name:     filepath.ToSlash(name),
+			Mode:     0644,
+			Size:     int64(len(b)),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			return err
+		}
+		_, err := s.tw.Write(b)
+		return err
+	}), nil
+}
+
+func (s *TarSink) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.tw.Close(); err != nil {
+		s.w.Close()
+		return err
+	}
+	if err := s.gz.Close(); err != nil {
+		s.w.Close()
+		return err
+	}
+	return s.w.Close()
+}
+
+// StreamSink writes the output files as a single --- separated yaml stream,
+// e.g. on stdout to pipe the manifests to kubectl apply -f -
+type StreamSink struct {
+	m sync.Mutex
+	w io.Writer
+	// Filter selects the files that are written to the stream, all files are
+	// written when it is nil
+	Filter func(path string) bool
+}
+
+// NewStreamSink returns a sink that writes the k8s manifests to w as a yaml stream
+func NewStreamSink(w io.Writer) *StreamSink {
+	return &StreamSink{
+		w:      w,
+		Filter: IsManifest,
+	}
+}
+
+// MkdirAll is a no-op, the stream has no directories
+func (s *StreamSink) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+func (s *StreamSink) Create(name string) (io.WriteCloser, error) {
+	return newBufferedFile(name, func(name string, b []byte) error {
+		if s.Filter != nil && !s.Filter(name) {
+			return nil
+		}
+		s.m.Lock()
+		defer s.m.Unlock()
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		_, err := fmt.Fprintf(s.w, "---\n# Source: %s\n%s", filepath.ToSlash(name), b)
+		return err
+	}), nil
+}
+
+func (s *StreamSink) Close() error {
+	return nil
+}
+
+// IsManifest returns true if the output file is a k8s manifest; the
+// kustomizations, helm values, csv files and the ipam state are not
+func IsManifest(name string) bool {
+	name = filepath.ToSlash(name)
+	if path.Ext(name) != ".yaml" {
+		return false
+	}
+	switch {
+	case path.Base(name) == "kustomization.yaml",
+		name == IPAMStateFileName,
+		strings.HasPrefix(name, appValuesDir+"/"):
+		return false
+	}
+	return true
+}
+
+// bufferedFile buffers the content of a file and hands it to the sink when it
+// is closed
+type bufferedFile struct {
+	bytes.Buffer
+	name  string
+	close func(name string, b []byte) error
+}
+
+func newBufferedFile(name string, close func(name string, b []byte) error) *bufferedFile {
+	return &bufferedFile{
+		name:  filepath.Clean(name),
+		close: close,
+	}
+}
+
+func (f *bufferedFile) Close() error {
+	return f.close(f.name, f.Bytes())
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// nopCloser turns a buffer into the writer of a tar sink
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestTarSink(t *testing.T) {
+	config := testConfigs[0]
+	mem := NewMemSink()
+	runParser(t, config, t.TempDir(), WithOutputSink(mem))
+
+	archives := make([][]byte, 0, 2)
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		sink := NewTarSink(nopCloser{&buf})
+		runParser(t, config, t.TempDir(), WithOutputSink(sink))
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, buf.Bytes())
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("archive differs across runs")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archives[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files[hdr.Name], err = ioutil.ReadAll(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	compareTrees(t, mem.Files(), files)
+}
+
+func TestStreamSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewStreamSink(&buf)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "server/sriovdp_cm.yaml", content: "kind: ConfigMap\n"},
+		{name: "switch/kustomize/kustomization.yaml", content: "resources: []\n"},
+		{name: "app-values/upf_values.yaml", content: "tags: {}\n"},
+		{name: "app-ipam-csv/paco-ipam-ipv4.csv", content: "wlName\n"},
+		{name: IPAMStateFileName, content: "allocations: []\n"},
+		{name: "app-kustomize/upf/statefulSet.yaml", content: "kind: StatefulSet"},
+	}
+	for _, f := range files {
+		w, err := sink.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, f.content); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := strings.Join([]string{
+		"---",
+		"# Source: server/sriovdp_cm.yaml",
+		"kind: ConfigMap",
+		"---",
+		"# Source: app-kustomize/upf/statefulSet.yaml",
+		"kind: StatefulSet",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
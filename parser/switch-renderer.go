@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// switchResource is a resource of the switch intent, the nodes it applies to
+// and the name and location of the resource in the output
+type switchResource struct {
+	DirName  string
+	FileName string
+	ResName  string
+	// Target is the node name or the target label of a group of nodes
+	Target string
+	// Nodes holds the nodes of the target that are rendered by the renderer
+	Nodes []string
+}
+
+// switchRenderer renders the vendor-neutral switch intent of the nodes of a
+// kind, the intent is computed by WriteInfrastructure, WriteClientsGroups and
+// WriteWorkloads
+type switchRenderer interface {
+	Interfaces(r *switchResource, interfaces []*k8ssrlinterface) error
+	SubInterfaces(r *switchResource, subinterfaces []*k8ssrlsubinterface) error
+	IrbSubInterfaces(r *switchResource, irbsubinterfaces []*k8ssrlirbsubinterface) error
+	TunnelInterfaces(r *switchResource, tunnelinterfaces []*k8ssrlTunnelInterface) error
+	VxlanInterfaces(r *switchResource, vxlaninterfaces []*k8ssrlVxlanInterface) error
+	NetworkInstance(r *switchResource, netwinstance *k8ssrlNetworkInstance) error
+	ProtocolsBgp(r *switchResource, protocolsbgp *k8ssrlprotocolsbgp) error
+	ProtocolsIgp(r *switchResource, protocolsigp *k8ssrlprotocolsigp) error
+	ESIs(r *switchResource, esis []*k8ssrlESI) error
+	BgpVpn(r *switchResource, netwinstance *k8ssrlNetworkInstance) error
+	BgpEvpn(r *switchResource, netwinstance *k8ssrlNetworkInstance) error
+	Linux(r *switchResource, netwinstance *k8ssrlNetworkInstance) error
+	RoutingPolicy(r *switchResource, routingPolicy *k8ssrlRoutingPolicy) error
+	Keychain(r *switchResource, session *bgpSession) error
+	Bfd(r *switchResource, bfd *k8ssrlBfd) error
+	// Finish writes the output that spans the resources, e.g. a configuration per node
+	Finish() error
+}
+
+// gatewayRenderer is implemented by the renderers of the kinds that act as a
+// data center gateway for the routed workloads
+type gatewayRenderer interface {
+	// GatewayLink records the gateway side of a routed link to a leaf with the
+	// settings of its bgp sessions and returns the eBGP neighbors of the leaf
+	// towards the gateway
+	GatewayLink(wlName string, netwInfo *NetworkInfo, leaf *Node, leafEp *Endpoint, link *Link, ipv4prefix, ipv6prefix string, session *bgpSession) []*Neighbor
+}
+
+// switchBackend is a vendor backend of the switch renderer
+type switchBackend struct {
+	// kinds holds the node kinds the backend renders
+	kinds []string
+	// templates holds the templates of the backend by name
+	templates   map[string]*template.Template
+	newRenderer func(p *Parser, templates map[string]*template.Template) switchRenderer
+}
+
+// switchBackends holds the registered backends by name
+var switchBackends = make(map[string]*switchBackend)
+
+// registerSwitchBackend registers a backend for the node kinds with its templates
+func registerSwitchBackend(name string, kinds []string, templates map[string]string, newRenderer func(p *Parser, templates map[string]*template.Template) switchRenderer) {
+	b := &switchBackend{
+		kinds:       kinds,
+		templates:   make(map[string]*template.Template),
+		newRenderer: newRenderer,
+	}
+	for tmplName, src := range templates {
+		b.templates[tmplName] = makek8sTemplate(tmplName, src)
+	}
+	switchBackends[name] = b
+}
+
+// defaultSwitchBackend renders the targets without nodes, e.g. a group whose
+// nodes are not defined in the topology
+const defaultSwitchBackend = "srl"
+
+// newSwitchRenderers returns a renderer per registered backend
+func (p *Parser) newSwitchRenderers() map[string]switchRenderer {
+	renderers := make(map[string]switchRenderer)
+	for name, b := range switchBackends {
+		renderers[name] = b.newRenderer(p, b.templates)
+	}
+	return renderers
+}
+
+// switchBackendName returns the name of the backend of a node kind
+func switchBackendName(kind string) (string, bool) {
+	for _, name := range SortedKeys(switchBackends) {
+		for _, k := range switchBackends[name].kinds {
+			if k == kind {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// switchRenderer returns the renderer of a node kind, nil if no backend renders the kind
+func (p *Parser) switchRenderer(kind string) switchRenderer {
+	name, ok := switchBackendName(kind)
+	if !ok {
+		return nil
+	}
+	return p.renderers[name]
+}
+
+// render hands a resource of the intent to the renderers of the nodes of the
+// target; a group target is split in a resource per backend
+func (p *Parser) render(dirName, fileName, resName, target *string, f func(r switchRenderer, res *switchResource) error) error {
+	nodes := make(map[string][]string)
+	if n, ok := p.Nodes[*target]; ok {
+		name, ok := switchBackendName(*n.Kind)
+		if !ok {
+			return &TopologyError{Element: "node " + *target, Msg: fmt.Sprintf("no switch renderer for kind %s", *n.Kind)}
+		}
+		nodes[name] = []string{*target}
+	} else {
+		for _, nodeName := range SortedKeys(p.Nodes) {
+			n := p.Nodes[nodeName]
+			if *n.Target != *target {
+				continue
+			}
+			// e.g. the servers of a group are not rendered
+			if name, ok := switchBackendName(*n.Kind); ok {
+				nodes[name] = append(nodes[name], nodeName)
+			}
+		}
+		if len(nodes) == 0 {
+			nodes[defaultSwitchBackend] = []string{}
+		}
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res := &switchResource{
+			DirName:  *dirName,
+			FileName: *fileName,
+			ResName:  *resName,
+			Target:   *target,
+			Nodes:    nodes[name],
+		}
+		if err := f(p.renderers[name], res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishSwitchRenderers writes the output of the renderers that spans the resources
+func (p *Parser) finishSwitchRenderers() error {
+	for _, name := range SortedKeys(p.renderers) {
+		if err := p.renderers[name].Finish(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsupportedRenderer is embedded by the renderers that render a part of the
+// intent, the other parts return an error
+type unsupportedRenderer struct {
+	kind string
+}
+
+func (u unsupportedRenderer) unsupported(what string, r *switchResource) error {
+	return &TopologyError{Element: "target " + r.Target, Msg: fmt.Sprintf("%s are not supported for kind %s", what, u.kind)}
+}
+
+func (u unsupportedRenderer) Interfaces(r *switchResource, _ []*k8ssrlinterface) error {
+	return u.unsupported("interfaces", r)
+}
+
+func (u unsupportedRenderer) SubInterfaces(r *switchResource, _ []*k8ssrlsubinterface) error {
+	return u.unsupported("subinterfaces", r)
+}
+
+func (u unsupportedRenderer) IrbSubInterfaces(r *switchResource, _ []*k8ssrlirbsubinterface) error {
+	return u.unsupported("irb subinterfaces", r)
+}
+
+func (u unsupportedRenderer) TunnelInterfaces(r *switchResource, _ []*k8ssrlTunnelInterface) error {
+	return u.unsupported("tunnel interfaces", r)
+}
+
+func (u unsupportedRenderer) VxlanInterfaces(r *switchResource, _ []*k8ssrlVxlanInterface) error {
+	return u.unsupported("vxlan interfaces", r)
+}
+
+func (u unsupportedRenderer) NetworkInstance(r *switchResource, _ *k8ssrlNetworkInstance) error {
+	return u.unsupported("network instances", r)
+}
+
+func (u unsupportedRenderer) ProtocolsBgp(r *switchResource, _ *k8ssrlprotocolsbgp) error {
+	return u.unsupported("bgp protocols", r)
+}
+
+func (u unsupportedRenderer) ProtocolsIgp(r *switchResource, _ *k8ssrlprotocolsigp) error {
+	return u.unsupported("igp protocols", r)
+}
+
+func (u unsupportedRenderer) ESIs(r *switchResource, _ []*k8ssrlESI) error {
+	return u.unsupported("ethernet segments", r)
+}
+
+func (u unsupportedRenderer) BgpVpn(r *switchResource, _ *k8ssrlNetworkInstance) error {
+	return u.unsupported("bgp-vpn protocols", r)
+}
+
+func (u unsupportedRenderer) BgpEvpn(r *switchResource, _ *k8ssrlNetworkInstance) error {
+	return u.unsupported("bgp-evpn protocols", r)
+}
+
+func (u unsupportedRenderer) Linux(r *switchResource, _ *k8ssrlNetworkInstance) error {
+	return u.unsupported("linux protocols", r)
+}
+
+func (u unsupportedRenderer) RoutingPolicy(r *switchResource, _ *k8ssrlRoutingPolicy) error {
+	return u.unsupported("routing policies", r)
+}
+
+func (u unsupportedRenderer) Keychain(r *switchResource, _ *bgpSession) error {
+	return u.unsupported("keychains", r)
+}
+
+func (u unsupportedRenderer) Bfd(r *switchResource, _ *k8ssrlBfd) error {
+	return u.unsupported("bfd sessions", r)
+}
+
+func (u unsupportedRenderer) Finish() error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+)
+
+// testRenderer records the interfaces it renders
+type testRenderer struct {
+	unsupportedRenderer
+	resources []*switchResource
+}
+
+func (r *testRenderer) Interfaces(res *switchResource, _ []*k8ssrlinterface) error {
+	r.resources = append(r.resources, res)
+	return nil
+}
+
+func TestSwitchRendererByKind(t *testing.T) {
+	registerSwitchBackend("test", []string{"test"}, nil, func(p *Parser, _ map[string]*template.Template) switchRenderer {
+		return &testRenderer{unsupportedRenderer: unsupportedRenderer{kind: "test"}}
+	})
+	defer delete(switchBackends, "test")
+
+	mem := NewMemSink()
+	p, err := NewParser(WithOutputSink(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, kind := range map[string]string{"leaf1": "srl", "leaf2": "srl", "gw1": "test", "server1": "linux"} {
+		p.Nodes[name] = &Node{ShortName: StringPtr(name), Kind: StringPtr(kind), Target: StringPtr("grp1")}
+	}
+
+	itfces := []*k8ssrlinterface{{Name: "ethernet-1/1", Kind: "access"}}
+	if err := p.WriteSrlInterface(StringPtr("infra"), StringPtr("interface.yaml"), StringPtr("itfce"), StringPtr("grp1"), itfces); err != nil {
+		t.Fatal(err)
+	}
+	// the srl nodes of the group share a single k8s resource
+	if _, ok := mem.Files()["infra/interface.yaml"]; !ok {
+		t.Errorf("got files %v, want infra/interface.yaml", SortedKeys(mem.Files()))
+	}
+	tr := p.renderers["test"].(*testRenderer)
+	if len(tr.resources) != 1 || len(tr.resources[0].Nodes) != 1 || tr.resources[0].Nodes[0] != "gw1" {
+		t.Errorf("got resources %v, want one for gw1", tr.resources)
+	}
+
+	// a node of a kind without a backend
+	if err := p.WriteSrlInterface(StringPtr("infra"), StringPtr("interface.yaml"), StringPtr("itfce"), StringPtr("server1"), itfces); err == nil {
+		t.Error("expected an error for a node without a switch renderer")
+	}
+	// the intent that the backend does not render
+	var terr *TopologyError
+	if err := p.WriteSrlRoutingPolicy(StringPtr("infra"), StringPtr("policy.yaml"), StringPtr("policy"), StringPtr("gw1"), &k8ssrlRoutingPolicy{}); !errors.As(err, &terr) {
+		t.Errorf("got %v, want a topology error", err)
+	}
+}
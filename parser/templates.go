@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/nokia-paco-automation/paco-parser/templates"
+)
+
+// templateFS returns the built-in templates followed by the template directory
+// of the user, if any, such that the files of the user replace the built-in ones
+func (p *Parser) templateFS() []fs.FS {
+	fsyss := []fs.FS{templates.FS}
+	if p.TemplateDir != nil {
+		fsyss = append(fsyss, os.DirFS(*p.TemplateDir))
+	}
+	return fsyss
+}
+
+// ParseTemplates parses all .tmpl files in the directory of the file systems into
+// a single template, a file in a later file system replaces the file with the same
+// path in an earlier file system
+func ParseTemplates(dir string, fsyss ...fs.FS) (*template.Template, error) {
+	files := make(map[string]fs.FS)
+	for _, fsys := range fsyss {
+		err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(name, ".tmpl") {
+				files[name] = fsys
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, &TemplateError{Template: dir, Err: errors.New("no .tmpl files found")}
+	}
+
+	templ := template.New("app").Funcs(templateHelperFunctions).Funcs(sprig.TxtFuncMap())
+	for _, name := range SortedKeys(files) {
+		b, err := fs.ReadFile(files[name], name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := templ.New(path.Base(name)).Parse(string(b)); err != nil {
+			return nil, &TemplateError{Template: name, Err: err}
+		}
+	}
+	return templ, nil
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseTemplates(t *testing.T) {
+	builtin := fstest.MapFS{
+		"server/a.tmpl": {Data: []byte("builtin a")},
+		"server/b.tmpl": {Data: []byte("builtin b")},
+		"server/c.txt":  {Data: []byte("not a template")},
+	}
+	user := fstest.MapFS{
+		"server/b.tmpl": {Data: []byte("user b")},
+		"server/d.tmpl": {Data: []byte("user d")},
+	}
+
+	templ, err := ParseTemplates("server", builtin, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"a.tmpl": "builtin a",
+		"b.tmpl": "user b",
+		"d.tmpl": "user d",
+	}
+	for name, content := range want {
+		var buf bytes.Buffer
+		if err := templ.ExecuteTemplate(&buf, name, nil); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != content {
+			t.Errorf("%s: got %q, want %q", name, buf.String(), content)
+		}
+	}
+	if templ.Lookup("c.txt") != nil {
+		t.Error("c.txt should not be parsed")
+	}
+}
+
+func TestParseTemplatesMissingDir(t *testing.T) {
+	// a user directory without the directory falls back to the built-in templates
+	builtin := fstest.MapFS{"server/a.tmpl": {Data: []byte("builtin a")}}
+	if _, err := ParseTemplates("server", builtin, fstest.MapFS{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ParseTemplates("app-helm", builtin)
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("got error %v, want TemplateError", err)
+	}
+}
+
+func TestParseTemplatesBuiltin(t *testing.T) {
+	p, err := NewParser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dir, name := range map[string]string{
+		"app-helm":      "upf.tmpl",
+		"app-kustomize": "StatefulSet.tmpl",
+		"server":        "sriov.tmpl",
+	} {
+		templ, err := ParseTemplates(dir, p.templateFS()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if templ.Lookup(name) == nil {
+			t.Errorf("%s: built-in template %s not found", dir, name)
+		}
+	}
+}
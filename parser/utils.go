@@ -2,15 +2,11 @@ package parser
 
 import (
 	"fmt"
-	"log"
 	"math/big"
 	"net"
-	"os"
-	"path/filepath"
-	"strings"
-	"text/template"
-
-	"github.com/Masterminds/sprig"
+	"reflect"
+	"sort"
+	"strconv"
 )
 
 func StringPtr(s string) *string {
@@ -33,6 +29,27 @@ func StringSlicePtr(s []string) *[]string {
 	return &s
 }
 
+// SortedKeys returns the keys of a map with string keys in sorted order, ranging
+// over the sorted keys iso the map keeps the output stable across runs
+func SortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SortedIntKeys returns the keys of a map with int keys in sorted order
+func SortedIntKeys(m interface{}) []int {
+	keys := make([]int, 0)
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, int(k.Int()))
+	}
+	sort.Ints(keys)
+	return keys
+}
+
 // RangeSize returns the size of a range in valid addresses.
 func RangeSize(subnet *net.IPNet) int64 {
 	ones, bits := subnet.Mask.Size()
@@ -82,22 +99,11 @@ func bigForIP(ip net.IP) *big.Int {
 	return big.NewInt(0).SetBytes(b)
 }
 
-func ParseTemplates(path string) *template.Template {
-	templ := template.New("app").Funcs(templateHelperFunctions).Funcs(sprig.TxtFuncMap())
-	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
-		if strings.HasSuffix(path, ".tmpl") {
-			_, err = templ.ParseFiles(path)
-			if err != nil {
-				log.Println(err)
-			}
-		}
-
-		return err
-	})
-
-	if err != nil {
-		panic(err)
+// endpointPrefix returns the address of a link endpoint with the prefix length
+// of the link, e.g. 10.0.0.1/31, or an empty string when no address is allocated
+func endpointPrefix(address *string, length *int) string {
+	if address == nil || *address == "" || length == nil {
+		return ""
 	}
-
-	return templ
+	return *address + "/" + strconv.Itoa(*length)
 }
@@ -0,0 +1,597 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// appNetworkIndexes holds the appnetwindexes entries the application parsing
+// requires per cnf; key1 is itfce or loopback, key2 is the cnf name
+var appNetworkIndexes = map[string]map[string][]string{
+	"itfce": {
+		"smf": {"llb"},
+		"upf": {"llb", "lmg"},
+		"amf": {"int", "fip"},
+	},
+	"loopback": {
+		"smf": {"bgp", "llb-sig", "llb-pod"},
+		"upf": {"bgp", "llb-sig", "llb-pod", "lmg-pod", "lmg-sig"},
+	},
+}
+
+// unknownFieldRe matches the error of a strict yaml decode for an unknown field
+var unknownFieldRe = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+
+// ValidationError describes a single problem in the deployment file
+type ValidationError struct {
+	Path    string
+	Line    int
+	Message string
+	// Warning is set for a problem that does not make the parser fail
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// validator walks the config and collects the problems it finds, the yaml
+// node tree is used to find the line number of every reported path
+type validator struct {
+	root   *yamlv3.Node
+	config *Config
+	errs   []*ValidationError
+}
+
+// ValidateConfig checks the deployment file for problems that would make
+// the parser fail, it returns all problems it finds and the warnings about
+// the output. The error is only returned when the file cannot be read or is
+// not valid yaml.
+func ValidateConfig(file *string) ([]*ValidationError, error) {
+	yamlFile, err := ioutil.ReadFile(*file)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(yamlv3.Node)
+	if err := yamlv3.Unmarshal(yamlFile, doc); err != nil {
+		return nil, err
+	}
+
+	v := &validator{
+		root:   doc,
+		config: new(Config),
+	}
+	if len(doc.Content) > 0 {
+		v.root = doc.Content[0]
+	}
+
+	// type errors and unknown fields are reported and the remaining part of
+	// the config is still validated, since yaml continues decoding after them
+	if err := yaml.UnmarshalStrict(yamlFile, v.config); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return nil, err
+		}
+		for _, e := range typeErr.Errors {
+			v.errs = append(v.errs, typeError(e))
+		}
+	}
+
+	v.validateInfrastructure()
+	v.validateCluster()
+	v.validateTopology()
+	v.validateWorkloads()
+	v.validateKeychains()
+	v.validateAppNetworkIndexes()
+
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].Line != v.errs[j].Line {
+			return v.errs[i].Line < v.errs[j].Line
+		}
+		return v.errs[i].Path < v.errs[j].Path
+	})
+	return v.errs, nil
+}
+
+// typeError converts a yaml type error string ("line 3: cannot unmarshal ...")
+// into a validation error
+func typeError(e string) *ValidationError {
+	verr := &ValidationError{Path: "", Message: e}
+	if strings.HasPrefix(e, "line ") {
+		split := strings.SplitN(strings.TrimPrefix(e, "line "), ": ", 2)
+		if line, err := strconv.Atoi(split[0]); err == nil && len(split) == 2 {
+			verr.Line = line
+			verr.Message = split[1]
+		}
+	}
+	// field x not found in type parser.NodeConfig
+	if m := unknownFieldRe.FindStringSubmatch(verr.Message); m != nil {
+		verr.Message = fmt.Sprintf("unknown field %q", m[1])
+	}
+	return verr
+}
+
+// addError records a problem at the given path, the path elements are map keys
+// (string) or sequence indexes (int)
+func (v *validator) addError(path []interface{}, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:    pathString(path),
+		Line:    v.line(path),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) addWarning(path []interface{}, format string, args ...interface{}) {
+	v.addError(path, format, args...)
+	v.errs[len(v.errs)-1].Warning = true
+}
+
+// line returns the line of the yaml node at the path, when the path does not
+// exist the line of the deepest node that exists is returned
+func (v *validator) line(path []interface{}) int {
+	n := v.root
+	if n == nil {
+		return 0
+	}
+	line := 0
+	for _, elem := range path {
+		var next *yamlv3.Node
+		switch e := elem.(type) {
+		case string:
+			if n.Kind == yamlv3.MappingNode {
+				for i := 0; i+1 < len(n.Content); i += 2 {
+					if n.Content[i].Value == e {
+						// report the key line for maps and the value line for scalars
+						next = n.Content[i+1]
+						if next.Kind != yamlv3.ScalarNode {
+							line = n.Content[i].Line
+						} else {
+							line = next.Line
+						}
+						break
+					}
+				}
+			}
+		case int:
+			if n.Kind == yamlv3.SequenceNode && e < len(n.Content) {
+				next = n.Content[e]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		n = next
+	}
+	return line
+}
+
+// pathString renders a path as infrastructure.networks.isl or topology.links[0]
+func pathString(path []interface{}) string {
+	var sb strings.Builder
+	for _, elem := range path {
+		switch e := elem.(type) {
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(e)
+		case int:
+			sb.WriteString(fmt.Sprintf("[%d]", e))
+		}
+	}
+	return sb.String()
+}
+
+// subPath returns a copy of path with the elements appended
+func subPath(path []interface{}, elems ...interface{}) []interface{} {
+	p := make([]interface{}, 0, len(path)+len(elems))
+	p = append(p, path...)
+	return append(p, elems...)
+}
+
+func (v *validator) validateInfrastructure() {
+	path := []interface{}{"infrastructure"}
+	infra := v.config.Infrastructure
+	if infra == nil {
+		v.addError(path, "infrastructure section is missing")
+		return
+	}
+
+	if infra.Protocols == nil || len(infra.Protocols.AsPool) == 0 {
+		v.addError(subPath(path, "protocols", "as_pool"), "as_pool must contain at least 1 AS")
+	} else if infra.Protocols.Protocol == nil {
+		v.addError(subPath(path, "protocols", "protocol"), "protocol is missing")
+	}
+	if infra.Protocols != nil && infra.Protocols.OverlayDesign != nil {
+		v.validateOverlayDesign(subPath(path, "protocols", "overlay_design"), infra.Protocols.OverlayDesign)
+	}
+	if infra.Protocols != nil && infra.Protocols.Protocol != nil {
+		v.validateUnderlay(subPath(path, "protocols"), infra.Protocols)
+	}
+	if infra.Protocols != nil && infra.Protocols.BgpSessions != nil {
+		v.validateBgpSessions(subPath(path, "protocols", "bgp_sessions"), infra.Protocols.BgpSessions)
+		// the ebgp overlay design has no overlay sessions
+		if infra.Protocols.BgpSessions.Bfd != nil && (infra.Protocols.OverlayDesign == nil || *infra.Protocols.OverlayDesign != OverlayEBGP) {
+			v.addWarning(subPath(path, "protocols", "bgp_sessions", "bfd"), overlayBfdWarning)
+		}
+		v.addWarning(subPath(path, "protocols", "bgp_sessions"), cnfSessionsWarning)
+	}
+
+	if infra.AddressingSchema == nil {
+		v.addError(subPath(path, "addressing_schema"), "addressing_schema is missing, supported values: %s", strings.Join(addressingSchemas, ", "))
+	} else {
+		v.validateAddressingSchema(subPath(path, "addressing_schema"), infra.AddressingSchema)
+	}
+
+	required := []string{"isl", "loopback"}
+	if infra.IslAddressing != nil {
+		switch *infra.IslAddressing {
+		case IslNumbered:
+		case IslUnnumbered:
+			// the unnumbered isls do not use the isl network
+			required = []string{"loopback"}
+		default:
+			v.addError(subPath(path, "isl_addressing"), "unknown isl_addressing %q, supported values: %s", *infra.IslAddressing, strings.Join(islAddressings, ", "))
+		}
+	}
+	for _, netwName := range required {
+		netwInfo, ok := infra.Networks[netwName]
+		if !ok || netwInfo == nil {
+			v.addError(subPath(path, "networks", netwName), "network %s is missing", netwName)
+			continue
+		}
+		if infra.AddressingSchema != nil {
+			v.validateSchemaCidrs(subPath(path, "networks", netwName), *infra.AddressingSchema, netwInfo)
+		}
+	}
+	for netwName, netwInfo := range infra.Networks {
+		v.validateNetwork(subPath(path, "networks", netwName), netwInfo)
+	}
+}
+
+func (v *validator) validateCluster() {
+	if v.config.Cluster == nil {
+		return
+	}
+	for netwName, netwInfo := range v.config.Cluster.Networks {
+		v.validateNetwork([]interface{}{"cluster", "networks", netwName}, netwInfo)
+	}
+}
+
+func (v *validator) validateWorkloads() {
+	for wlName, clients := range v.config.Workloads {
+		for cgName, wlInfo := range clients {
+			if wlInfo == nil {
+				continue
+			}
+			for netwType, netwInfo := range wlInfo.Itfces {
+				path := []interface{}{"workloads", wlName, cgName, "itfces", netwType}
+				v.validateNetwork(path, netwInfo)
+				if netwInfo != nil && netwInfo.Kind != nil && *netwInfo.Kind == "routed" {
+					if netwInfo.AddressingSchema == nil {
+						v.addError(subPath(path, "addressing_schema"), "routed itfce requires an addressing_schema")
+					} else {
+						v.validateSchemaCidrs(path, *netwInfo.AddressingSchema, netwInfo)
+					}
+					if netwInfo.VlanID == nil {
+						v.addError(subPath(path, "vlan_id"), "routed itfce requires a vlan_id")
+					}
+					if netwInfo.BgpSessions != nil {
+						v.validateBgpSessions(subPath(path, "bgp_sessions"), netwInfo.BgpSessions)
+					}
+				}
+			}
+			for netwType, netwInfo := range wlInfo.Loopbacks {
+				v.validateNetwork([]interface{}{"workloads", wlName, cgName, "loopbacks", netwType}, netwInfo)
+			}
+		}
+	}
+}
+
+// validateKeychains checks that the routed itfces with an authentication of
+// their own that share a keychain, e.g. a vlan of a workload towards several
+// client groups, have the same authentication
+func (v *validator) validateKeychains() {
+	type keychainAuth struct {
+		path string
+		auth BgpAuth
+	}
+	keychains := make(map[string]*keychainAuth)
+	for _, wlName := range SortedKeys(v.config.Workloads) {
+		clients := v.config.Workloads[wlName]
+		for _, cgName := range SortedKeys(clients) {
+			if clients[cgName] == nil {
+				continue
+			}
+			for _, netwType := range SortedKeys(clients[cgName].Itfces) {
+				netwInfo := clients[cgName].Itfces[netwType]
+				if netwInfo == nil || netwInfo.Kind == nil || *netwInfo.Kind != "routed" || netwInfo.BgpSessions == nil || netwInfo.BgpSessions.Auth == nil {
+					continue
+				}
+				path := []interface{}{"workloads", wlName, cgName, "itfces", netwType, "bgp_sessions", "auth"}
+				keychain := workloadKeychain(wlName, netwInfo)
+				auth := *netwInfo.BgpSessions.Auth
+				other, ok := keychains[keychain]
+				if !ok {
+					keychains[keychain] = &keychainAuth{path: pathString(path), auth: auth}
+					continue
+				}
+				if !equalStringPtr(other.auth.Type, auth.Type) || !equalStringPtr(other.auth.Secret, auth.Secret) {
+					v.addError(path, "keychain %s is also used by %s with a different auth", keychain, other.path)
+				}
+			}
+		}
+	}
+}
+
+// equalStringPtr returns true when both strings are unset or equal
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateNetwork checks the addressing schema and the cidrs of a network
+func (v *validator) validateNetwork(path []interface{}, netwInfo *NetworkInfo) {
+	if netwInfo == nil {
+		return
+	}
+	if netwInfo.AddressingSchema != nil {
+		v.validateAddressingSchema(subPath(path, "addressing_schema"), netwInfo.AddressingSchema)
+	}
+	for i, c := range netwInfo.Ipv4Cidr {
+		v.validateCidr(subPath(path, "ipv4_cidr", i), c, "ipv4")
+	}
+	for i, c := range netwInfo.Ipv6Cidr {
+		v.validateCidr(subPath(path, "ipv6_cidr", i), c, "ipv6")
+	}
+}
+
+// validateSchemaCidrs checks that a network has cidrs in the address families
+// of its addressing schema
+func (v *validator) validateSchemaCidrs(path []interface{}, schema string, netwInfo *NetworkInfo) {
+	if (schema == AddressingDualStack || schema == AddressingIPv4Only) && len(netwInfo.Ipv4Cidr) == 0 {
+		v.addError(subPath(path, "ipv4_cidr"), "addressing_schema %s requires an ipv4_cidr", schema)
+	}
+	if (schema == AddressingDualStack || schema == AddressingIPv6Only) && len(netwInfo.Ipv6Cidr) == 0 {
+		v.addError(subPath(path, "ipv6_cidr"), "addressing_schema %s requires an ipv6_cidr", schema)
+	}
+}
+
+func (v *validator) validateAddressingSchema(path []interface{}, schema *string) {
+	for _, s := range addressingSchemas {
+		if *schema == s {
+			return
+		}
+	}
+	v.addError(path, "unknown addressing_schema %q, supported values: %s", *schema, strings.Join(addressingSchemas, ", "))
+}
+
+func (v *validator) validateOverlayDesign(path []interface{}, design *string) {
+	switch *design {
+	case OverlayFullMesh, OverlayEBGP:
+		return
+	case OverlayRouteReflector:
+		if v.config.Topology != nil {
+			for _, nodeCfg := range v.config.Topology.Nodes {
+				if nodeCfg == nil {
+					continue
+				}
+				if role, ok := nodeCfg.Labels["role"]; ok && role != nil && *role == "spine" {
+					return
+				}
+				if rr, ok := nodeCfg.Labels["rr"]; ok && rr != nil && *rr == "true" {
+					return
+				}
+			}
+		}
+		v.addError(path, "overlay_design %s requires a node with the label role: spine or rr: true", *design)
+		return
+	}
+	v.addError(path, "unknown overlay_design %q, supported values: %s", *design, strings.Join(overlayDesigns, ", "))
+}
+
+func (v *validator) validateUnderlay(path []interface{}, protocols *Protocols) {
+	switch *protocols.Protocol {
+	case UnderlayEBGP:
+		return
+	case UnderlayISIS, UnderlayOSPF:
+	default:
+		v.addError(subPath(path, "protocol"), "unknown protocol %q, supported values: %s", *protocols.Protocol, strings.Join(underlayProtocols, ", "))
+		return
+	}
+	if protocols.OverlayAs == nil {
+		v.addError(subPath(path, "overlay_as"), "protocol %s requires the overlay_as of the iBGP overlay", *protocols.Protocol)
+	}
+	if protocols.OverlayDesign != nil && *protocols.OverlayDesign == OverlayEBGP {
+		v.addError(subPath(path, "overlay_design"), "overlay_design ebgp requires protocol ebgp, not %s", *protocols.Protocol)
+	}
+	if protocols.IsisLevel != nil {
+		found := false
+		for _, level := range isisLevels {
+			if *protocols.IsisLevel == level {
+				found = true
+			}
+		}
+		if !found {
+			v.addError(subPath(path, "isis_level"), "unknown isis_level %q, supported values: %s", *protocols.IsisLevel, strings.Join(isisLevels, ", "))
+		}
+	}
+	if protocols.OspfArea != nil && net.ParseIP(*protocols.OspfArea).To4() == nil {
+		v.addError(subPath(path, "ospf_area"), "ospf_area %q is not a dotted area id, e.g. 0.0.0.0", *protocols.OspfArea)
+	}
+	if protocols.HelloInterval != nil && *protocols.HelloInterval < 1 {
+		v.addError(subPath(path, "hello_interval"), "hello_interval must be at least 1")
+	}
+	if protocols.HelloMultiplier != nil && *protocols.HelloMultiplier < 2 {
+		v.addError(subPath(path, "hello_multiplier"), "hello_multiplier must be at least 2")
+	}
+}
+
+// validateBgpSessions checks the bfd, authentication, timers and prefix limit
+// of the bgp sessions
+func (v *validator) validateBgpSessions(path []interface{}, sessions *BgpSessions) {
+	if bfd := sessions.Bfd; bfd != nil {
+		if bfd.Interval != nil && *bfd.Interval < 10 {
+			v.addError(subPath(path, "bfd", "interval"), "bfd interval must be at least 10 ms")
+		}
+		if bfd.Multiplier != nil && *bfd.Multiplier < 3 {
+			v.addError(subPath(path, "bfd", "multiplier"), "bfd multiplier must be at least 3")
+		}
+	}
+	if auth := sessions.Auth; auth != nil {
+		if auth.Type != nil && *auth.Type != BgpAuthMD5 && *auth.Type != BgpAuthTCPAO {
+			v.addError(subPath(path, "auth", "type"), "unknown auth type %q, supported values: %s", *auth.Type, strings.Join(bgpAuthTypes, ", "))
+		}
+		if auth.Secret == nil || *auth.Secret == "" {
+			v.addError(subPath(path, "auth", "secret"), "auth requires a secret reference, env:NAME or file:PATH")
+		}
+	}
+	// a hold time of 0 disables the keepalives
+	if sessions.HoldTime != nil && *sessions.HoldTime != 0 && *sessions.HoldTime < 3 {
+		v.addError(subPath(path, "hold_time"), "hold_time must be 0 or at least 3 seconds")
+	}
+	if sessions.KeepaliveInterval != nil {
+		if *sessions.KeepaliveInterval < 1 {
+			v.addError(subPath(path, "keepalive_interval"), "keepalive_interval must be at least 1 second")
+		} else if sessions.HoldTime != nil && *sessions.HoldTime != 0 && *sessions.KeepaliveInterval >= *sessions.HoldTime {
+			v.addError(subPath(path, "keepalive_interval"), "keepalive_interval must be less than the hold_time")
+		}
+	}
+	if sessions.MaxPrefix != nil && *sessions.MaxPrefix < 1 {
+		v.addError(subPath(path, "max_prefix"), "max_prefix must be at least 1")
+	}
+}
+
+func (v *validator) validateCidr(path []interface{}, c *string, version string) {
+	if c == nil {
+		v.addError(path, "cidr is empty")
+		return
+	}
+	ip, _, err := net.ParseCIDR(*c)
+	if err != nil {
+		v.addError(path, "cidr %q does not parse", *c)
+		return
+	}
+	if ip4or6(ip.String()) != version {
+		v.addError(path, "cidr %s is not an %s cidr", *c, version)
+	}
+}
+
+func (v *validator) validateTopology() {
+	path := []interface{}{"topology"}
+	topo := v.config.Topology
+	if topo == nil {
+		v.addError(path, "topology section is missing")
+		return
+	}
+	if len(topo.Nodes) == 0 {
+		v.addError(subPath(path, "nodes"), "no nodes are declared")
+	}
+	for _, name := range SortedKeys(topo.Nodes) {
+		if topo.Nodes[name] == nil {
+			continue
+		}
+		for i, peer := range topo.Nodes[name].WanPeers {
+			ppath := subPath(path, "nodes", name, "wan_peers", i)
+			if peer == nil {
+				v.addError(ppath, "wan peer is empty")
+				continue
+			}
+			if peer.PeerAddress == nil || net.ParseIP(*peer.PeerAddress) == nil {
+				v.addError(subPath(ppath, "peer_address"), "wan peer requires an IPv4 or IPv6 peer_address")
+			}
+			if peer.PeerAS == nil || *peer.PeerAS == 0 {
+				v.addError(subPath(ppath, "peer_as"), "wan peer requires a peer_as")
+			}
+		}
+	}
+	for i, l := range topo.Links {
+		lpath := subPath(path, "links", i)
+		if l == nil {
+			v.addError(lpath, "link is empty")
+			continue
+		}
+		if len(l.Endpoints) != 2 {
+			v.addError(subPath(lpath, "endpoints"), "link must have 2 endpoints, got %d", len(l.Endpoints))
+		}
+		for j, ep := range l.Endpoints {
+			eppath := subPath(lpath, "endpoints", j)
+			if ep == nil {
+				v.addError(eppath, "endpoint is empty")
+				continue
+			}
+			split := strings.Split(*ep, ":")
+			if len(split) != 2 || split[0] == "" || split[1] == "" {
+				v.addError(eppath, "endpoint %q has wrong syntax, expected node:endpoint", *ep)
+				continue
+			}
+			if _, ok := topo.Nodes[split[0]]; !ok {
+				v.addError(eppath, "endpoint %q refers to node %s, which is not declared in topology.nodes", *ep, split[0])
+			}
+		}
+		if t, ok := l.Labels["type"]; ok && t != nil && strings.Contains(*t, ":") {
+			v.addError(subPath(lpath, "labels", "type"), "link label type %q has wrong syntax", *t)
+		}
+	}
+}
+
+// validateAppNetworkIndexes checks the appnetwindexes entries that are used
+// when the application data is parsed
+func (v *validator) validateAppNetworkIndexes() {
+	required := map[string]map[string][]string{
+		"itfce":    {"switch": {"gw"}},
+		"loopback": {"switch": {"bgp"}},
+	}
+	for _, pacoInfo := range v.config.Application {
+		if pacoInfo == nil {
+			continue
+		}
+		for cnfName, cnfInfo := range pacoInfo.Cnfs {
+			if cnfInfo == nil || cnfInfo.Enabled == nil || !*cnfInfo.Enabled {
+				continue
+			}
+			for itfceType, cnfs := range appNetworkIndexes {
+				required[itfceType][cnfName] = append(required[itfceType][cnfName], cnfs[cnfName]...)
+			}
+			// the system loopback is only allocated on the 3GPP_Internal network
+			if cnfInfo.Networking != nil {
+				if _, ok := cnfInfo.Networking.Multus["3GPP_Internal"]; ok && (cnfName == "smf" || cnfName == "upf") {
+					required["loopback"][cnfName] = append(required["loopback"][cnfName], "system")
+				}
+			}
+		}
+	}
+
+	for _, itfceType := range []string{"itfce", "loopback"} {
+		elements := make([]string, 0, len(required[itfceType]))
+		for element := range required[itfceType] {
+			elements = append(elements, element)
+		}
+		sort.Strings(elements)
+		for _, element := range elements {
+			for _, kind := range required[itfceType][element] {
+				if idx, ok := v.config.AppNetworkIndexes[itfceType][element][kind]; !ok || idx == nil {
+					v.addError([]interface{}{"appnetwindexes", itfceType, element, kind}, "index is required but not assigned")
+				}
+			}
+		}
+	}
+}
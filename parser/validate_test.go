@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	sample, err := ioutil.ReadFile(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		path     string
+		// line holds the start of the line the problem is reported on, for a
+		// missing key the line of its parent; the line is searched from the
+		// section the problem is in
+		section, line string
+	}{
+		{
+			name:    "missing isl network",
+			old:     "    isl: {ipv4_cidr: [100.64.0.0/16], ipv6_cidr: [3100:64::/48], ipv4_itfce_prefix_length: 31, ipv6_itfce_prefix_length: 127}\n",
+			path:    "infrastructure.networks.isl",
+			section: "infrastructure:",
+			line:    "  networks:",
+		},
+		{
+			name:    "missing loopback network",
+			old:     "    loopback: {ipv4_cidr: [100.112.100.0/24], ipv6_cidr: [3100:100::/48]}\n",
+			path:    "infrastructure.networks.loopback",
+			section: "infrastructure:",
+			line:    "  networks:",
+		},
+		{
+			name: "link to an undeclared node",
+			old:  `["leaf1:e1-1", "master0:eno5"]`,
+			new:  `["leaf9:e1-1", "master0:eno5"]`,
+			path: "topology.links[0].endpoints[0]",
+			line: `    - endpoints: ["leaf9:e1-1"`,
+		},
+		{
+			name: "bad endpoint syntax",
+			old:  `["leaf1:e1-1", "master0:eno5"]`,
+			new:  `["leaf1:e1-1", "master0-eno5"]`,
+			path: "topology.links[0].endpoints[1]",
+			line: `    - endpoints: ["leaf1:e1-1", "master0-eno5"]`,
+		},
+		{
+			name: "unknown addressing schema",
+			old:  `  addressing_schema: "dual-stack"`,
+			new:  `  addressing_schema: "triple-stack"`,
+			path: "infrastructure.addressing_schema",
+			line: `  addressing_schema: "triple-stack"`,
+		},
+		{
+			name: "unparsable cidr",
+			old:  "loopback: {ipv4_cidr: [100.112.100.0/24]",
+			new:  "loopback: {ipv4_cidr: [100.112.300.0/24]",
+			path: "infrastructure.networks.loopback.ipv4_cidr[0]",
+			line: "    loopback: {ipv4_cidr: [100.112.300.0/24]",
+		},
+		{
+			name: "unparsable wan peer",
+			old:  "peer_address: 192.0.2.1,",
+			new:  "peer_address: 192.0.2.300,",
+			path: "topology.nodes.dcgw1.wan_peers[0].peer_address",
+			line: "      wan_peers: [{peer_address: 192.0.2.300,",
+		},
+		{
+			name:    "missing appnetwindexes",
+			old:     "    switch:\n      gw: 1\n",
+			new:     "    switch:\n      gx: 1\n",
+			path:    "appnetwindexes.itfce.switch.gw",
+			section: "appnetwindexes:",
+			line:    "    switch:",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if !strings.Contains(string(sample), tc.old) {
+				t.Fatalf("sample has no %q", tc.old)
+			}
+			b := strings.Replace(string(sample), tc.old, tc.new, 1)
+			line, inSection := 0, tc.section == ""
+			for i, l := range strings.Split(b, "\n") {
+				inSection = inSection || l == tc.section
+				if inSection && strings.HasPrefix(l, tc.line) {
+					line = i + 1
+					break
+				}
+			}
+			file := filepath.Join(t.TempDir(), "paco.yaml")
+			if err := ioutil.WriteFile(file, []byte(b), 0644); err != nil {
+				t.Fatal(err)
+			}
+			verrs, err := ValidateConfig(&file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, verr := range verrs {
+				if verr.Path == tc.path {
+					if verr.Line != line {
+						t.Errorf("got %v on line %d, want line %d", verr, verr.Line, line)
+					}
+					return
+				}
+			}
+			t.Errorf("got %v, want a problem at %s", verrs, tc.path)
+		})
+	}
+}
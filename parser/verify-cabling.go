@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// the kinds of cabling problems
+const (
+	// CablingMissing is a planned link without lldp neighbor
+	CablingMissing = "missing"
+	// CablingSwapped is a planned link that is cabled to another port of the
+	// planned node
+	CablingSwapped = "swapped"
+	// CablingWrongNode is a planned link that is cabled to another node of the
+	// topology, e.g. the other leaf of the group
+	CablingWrongNode = "wrong-node"
+	// CablingUnexpected is a neighbor that is not part of the plan
+	CablingUnexpected = "unexpected"
+)
+
+// LLDPNeighbor is a neighbor a node sees on one of its ports
+type LLDPNeighbor struct {
+	LocalPort  string
+	RemoteNode string
+	RemotePort string
+}
+
+// CablingError describes a single difference between the planned links and
+// the lldp neighbors of a node
+type CablingError struct {
+	Kind     string
+	Node     string
+	Port     string
+	Expected string // node:port of the planned peer, empty if there is none
+	Found    string // node:port of the lldp neighbor, empty if there is none
+	// FoundOn is the port of the node with the planned peer as lldp neighbor
+	FoundOn string
+}
+
+func (e *CablingError) Error() string {
+	switch {
+	case e.Kind == CablingMissing:
+		return fmt.Sprintf("%s:%s: %s link, no lldp neighbor, expected %s", e.Node, e.Port, e.Kind, e.Expected)
+	case e.Expected == "":
+		return fmt.Sprintf("%s:%s: %s neighbor %s", e.Node, e.Port, e.Kind, e.Found)
+	case e.FoundOn != "":
+		return fmt.Sprintf("%s:%s: %s, expected %s is cabled to %s", e.Node, e.Port, e.Kind, e.Expected, e.FoundOn)
+	}
+	return fmt.Sprintf("%s:%s: %s, found %s, expected %s", e.Node, e.Port, e.Kind, e.Found, e.Expected)
+}
+
+// srlLLDPShow is the json of the SR Linux `show system lldp neighbor | as json`
+type srlLLDPShow struct {
+	Neighbors []struct {
+		Name       string `json:"Name"`
+		SystemName string `json:"Neighbor System Name"`
+		Port       string `json:"Neighbor Port"`
+	} `json:"Neighbors"`
+}
+
+// srlLLDPState is the json of the SR Linux lldp state, e.g.
+// `info from state system lldp | as json`
+type srlLLDPState struct {
+	Interface []struct {
+		Name     string `json:"name"`
+		Neighbor []struct {
+			SystemName string `json:"system-name"`
+			PortID     string `json:"port-id"`
+		} `json:"neighbor"`
+	} `json:"interface"`
+}
+
+// lldpctlPort is a port in the output of `lldpctl -f json`
+type lldpctlPort struct {
+	Chassis map[string]json.RawMessage `json:"chassis"`
+	Port    struct {
+		ID struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"id"`
+		Descr string `json:"descr"`
+	} `json:"port"`
+}
+
+// lldpctlDump is the output of `lldpctl -f json`, interface is a list of
+// single port objects or a single object for one port
+type lldpctlDump struct {
+	LLDP struct {
+		Interface json.RawMessage `json:"interface"`
+	} `json:"lldp"`
+}
+
+// ParseLLDP returns the neighbors in the lldp output of a node, either the
+// SR Linux `show system lldp neighbor` json or the linux `lldpctl -f json`
+func ParseLLDP(b []byte) ([]*LLDPNeighbor, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	nbrs := make([]*LLDPNeighbor, 0)
+	switch {
+	case raw["Neighbors"] != nil:
+		show := new(srlLLDPShow)
+		if err := json.Unmarshal(b, show); err != nil {
+			return nil, err
+		}
+		for _, n := range show.Neighbors {
+			nbrs = append(nbrs, &LLDPNeighbor{LocalPort: n.Name, RemoteNode: n.SystemName, RemotePort: n.Port})
+		}
+	case raw["lldp"] != nil:
+		dump := new(lldpctlDump)
+		if err := json.Unmarshal(b, dump); err != nil {
+			return nil, err
+		}
+		ports := make([]map[string]*lldpctlPort, 0)
+		if err := json.Unmarshal(dump.LLDP.Interface, &ports); err != nil {
+			port := make(map[string]*lldpctlPort)
+			if err := json.Unmarshal(dump.LLDP.Interface, &port); err != nil {
+				return nil, err
+			}
+			ports = append(ports, port)
+		}
+		for _, port := range ports {
+			for _, localPort := range SortedKeys(port) {
+				p := port[localPort]
+				// the chassis is keyed by the system name
+				for _, systemName := range SortedKeys(p.Chassis) {
+					// the port id of lldpd is the mac address by default, the
+					// description then holds the interface name
+					remotePort := p.Port.ID.Value
+					if p.Port.ID.Type != "ifname" && p.Port.ID.Type != "local" && p.Port.Descr != "" {
+						remotePort = p.Port.Descr
+					}
+					nbrs = append(nbrs, &LLDPNeighbor{LocalPort: localPort, RemoteNode: systemName, RemotePort: remotePort})
+				}
+			}
+		}
+	case raw["interface"] != nil:
+		state := new(srlLLDPState)
+		if err := json.Unmarshal(b, state); err != nil {
+			return nil, err
+		}
+		for _, itfce := range state.Interface {
+			for _, n := range itfce.Neighbor {
+				nbrs = append(nbrs, &LLDPNeighbor{LocalPort: itfce.Name, RemoteNode: n.SystemName, RemotePort: n.PortID})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown lldp output, expected SR Linux lldp neighbor or lldpctl json")
+	}
+	return nbrs, nil
+}
+
+// VerifyCabling compares the lldp neighbors with the planned physical links;
+// the neighbors are keyed by node name, the nodes without lldp output are only
+// verified from the side of their peers
+func VerifyCabling(ctx context.Context, cfg *Config, neighbors map[string][]*LLDPNeighbor, opts ...ParserOption) ([]*CablingError, error) {
+	res, err := Generate(ctx, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for nodeName := range neighbors {
+		if _, ok := res.Nodes[nodeName]; !ok {
+			return nil, &TopologyError{Element: "node " + nodeName, Msg: "lldp output of a node that is not in the topology"}
+		}
+	}
+
+	// the planned peer per node and port
+	planned := make(map[string]map[string]string)
+	for _, l := range res.Links {
+		if l.vWire == nil || !*l.vWire {
+			continue
+		}
+		for _, ep := range [][2]*Endpoint{{l.A, l.B}, {l.B, l.A}} {
+			if _, ok := planned[*ep[0].Node.ShortName]; !ok {
+				planned[*ep[0].Node.ShortName] = make(map[string]string)
+			}
+			planned[*ep[0].Node.ShortName][*ep[0].RealName] = *ep[1].Node.ShortName + ":" + *ep[1].RealName
+		}
+	}
+
+	errs := make([]*CablingError, 0)
+	for _, nodeName := range SortedKeys(neighbors) {
+		// the neighbor per local port
+		found := make(map[string]string)
+		for _, n := range neighbors[nodeName] {
+			found[n.LocalPort] = lldpNodeName(res.Nodes, n.RemoteNode) + ":" + n.RemotePort
+		}
+		// the ports whose neighbor is explained by a planned link on another port
+		explained := make(map[string]bool)
+
+		for _, port := range SortedKeys(planned[nodeName]) {
+			expected := planned[nodeName][port]
+			nbr, ok := found[port]
+			switch {
+			case ok && nbr == expected:
+				// cabled as planned
+			case ok && strings.Split(nbr, ":")[0] == strings.Split(expected, ":")[0]:
+				errs = append(errs, &CablingError{Kind: CablingSwapped, Node: nodeName, Port: port, Expected: expected, Found: nbr})
+			case ok:
+				kind := CablingUnexpected
+				if _, known := res.Nodes[strings.Split(nbr, ":")[0]]; known {
+					kind = CablingWrongNode
+				}
+				errs = append(errs, &CablingError{Kind: kind, Node: nodeName, Port: port, Expected: expected, Found: nbr})
+			default:
+				// the planned peer on another local port
+				otherPort := ""
+				for _, p := range SortedKeys(found) {
+					if found[p] == expected && planned[nodeName][p] != expected {
+						otherPort = p
+						break
+					}
+				}
+				if otherPort == "" {
+					errs = append(errs, &CablingError{Kind: CablingMissing, Node: nodeName, Port: port, Expected: expected})
+					continue
+				}
+				explained[otherPort] = true
+				errs = append(errs, &CablingError{Kind: CablingSwapped, Node: nodeName, Port: port, Expected: expected, FoundOn: otherPort})
+			}
+		}
+
+		for _, port := range SortedKeys(found) {
+			// the management port is not part of the plan
+			if _, ok := planned[nodeName][port]; ok || explained[port] || strings.HasPrefix(port, "mgmt") {
+				continue
+			}
+			errs = append(errs, &CablingError{Kind: CablingUnexpected, Node: nodeName, Port: port, Found: found[port]})
+		}
+	}
+	return errs, nil
+}
+
+// lldpNodeName returns the node of the lldp system name, the system name can
+// be the fqdn of the node
+func lldpNodeName(nodes map[string]*Node, systemName string) string {
+	if _, ok := nodes[systemName]; ok {
+		return systemName
+	}
+	host := strings.Split(systemName, ".")[0]
+	if _, ok := nodes[host]; ok {
+		return host
+	}
+	return systemName
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLLDP(t *testing.T) {
+	for name, tc := range map[string]struct {
+		input string
+		want  []*LLDPNeighbor
+	}{
+		"lldpctl single interface": {
+			input: `{"lldp": {"interface": {"eno5": {"chassis": {"leaf1": {}}, "port": {"id": {"type": "mac", "value": "1a:b1:01:ff:00:01"}, "descr": "ethernet-1/1"}}}}}`,
+			want:  []*LLDPNeighbor{{LocalPort: "eno5", RemoteNode: "leaf1", RemotePort: "ethernet-1/1"}},
+		},
+		"srl state": {
+			input: `{"interface": [{"name": "ethernet-1/49", "neighbor": [{"id": "1A:B0:01:FF:00:00", "system-name": "leaf2", "port-id": "ethernet-1/49"}]}]}`,
+			want:  []*LLDPNeighbor{{LocalPort: "ethernet-1/49", RemoteNode: "leaf2", RemotePort: "ethernet-1/49"}},
+		},
+	} {
+		got, err := ParseLLDP([]byte(tc.input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", name, got, tc.want)
+		}
+	}
+	if _, err := ParseLLDP([]byte(`{"neighbors": []}`)); err == nil {
+		t.Error("expected an error for an unknown lldp output")
+	}
+}
+
+func TestVerifyCabling(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	neighbors := make(map[string][]*LLDPNeighbor)
+	for _, node := range []string{"leaf1", "master0"} {
+		b, err := ioutil.ReadFile(filepath.Join("parser", "testdata", "lldp", node+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if neighbors[node], err = ParseLLDP(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cerrs, err := VerifyCabling(context.Background(), cfg, neighbors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(cerrs))
+	for _, cerr := range cerrs {
+		got = append(got, cerr.Error())
+	}
+	want := []string{
+		"leaf1:ethernet-1/2: missing link, no lldp neighbor, expected master0:ens2f0",
+		"leaf1:ethernet-1/49: swapped, found leaf2:ethernet-1/48, expected leaf2:ethernet-1/49",
+		"leaf1:ethernet-1/50: wrong-node, found dcgw2:1/1/1, expected dcgw1:1/1/1",
+		"leaf1:ethernet-1/10: unexpected neighbor lab-switch:Gi0/1",
+		"master0:eno5: missing link, no lldp neighbor, expected leaf1:ethernet-1/1",
+		"master0:eno6: swapped, found leaf2:ethernet-1/2, expected leaf2:ethernet-1/1",
+		"master0:ens2f1: swapped, found leaf2:ethernet-1/1, expected leaf2:ethernet-1/2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+
+	// a swapped pair of local ports
+	cerrs, err = VerifyCabling(context.Background(), cfg, map[string][]*LLDPNeighbor{
+		"leaf2": {
+			{LocalPort: "ethernet-1/1", RemoteNode: "master0", RemotePort: "eno6"},
+			{LocalPort: "ethernet-1/2", RemoteNode: "master0", RemotePort: "ens2f1"},
+			{LocalPort: "ethernet-1/48", RemoteNode: "leaf1", RemotePort: "ethernet-1/49"},
+			{LocalPort: "ethernet-1/50", RemoteNode: "dcgw2", RemotePort: "1/1/1"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cerrs) != 1 || cerrs[0].Kind != CablingSwapped || cerrs[0].Port != "ethernet-1/49" || cerrs[0].FoundOn != "ethernet-1/48" {
+		t.Errorf("got %v, want ethernet-1/49 swapped with ethernet-1/48", cerrs)
+	}
+
+	if _, err := VerifyCabling(context.Background(), cfg, map[string][]*LLDPNeighbor{"spine1": {}}); err == nil {
+		t.Error("expected an error for a node that is not in the topology")
+	}
+}
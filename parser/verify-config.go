@@ -0,0 +1,343 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// the kinds of configuration drift
+const (
+	// DriftMissing is generated configuration that is not in the running configuration
+	DriftMissing = "missing"
+	// DriftExtra is an interface, subinterface, network-instance, bgp neighbor
+	// or ethernet segment in the running configuration that is not generated
+	DriftExtra = "extra"
+	// DriftDifferent is a leaf with another value in the running configuration
+	DriftDifferent = "different"
+)
+
+// driftLists holds the lists of which the entries that are not generated are
+// reported, other additions in the running configuration such as defaults are
+// not drift
+var driftLists = map[string]bool{
+	"/interface":                               true,
+	"/interface/subinterface":                  true,
+	"/network-instance":                        true,
+	"/network-instance/protocols/bgp/neighbor": true,
+	"/system/network-instance/protocols/evpn/ethernet-segments/bgp-instance/ethernet-segment": true,
+}
+
+// driftIgnored holds the entries of the running configuration that are not
+// managed by the parser, e.g. the management interface
+var driftIgnored = map[string]bool{
+	"/interface[name=mgmt0]":       true,
+	"/network-instance[name=mgmt]": true,
+}
+
+// yangPrefixRe matches the module prefix of the keys and identities in the
+// SR Linux json, e.g. srl_nokia-interfaces:interface
+var yangPrefixRe = regexp.MustCompile(`^srl_nokia-[a-z0-9_-]+:`)
+
+// ConfigDrift describes a difference between the generated and the running
+// configuration of a node
+type ConfigDrift struct {
+	Kind     string
+	Node     string
+	Path     string // gnmi path, e.g. /interface[name=ethernet-1/1]/admin-state
+	Expected string
+	Found    string
+}
+
+func (d *ConfigDrift) Error() string {
+	switch d.Kind {
+	case DriftDifferent:
+		return fmt.Sprintf("%s: %s %s, found %s, expected %s", d.Node, d.Path, d.Kind, d.Found, d.Expected)
+	case DriftMissing:
+		if d.Expected != "" {
+			return fmt.Sprintf("%s: %s %s, expected %s", d.Node, d.Path, d.Kind, d.Expected)
+		}
+	}
+	return fmt.Sprintf("%s: %s %s", d.Node, d.Path, d.Kind)
+}
+
+// srlK8sResource is a SR Linux k8s resource of the running configuration
+type srlK8sResource struct {
+	Kind   string
+	Target string
+	Spec   map[string]interface{}
+}
+
+// RunningConfig holds the running configuration of the switches, as SR Linux
+// json per node or as the k8s resources of the nodes and groups
+type RunningConfig struct {
+	// Nodes holds the running configuration tree per node
+	Nodes     map[string]map[string]interface{}
+	resources []*srlK8sResource
+}
+
+// NewRunningConfig returns an empty running configuration
+func NewRunningConfig() *RunningConfig {
+	return &RunningConfig{
+		Nodes:     make(map[string]map[string]interface{}),
+		resources: make([]*srlK8sResource, 0),
+	}
+}
+
+// Load adds the SR Linux json of a node, e.g. `info from running / | as json`,
+// or the k8s resources of `kubectl get -o yaml`; the node of a resource is its
+// target label
+func (rc *RunningConfig) Load(nodeName string, b []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		doc := make(map[string]interface{})
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		if _, ok := doc["apiVersion"]; !ok {
+			// the json numbers are decoded as they are, e.g. an AS above 2^31
+			tree := make(map[string]interface{})
+			jdec := json.NewDecoder(bytes.NewReader(b))
+			jdec.UseNumber()
+			if err := jdec.Decode(&tree); err != nil {
+				return fmt.Errorf("expected a SR Linux json configuration or k8s resources: %v", err)
+			}
+			if nodeName == "" {
+				return fmt.Errorf("the node of the SR Linux configuration is not known")
+			}
+			rc.Nodes[nodeName] = normalizeSrlTree(tree).(map[string]interface{})
+			return nil
+		}
+		// a list of resources, e.g. kubectl get -o yaml
+		items, ok := doc["items"].([]interface{})
+		if !ok {
+			items = []interface{}{doc}
+		}
+		for _, item := range items {
+			if res := newSrlK8sResource(item); res != nil {
+				rc.resources = append(rc.resources, res)
+			}
+		}
+	}
+}
+
+// newSrlK8sResource returns the SR Linux resource of a k8s object, nil for
+// the other objects
+func newSrlK8sResource(obj interface{}) *srlK8sResource {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	kind, _ := m["kind"].(string)
+	spec, _ := m["spec"].(map[string]interface{})
+	if !strings.HasPrefix(kind, "K8sSrlNokia") || spec == nil {
+		return nil
+	}
+	res := &srlK8sResource{Kind: kind, Spec: spec}
+	if md, ok := m["metadata"].(map[string]interface{}); ok {
+		if labels, ok := md["labels"].(map[string]interface{}); ok {
+			res.Target, _ = labels["target"].(string)
+		}
+	}
+	return res
+}
+
+// normalizeSrlTree removes the module prefixes of the keys and identities
+func normalizeSrlTree(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[yangPrefixRe.ReplaceAllString(k, "")] = normalizeSrlTree(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(t))
+		for _, e := range t {
+			l = append(l, normalizeSrlTree(e))
+		}
+		return l
+	case string:
+		return yangPrefixRe.ReplaceAllString(t, "")
+	}
+	return v
+}
+
+// VerifyConfig compares the running configuration of the SR Linux nodes with
+// the configuration the parser generates for them; only the nodes with a
+// running configuration are verified
+func VerifyConfig(ctx context.Context, cfg *Config, rc *RunningConfig, opts ...ParserOption) ([]*ConfigDrift, error) {
+	res, err := Generate(ctx, cfg, append(opts, WithSwitchFormat(SwitchFormatJSON))...)
+	if err != nil {
+		return nil, err
+	}
+
+	running := make(map[string]map[string]interface{})
+	for nodeName, tree := range rc.Nodes {
+		// the resources are merged in a copy
+		running[nodeName] = normalizeSrlTree(tree).(map[string]interface{})
+	}
+	for _, r := range rc.resources {
+		nodes := make([]string, 0)
+		if _, ok := res.Nodes[r.Target]; ok {
+			nodes = append(nodes, r.Target)
+		} else {
+			for _, nodeName := range SortedKeys(res.Nodes) {
+				if *res.Nodes[nodeName].Target == r.Target && *res.Nodes[nodeName].Kind == "srl" {
+					nodes = append(nodes, nodeName)
+				}
+			}
+		}
+		if len(nodes) == 0 {
+			return nil, &TopologyError{Element: "target " + r.Target, Msg: fmt.Sprintf("resource %s targets no node of the topology", r.Kind)}
+		}
+		for _, nodeName := range nodes {
+			if _, ok := running[nodeName]; !ok {
+				running[nodeName] = make(map[string]interface{})
+			}
+			// every node gets its own copy of the spec to merge in
+			if err := mergeSrlResource(running[nodeName], r.Kind, normalizeSrlTree(r.Spec).(map[string]interface{})); err != nil {
+				return nil, &TopologyError{Element: "node " + nodeName, Msg: fmt.Sprintf("running resource %s: %v", r.Kind, err)}
+			}
+		}
+	}
+
+	drifts := make([]*ConfigDrift, 0)
+	for _, nodeName := range SortedKeys(running) {
+		if _, ok := res.Nodes[nodeName]; !ok {
+			return nil, &TopologyError{Element: "node " + nodeName, Msg: "running configuration of a node that is not in the topology"}
+		}
+		generated := make(map[string]interface{})
+		if b, ok := res.SwitchConfigs[nodeName]; ok {
+			dec := json.NewDecoder(bytes.NewReader(b))
+			dec.UseNumber()
+			if err := dec.Decode(&generated); err != nil {
+				return nil, err
+			}
+		}
+		d := &driftDiff{node: nodeName}
+		d.tree("", "", generated, running[nodeName])
+		drifts = append(drifts, d.drifts...)
+	}
+	return drifts, nil
+}
+
+// driftDiff collects the drift of the configuration of a node
+type driftDiff struct {
+	node   string
+	drifts []*ConfigDrift
+}
+
+func (d *driftDiff) add(kind, path, expected, found string) {
+	d.drifts = append(d.drifts, &ConfigDrift{Kind: kind, Node: d.node, Path: path, Expected: expected, Found: found})
+}
+
+// tree compares the generated tree with the running tree; path is the gnmi
+// path, schemaPath is the path without the list keys
+func (d *driftDiff) tree(path, schemaPath string, want, got map[string]interface{}) {
+	for _, k := range SortedKeys(want) {
+		p, sp := path+"/"+k, schemaPath+"/"+k
+		gv, ok := got[k]
+		if !ok {
+			expected := ""
+			if isSrlLeaf(want[k]) {
+				expected = fmt.Sprint(want[k])
+			}
+			d.add(DriftMissing, p, expected, "")
+			continue
+		}
+		switch wv := want[k].(type) {
+		case map[string]interface{}:
+			if gm, ok := gv.(map[string]interface{}); ok {
+				d.tree(p, sp, wv, gm)
+			} else {
+				d.add(DriftDifferent, p, "container", fmt.Sprint(gv))
+			}
+		case []interface{}:
+			gl, _ := gv.([]interface{})
+			d.list(k, p, sp, wv, gl)
+		default:
+			if fmt.Sprint(wv) != fmt.Sprint(gv) {
+				d.add(DriftDifferent, p, fmt.Sprint(wv), fmt.Sprint(gv))
+			}
+		}
+	}
+}
+
+// list compares the entries of a list by their keys, or the values of a leaf-list
+func (d *driftDiff) list(name, path, schemaPath string, want, got []interface{}) {
+	matched := make(map[int]bool)
+	for _, we := range want {
+		wm, ok := we.(map[string]interface{})
+		if !ok {
+			// a leaf-list
+			found := false
+			for _, ge := range got {
+				if fmt.Sprint(ge) == fmt.Sprint(we) {
+					found = true
+				}
+			}
+			if !found {
+				d.add(DriftMissing, path, fmt.Sprint(we), "")
+			}
+			continue
+		}
+		keys := srlKeys(name, wm)
+		p := path + srlPathKeys(wm, keys)
+		found := false
+		for i, ge := range got {
+			if gm, ok := ge.(map[string]interface{}); ok && !matched[i] && srlKeyValues(gm, keys) == srlKeyValues(wm, keys) {
+				matched[i] = true
+				found = true
+				d.tree(p, schemaPath, wm, gm)
+				break
+			}
+		}
+		if !found {
+			d.add(DriftMissing, p, "", "")
+		}
+	}
+	if !driftLists[schemaPath] {
+		return
+	}
+	for i, ge := range got {
+		gm, ok := ge.(map[string]interface{})
+		if !ok || matched[i] {
+			continue
+		}
+		p := path + srlPathKeys(gm, srlKeys(name, gm))
+		if !driftIgnored[p] {
+			d.add(DriftExtra, p, "", "")
+		}
+	}
+}
+
+// srlPathKeys returns the keys of a list entry in a gnmi path, e.g. [name=ethernet-1/1]
+func srlPathKeys(entry map[string]interface{}, keys []string) string {
+	s := ""
+	for _, key := range keys {
+		s += fmt.Sprintf("[%s=%s]", key, gnmiKeyValue(entry[key]))
+	}
+	return s
+}
+
+// isSrlLeaf returns true for a leaf value, false for a container or list
+func isSrlLeaf(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyConfig(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the running configuration of leaf1 with manual changes
+	leaf1 := make(map[string]interface{})
+	if err := json.Unmarshal(res.SwitchConfigs["leaf1"], &leaf1); err != nil {
+		t.Fatal(err)
+	}
+	itfces := leaf1["interface"].([]interface{})
+	for _, e := range itfces {
+		itfce := e.(map[string]interface{})
+		if itfce["name"] == "ethernet-1/49" {
+			itfce["description"] = "changed by hand"
+		}
+	}
+	leaf1["interface"] = append(itfces,
+		map[string]interface{}{"name": "ethernet-1/10", "admin-state": "enable"},
+		map[string]interface{}{"name": "mgmt0", "admin-state": "enable"},
+	)
+	for _, e := range leaf1["network-instance"].([]interface{}) {
+		ni := e.(map[string]interface{})
+		if ni["name"] != "default" {
+			continue
+		}
+		bgp := ni["protocols"].(map[string]interface{})["bgp"].(map[string]interface{})
+		bgp["neighbor"] = bgp["neighbor"].([]interface{})[1:]
+	}
+	// the module prefixes of info from running
+	leaf1["srl_nokia-routing-policy:routing-policy"] = leaf1["routing-policy"]
+	delete(leaf1, "routing-policy")
+	b, err := json.Marshal(leaf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc := NewRunningConfig()
+	if err := rc.Load("leaf1", b); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := VerifyConfig(context.Background(), cfg, rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(drifts))
+	for _, d := range drifts {
+		got = append(got, d.Error())
+	}
+	want := []string{
+		"leaf1: /interface[name=ethernet-1/49]/description different, found changed by hand, expected paco-ethernet-1/49",
+		"leaf1: /interface[name=ethernet-1/10] extra",
+		"leaf1: /network-instance[name=default]/protocols/bgp/neighbor[peer-address=100.64.0.1] missing",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+}
+
+func TestVerifyConfigGenerated(t *testing.T) {
+	for _, config := range testConfigs {
+		t.Run(goldenName(config), func(t *testing.T) {
+			cfg, err := LoadConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the k8s resources the parser generated have no drift
+			rc := NewRunningConfig()
+			err = filepath.Walk(filepath.Join(goldenDir, goldenName(config), switchDir), func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				b, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				return rc.Load("", b)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rc.resources) == 0 {
+				t.Fatal("no resources loaded")
+			}
+			drifts, err := VerifyConfig(context.Background(), cfg, rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(drifts) != 0 {
+				t.Errorf("got drift %v for the generated resources", drifts)
+			}
+
+			// neither has the native configuration of the nodes
+			res, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatJSON))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rc = NewRunningConfig()
+			for _, node := range SortedKeys(res.SwitchConfigs) {
+				if err := rc.Load(node, res.SwitchConfigs[node]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			drifts, err = VerifyConfig(context.Background(), cfg, rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(drifts) != 0 {
+				t.Errorf("got drift %v for the native configuration", drifts)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"os"
 	"path/filepath"
 	"text/template"
 
@@ -12,7 +11,7 @@ import (
 func (p *Parser) RenderStatefulSet(t *template.Template, dirName *string, values *Values) error {
 	log.Infof("Render statefulset file...")
 	fileName := "statefulSet.yaml"
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
 		return err
 	}
@@ -24,17 +23,17 @@ func (p *Parser) RenderStatefulSet(t *template.Template, dirName *string, values
 	}
 
 	if err := t.ExecuteTemplate(file, "StatefulSet.tmpl", s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: "StatefulSet.tmpl", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
 
 // RenderToActiveConfigMap function writes the ActiveConfigMap file
 func (p *Parser) RenderToActiveConfigMap(t *template.Template, dirName *string, values *Values) error {
 	log.Infof("Render network attachement file...")
 	fileName := "toActiveConfigMap.yaml"
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
 		return err
 	}
@@ -46,17 +45,17 @@ func (p *Parser) RenderToActiveConfigMap(t *template.Template, dirName *string,
 	}
 
 	if err := t.ExecuteTemplate(file, "ToActive_ConfigMap.tmpl", s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: "ToActive_ConfigMap.tmpl", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
 
 // RenderNetworkAttachement function writes the network Attachement file
 func (p *Parser) RenderNetworkAttachement(t *template.Template, dirName *string, values *Values) error {
 	log.Infof("Render network attachement file...")
 	fileName := "networkAttachementDefinition.yaml"
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
 		return err
 	}
@@ -68,8 +67,8 @@ func (p *Parser) RenderNetworkAttachement(t *template.Template, dirName *string,
 	}
 
 	if err := t.ExecuteTemplate(file, "networkAttachmentDefinition.tmpl", s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: "networkAttachmentDefinition.tmpl", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
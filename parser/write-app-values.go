@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"os"
 	"path/filepath"
 	"text/template"
 
@@ -12,7 +11,7 @@ import (
 func (p *Parser) WriteCnfValues(t *template.Template, dirName, cnfName *string, appc *AppConfig, appIPMap *AppIPMap) error {
 	log.Infof("Writing %s values.yaml...", *cnfName)
 	fileName := *cnfName + "_values.yaml"
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
 		return err
 	}
@@ -44,8 +43,8 @@ func (p *Parser) WriteCnfValues(t *template.Template, dirName, cnfName *string,
 	}
 
 	if err := t.ExecuteTemplate(file, *cnfName+".tmpl", s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: *cnfName + ".tmpl", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// gnmiPort is the gNMI port of the SR Linux nodes
+const gnmiPort = 57400
+
+// gnmiSetRequest is a gnmic set request file, see gnmic set --request-file
+type gnmiSetRequest struct {
+	Replaces []*gnmiUpdate `json:"replaces,omitempty"`
+	Updates  []*gnmiUpdate `json:"updates,omitempty"`
+}
+
+type gnmiUpdate struct {
+	Path     string      `json:"path"`
+	Value    interface{} `json:"value"`
+	Encoding string      `json:"encoding"`
+}
+
+var (
+	goGnmiTargetsTemplate = `# gnmic configuration with the targets of the paco switches, the set
+# request of a node is sent to its address, e.g.
+# gnmic -a <address> -u <user> -p <password> --skip-verify -e json_ietf set --request-file <node>.json
+skip-verify: true
+encoding: json_ietf
+targets:
+{{- range $index, $target := .}}
+  {{$target.Name}}:
+    address: {{$target.Address}}
+{{- end}}
+`
+)
+
+type gnmiTarget struct {
+	Name    string
+	Address string
+}
+
+// newGnmiSetRequest returns the set request with the configuration of a node;
+// the list entries, e.g. an interface or a network-instance, are replaced as a
+// whole, the containers such as routing-policy are updated
+func newGnmiSetRequest(cfg map[string]interface{}) *gnmiSetRequest {
+	req := &gnmiSetRequest{
+		Replaces: make([]*gnmiUpdate, 0),
+		Updates:  make([]*gnmiUpdate, 0),
+	}
+	for _, k := range SortedKeys(cfg) {
+		switch v := cfg[k].(type) {
+		case []interface{}:
+			for _, e := range v {
+				entry, ok := e.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				keys := srlKeys(k, entry)
+				value := make(map[string]interface{})
+				for ek, ev := range entry {
+					value[ek] = ev
+				}
+				path := "/" + k
+				for _, key := range keys {
+					path += fmt.Sprintf("[%s=%s]", key, gnmiKeyValue(entry[key]))
+					delete(value, key)
+				}
+				req.Replaces = append(req.Replaces, &gnmiUpdate{Path: path, Value: value, Encoding: "json_ietf"})
+			}
+		default:
+			req.Updates = append(req.Updates, &gnmiUpdate{Path: "/" + k, Value: v, Encoding: "json_ietf"})
+		}
+	}
+	return req
+}
+
+// gnmiKeyValue escapes a key value of a gNMI path
+func gnmiKeyValue(v interface{}) string {
+	return strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(fmt.Sprint(v))
+}
+
+// gnmiAddress returns the gNMI address of a node, the management ipv4 address
+// or the node name if the address is not set
+func gnmiAddress(n *Node) string {
+	if n.MgmtIPv4Address == nil || *n.MgmtIPv4Address == "" {
+		log.Warnf("node %s has no mgmt_ipv4, the node name is used as gNMI target", *n.ShortName)
+		return fmt.Sprintf("%s:%d", *n.ShortName, gnmiPort)
+	}
+	return fmt.Sprintf("%s:%d", *n.MgmtIPv4Address, gnmiPort)
+}
+
+// writeGnmiSetRequest writes the gnmic set request file of a node
+func (r *srlRenderer) writeGnmiSetRequest(dirName, nodeName string, cfg map[string]interface{}) error {
+	file, err := r.p.Sink.Create(filepath.Join(dirName, nodeName+".json"))
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(newGnmiSetRequest(cfg), "", "  ")
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeGnmiTargets writes the gnmic targets of the nodes with a set request
+func (r *srlRenderer) writeGnmiTargets(dirName string, nodeNames []string) error {
+	file, err := r.p.Sink.Create(filepath.Join(dirName, "targets.yaml"))
+	if err != nil {
+		return err
+	}
+	targets := make([]*gnmiTarget, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		targets = append(targets, &gnmiTarget{Name: nodeName, Address: gnmiAddress(r.p.Nodes[nodeName])})
+	}
+	if err := r.templates["gnmiTargets"].Execute(file, targets); err != nil {
+		file.Close()
+		return &TemplateError{Template: "gnmiTargets", Err: err}
+	}
+	return file.Close()
+}
@@ -3,14 +3,14 @@ package parser
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) {
+// WriteApplicationDeploymentIPAM writes the application ipam allocations as ipv4 and ipv6 csv files
+func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) error {
 
 	csvDataIPv4 := make([][]string, 0)
 	csvDataIPv6 := make([][]string, 0)
@@ -18,11 +18,14 @@ func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) {
 	csvDataIPv4 = append(csvDataIPv4, csvRowStart)
 	csvDataIPv6 = append(csvDataIPv6, csvRowStart)
 
-	for wlName, netwInfo := range p.DeploymentIPAM {
+	for _, wlName := range SortedKeys(p.DeploymentIPAM) {
+		netwInfo := p.DeploymentIPAM[wlName]
 		//fmt.Printf("Workload name: %s \n", wlName)
-		for netwType, subnetInfo := range netwInfo {
+		for _, netwType := range SortedKeys(netwInfo) {
+			subnetInfo := netwInfo[netwType]
 			//fmt.Printf("Network Type: %s \n", netwType)
-			for subnet, ipamInfo := range subnetInfo {
+			for _, subnet := range SortedKeys(subnetInfo) {
+				ipamInfo := subnetInfo[subnet]
 				ipType := ip4or6(subnet)
 
 				csvRowPrep := make([]string, 0)
@@ -63,7 +66,7 @@ func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) {
 					case "ipv6":
 						csvDataIPv6 = append(csvDataIPv6, csvRowPrep)
 					default:
-						log.Error("Wrong type")
+						return fmt.Errorf("subnet %s of workload %s is not an ipv4 or ipv6 subnet", subnet, wlName)
 					}
 				}
 				for _, ipAlloc := range ipamInfo.AllocatedIPs {
@@ -79,7 +82,7 @@ func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) {
 					case "ipv6":
 						csvDataIPv6 = append(csvDataIPv6, csvRow)
 					default:
-						log.Error("Wrong type")
+						return fmt.Errorf("subnet %s of workload %s is not an ipv4 or ipv6 subnet", subnet, wlName)
 					}
 
 					//fmt.Printf("Allocated IP: %s, App: %s, Usage: %s \n", *ipAlloc.IPAddress, *ipAlloc.Application, *ipAlloc.Usage)
@@ -92,34 +95,44 @@ func (p *Parser) WriteApplicationDeploymentIPAM(dirName *string) {
 	log.Info("Dumping CSV Data...")
 	log.Infof("length ipv4 csvData: %d", len(csvDataIPv4))
 	if len(csvDataIPv4) > 1 {
-		p.writeIpamFile(dirName, StringPtr("ipv4"), &csvDataIPv4)
+		if err := p.writeIpamFile(dirName, StringPtr("ipv4"), &csvDataIPv4); err != nil {
+			return err
+		}
 	}
 	log.Infof("length ipv6 csvData: %d", len(csvDataIPv6))
 	if len(csvDataIPv6) > 1 {
-		p.writeIpamFile(dirName, StringPtr("ipv6"), &csvDataIPv6)
+		if err := p.writeIpamFile(dirName, StringPtr("ipv6"), &csvDataIPv6); err != nil {
+			return err
+		}
 	}
 
 	log.Infof("Writing application ipam csv file...")
-
+	return nil
 }
 
-func (p *Parser) writeIpamFile(dirName, version *string, csvData *[][]string) {
-	p.CreateDirectory(*dirName, 0777)
+func (p *Parser) writeIpamFile(dirName, version *string, csvData *[][]string) error {
+	if err := p.CreateDirectory(*dirName, 0777); err != nil {
+		return err
+	}
 	fileName := fmt.Sprintf("paco-ipam-%s.csv", *version)
-	csvFile, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	csvFile, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
-		log.WithError(err).Infof("Cannot create file")
+		return err
 	}
 
 	csvwriter := csv.NewWriter(csvFile)
 
 	for _, row := range *csvData {
 		//log.Info("csvRow: %v", row)
-		err = csvwriter.Write(row)
-		if err != nil {
-			log.WithError(err).Infof("Cannot write csv file")
+		if err := csvwriter.Write(row); err != nil {
+			csvFile.Close()
+			return err
 		}
 	}
 	csvwriter.Flush()
-	csvFile.Close()
+	if err := csvwriter.Error(); err != nil {
+		csvFile.Close()
+		return err
+	}
+	return csvFile.Close()
 }
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"os"
 	"path/filepath"
 	"text/template"
 
@@ -12,7 +11,7 @@ import (
 func (p *Parser) RenderSriovConfigMap(t *template.Template, dirName *string, sriovc map[string]map[string]map[int]map[string][]*string) error {
 	log.Info("Writing server k8s .yaml files...")
 	fileName := "sriovdp_cm.yaml"
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(fileName)))
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(fileName)))
 	if err != nil {
 		return err
 	}
@@ -24,8 +23,8 @@ func (p *Parser) RenderSriovConfigMap(t *template.Template, dirName *string, sri
 	}
 
 	if err := t.ExecuteTemplate(file, "sriov.tmpl", s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: "sriov.tmpl", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
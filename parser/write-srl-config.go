@@ -0,0 +1,650 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// switch output formats
+const (
+	// SwitchFormatK8s writes the SR Linux configuration as k8s resources with kustomizations
+	SwitchFormatK8s = "k8s"
+	// SwitchFormatJSON writes a SR Linux configuration per node in json
+	SwitchFormatJSON = "json"
+	// SwitchFormatCLI writes a SR Linux configuration per node as flat set commands
+	SwitchFormatCLI = "cli"
+	// SwitchFormatGNMI writes a gnmic set request file per node
+	SwitchFormatGNMI = "gnmi"
+)
+
+// SwitchFormats holds the supported switch output formats
+var SwitchFormats = []string{SwitchFormatK8s, SwitchFormatJSON, SwitchFormatCLI, SwitchFormatGNMI}
+
+// srlParentKeys maps the spec key that refers to the parent list entry of a
+// resource to that list, e.g. a subinterface resource holds the interface-name
+var srlParentKeys = map[string]string{
+	"interface-name":        "interface",
+	"tunnel-interface-name": "tunnel-interface",
+	"network-instance-name": "network-instance",
+}
+
+// srlContainers holds the container the spec is merged in per resource kind,
+// the other resources are merged in their parent or the root of the config
+var srlContainers = map[string]string{
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsBgp":     "protocols",
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsBgpVpn":  "protocols",
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsBgpevpn": "protocols",
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsLinux":   "protocols",
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsIsis":    "protocols",
+	"K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsOspf":    "protocols",
+	"K8sSrlNokiaSystemSystemNetworkInstance":                    "system",
+	"K8sSrlNokiaSystemSystemAuthentication":                     "system",
+}
+
+// srlListKeys holds the keys of the SR Linux lists with more than one key,
+// the other lists are keyed by the first of srlKeyLeafs in the entry
+var srlListKeys = map[string][]string{
+	"prefix": {"ip-prefix", "mask-length-range"},
+}
+
+var srlKeyLeafs = []string{"name", "index", "id", "sequence-id", "ip-prefix", "peer-address", "group-name", "route-type", "as-number", "interface-name", "level-number", "area-id"}
+
+// WithSwitchFormat sets the output format of the switch configuration, k8s
+// resources or a native SR Linux configuration per node
+func WithSwitchFormat(f string) ParserOption {
+	return func(p *Parser) error {
+		if f == "" {
+			return nil
+		}
+		for _, sf := range SwitchFormats {
+			if f == sf {
+				p.SwitchFormat = StringPtr(f)
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown switch format %s, supported formats: %s", f, strings.Join(SwitchFormats, ", "))
+	}
+}
+
+// nativeSwitchConfig returns true if the switch configuration is written per node
+func (p *Parser) nativeSwitchConfig() bool {
+	return *p.SwitchFormat != SwitchFormatK8s
+}
+
+// createSwitchDirectory creates a directory for the k8s switch resources
+func (p *Parser) createSwitchDirectory(dirName string) error {
+	if p.nativeSwitchConfig() {
+		return nil
+	}
+	return p.CreateDirectory(dirName, 0777)
+}
+
+// srlRenderer renders the switch intent of the SR Linux nodes, as k8s
+// resources or as a native configuration per node
+type srlRenderer struct {
+	p         *Parser
+	templates map[string]*template.Template
+	// configs holds the native configuration tree per node name
+	configs map[string]map[string]interface{}
+}
+
+func init() {
+	registerSwitchBackend("srl", []string{"srl"}, map[string]string{
+		"srlInterface":              goK8sSrlinterfaceTemplate,
+		"srlSubInterface":           goK8sSrlsubinterfaceTemplate,
+		"srlIrbSubInterface":        goK8sSrlIrbSubInterfaceTemplate,
+		"srlTunnelInterface":        goK8sSrlTunnelInterfaceTemplate,
+		"srlVxlanInterface":         goK8sSrlVxlanInterfaceTemplate,
+		"srlNetworkInstance":        goK8sSrlnetworkinstanceTemplate,
+		"srlProtocolsBgp":           goK8sSrlprotocolsbgpTemplate,
+		"srlProtocolsIsis":          goK8sSrlprotocolsisisTemplate,
+		"srlProtocolsOspf":          goK8sSrlprotocolsospfTemplate,
+		"srlSystemNetworkInstance":  goK8sSrlSystemNetworkInstanceTemplate,
+		"srlNetworkInstanceBgpVpn":  goK8sSrlNetworkInstanceBgpVpnTemplate,
+		"srlNetworkInstanceBgpEvpn": goK8sSrlNetworkInstanceBgpEvpnTemplate,
+		"srlNetworkInstanceLinux":   goK8sSrlNetworkInstanceLinuxTemplate,
+		"srlRoutingPolicy":          goK8sSrlRoutingPoliciesTemplate,
+		"srlKeychain":               goK8sSrlKeychainTemplate,
+		"srlBfd":                    goK8sSrlBfdTemplate,
+		"gnmiTargets":               goGnmiTargetsTemplate,
+	}, newSrlRenderer)
+}
+
+func newSrlRenderer(p *Parser, templates map[string]*template.Template) switchRenderer {
+	return &srlRenderer{
+		p:         p,
+		templates: templates,
+		configs:   make(map[string]map[string]interface{}),
+	}
+}
+
+// write renders a k8s switch resource; in the native formats the spec of the
+// resource is merged in the configuration of the nodes of the resource
+func (r *srlRenderer) write(res *switchResource, tmplName string, data interface{}) error {
+	if !r.p.nativeSwitchConfig() {
+		file, err := r.p.Sink.Create(filepath.Join(res.DirName, filepath.Base(res.FileName)))
+		if err != nil {
+			return err
+		}
+		if err := r.templates[tmplName].Execute(file, data); err != nil {
+			file.Close()
+			return &TemplateError{Template: tmplName, Err: err}
+		}
+		return file.Close()
+	}
+
+	buf := new(bytes.Buffer)
+	if err := r.templates[tmplName].Execute(buf, data); err != nil {
+		return &TemplateError{Template: tmplName, Err: err}
+	}
+	for _, nodeName := range res.Nodes {
+		// every node gets its own copy of the spec to merge in
+		k8sRes := struct {
+			Kind string                 `yaml:"kind"`
+			Spec map[string]interface{} `yaml:"spec"`
+		}{}
+		if err := yaml.Unmarshal(buf.Bytes(), &k8sRes); err != nil {
+			return &TemplateError{Template: tmplName, Err: err}
+		}
+		if _, ok := r.configs[nodeName]; !ok {
+			r.configs[nodeName] = make(map[string]interface{})
+		}
+		if err := mergeSrlResource(r.configs[nodeName], k8sRes.Kind, k8sRes.Spec); err != nil {
+			return &TopologyError{Element: "node " + nodeName, Msg: fmt.Sprintf("resource %s: %v", res.FileName, err)}
+		}
+	}
+	return nil
+}
+
+// Interfaces renders the interfaces
+func (r *srlRenderer) Interfaces(res *switchResource, interfaces []*k8ssrlinterface) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		Interfaces   []*k8ssrlinterface
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		Interfaces:   interfaces,
+	}
+	return r.write(res, "srlInterface", s)
+}
+
+// SubInterfaces renders the subinterfaces of an interface
+func (r *srlRenderer) SubInterfaces(res *switchResource, subinterfaces []*k8ssrlsubinterface) error {
+	s := struct {
+		ResourceName  string
+		Target        string
+		InterfaceName string
+		SubInterfaces []*k8ssrlsubinterface
+	}{
+		ResourceName:  res.ResName,
+		Target:        res.Target,
+		InterfaceName: subinterfaces[0].InterfaceRealName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
+		SubInterfaces: subinterfaces,
+	}
+	return r.write(res, "srlSubInterface", s)
+}
+
+// IrbSubInterfaces renders the irb subinterfaces
+func (r *srlRenderer) IrbSubInterfaces(res *switchResource, irbsubinterfaces []*k8ssrlirbsubinterface) error {
+	s := struct {
+		ResourceName  string
+		Target        string
+		InterfaceName string
+		SubInterfaces []*k8ssrlirbsubinterface
+	}{
+		ResourceName:  res.ResName,
+		Target:        res.Target,
+		InterfaceName: irbsubinterfaces[0].InterfaceRealName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
+		SubInterfaces: irbsubinterfaces,
+	}
+	return r.write(res, "srlIrbSubInterface", s)
+}
+
+// TunnelInterfaces renders the tunnel interfaces
+func (r *srlRenderer) TunnelInterfaces(res *switchResource, tunnelinterfaces []*k8ssrlTunnelInterface) error {
+	s := struct {
+		ResourceName     string
+		Target           string
+		TunnelInterfaces []*k8ssrlTunnelInterface
+	}{
+		ResourceName:     res.ResName,
+		Target:           res.Target,
+		TunnelInterfaces: tunnelinterfaces,
+	}
+	return r.write(res, "srlTunnelInterface", s)
+}
+
+// VxlanInterfaces renders the vxlan interfaces of a tunnel interface
+func (r *srlRenderer) VxlanInterfaces(res *switchResource, vxlaninterfaces []*k8ssrlVxlanInterface) error {
+	s := struct {
+		ResourceName        string
+		Target              string
+		TunnelInterfaceName string
+		VxlanInterfaces     []*k8ssrlVxlanInterface
+	}{
+		ResourceName:        res.ResName,
+		Target:              res.Target,
+		TunnelInterfaceName: vxlaninterfaces[0].TunnelInterfaceName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
+		VxlanInterfaces:     vxlaninterfaces,
+	}
+	return r.write(res, "srlVxlanInterface", s)
+}
+
+// NetworkInstance renders the network instance
+func (r *srlRenderer) NetworkInstance(res *switchResource, netwinstance *k8ssrlNetworkInstance) error {
+	s := struct {
+		ResourceName    string
+		Target          string
+		NetworkInstance *k8ssrlNetworkInstance
+	}{
+		ResourceName:    res.ResName,
+		Target:          res.Target,
+		NetworkInstance: netwinstance,
+	}
+	return r.write(res, "srlNetworkInstance", s)
+}
+
+// ProtocolsBgp renders the bgp protocol of a network instance
+func (r *srlRenderer) ProtocolsBgp(res *switchResource, protocolsbgp *k8ssrlprotocolsbgp) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		ProtocolBgp  *k8ssrlprotocolsbgp
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		ProtocolBgp:  protocolsbgp,
+	}
+	return r.write(res, "srlProtocolsBgp", s)
+}
+
+// ProtocolsIgp renders the IS-IS or OSPF protocol of a network instance
+func (r *srlRenderer) ProtocolsIgp(res *switchResource, protocolsigp *k8ssrlprotocolsigp) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		ProtocolIgp  *k8ssrlprotocolsigp
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		ProtocolIgp:  protocolsigp,
+	}
+	if protocolsigp.Protocol == UnderlayOSPF {
+		return r.write(res, "srlProtocolsOspf", s)
+	}
+	return r.write(res, "srlProtocolsIsis", s)
+}
+
+// ESIs renders the ethernet segments of the system network instance
+func (r *srlRenderer) ESIs(res *switchResource, esis []*k8ssrlESI) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		ESIs         []*k8ssrlESI
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		ESIs:         esis,
+	}
+	return r.write(res, "srlSystemNetworkInstance", s)
+}
+
+// BgpVpn renders the bgp-vpn protocol of a network instance
+func (r *srlRenderer) BgpVpn(res *switchResource, netwInstanceProtocol *k8ssrlNetworkInstance) error {
+	s := struct {
+		ResourceName            string
+		Target                  string
+		NetworkInstanceProtocol *k8ssrlNetworkInstance
+	}{
+		ResourceName:            res.ResName,
+		Target:                  res.Target,
+		NetworkInstanceProtocol: netwInstanceProtocol,
+	}
+	return r.write(res, "srlNetworkInstanceBgpVpn", s)
+}
+
+// BgpEvpn renders the bgp-evpn protocol of a network instance
+func (r *srlRenderer) BgpEvpn(res *switchResource, netwInstanceProtocol *k8ssrlNetworkInstance) error {
+	s := struct {
+		ResourceName            string
+		Target                  string
+		NetworkInstanceProtocol *k8ssrlNetworkInstance
+	}{
+		ResourceName:            res.ResName,
+		Target:                  res.Target,
+		NetworkInstanceProtocol: netwInstanceProtocol,
+	}
+	return r.write(res, "srlNetworkInstanceBgpEvpn", s)
+}
+
+// Linux renders the linux protocol of a network instance
+func (r *srlRenderer) Linux(res *switchResource, netwInstanceProtocol *k8ssrlNetworkInstance) error {
+	s := struct {
+		ResourceName            string
+		Target                  string
+		NetworkInstanceProtocol *k8ssrlNetworkInstance
+	}{
+		ResourceName:            res.ResName,
+		Target:                  res.Target,
+		NetworkInstanceProtocol: netwInstanceProtocol,
+	}
+	return r.write(res, "srlNetworkInstanceLinux", s)
+}
+
+// RoutingPolicy renders the routing policy
+func (r *srlRenderer) RoutingPolicy(res *switchResource, routingPolicy *k8ssrlRoutingPolicy) error {
+	s := struct {
+		ResourceName  string
+		Target        string
+		RoutingPolicy *k8ssrlRoutingPolicy
+	}{
+		ResourceName:  res.ResName,
+		Target:        res.Target,
+		RoutingPolicy: routingPolicy,
+	}
+	return r.write(res, "srlRoutingPolicy", s)
+}
+
+// Keychain renders the keychain of the authentication of the bgp sessions
+func (r *srlRenderer) Keychain(res *switchResource, session *bgpSession) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		Session      *bgpSession
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		Session:      session,
+	}
+	return r.write(res, "srlKeychain", s)
+}
+
+// Bfd renders the bfd of the subinterfaces of the bgp sessions
+func (r *srlRenderer) Bfd(res *switchResource, bfd *k8ssrlBfd) error {
+	s := struct {
+		ResourceName string
+		Target       string
+		Bfd          *k8ssrlBfd
+	}{
+		ResourceName: res.ResName,
+		Target:       res.Target,
+		Bfd:          bfd,
+	}
+	return r.write(res, "srlBfd", s)
+}
+
+// mergeSrlResource merges the spec of a k8s resource in the configuration tree
+// of a node, an error is returned when a leaf is set to conflicting values
+func mergeSrlResource(root map[string]interface{}, kind string, spec map[string]interface{}) error {
+	dst := root
+	path := ""
+	src := make(map[string]interface{})
+	for k, v := range spec {
+		if list, ok := srlParentKeys[k]; ok {
+			dst = srlListEntry(root, list, "name", v)
+			path = list + " " + srlValue(v)
+			continue
+		}
+		src[k] = v
+	}
+	if c, ok := srlContainers[kind]; ok {
+		if _, ok := dst[c].(map[string]interface{}); !ok {
+			dst[c] = make(map[string]interface{})
+		}
+		dst = dst[c].(map[string]interface{})
+		path = srlPath(path, c)
+	}
+	return mergeSrlTree(path, dst, src)
+}
+
+// srlListEntry returns the entry of the list with the key, the entry is added
+// when it does not exist
+func srlListEntry(m map[string]interface{}, list, key string, value interface{}) map[string]interface{} {
+	l, _ := m[list].([]interface{})
+	for _, e := range l {
+		if entry, ok := e.(map[string]interface{}); ok && fmt.Sprint(entry[key]) == fmt.Sprint(value) {
+			return entry
+		}
+	}
+	entry := map[string]interface{}{key: value}
+	m[list] = append(l, entry)
+	return entry
+}
+
+// srlPath appends an element to the cli path of a leaf, e.g. for the errors
+func srlPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + " " + elem
+}
+
+// mergeSrlTree merges src in dst, the list entries with the same keys are
+// merged; a leaf that is set to another value than in dst is a conflict
+func mergeSrlTree(path string, dst, src map[string]interface{}) error {
+	for _, k := range SortedKeys(src) {
+		v := src[k]
+		switch sv := v.(type) {
+		case map[string]interface{}:
+			dv, ok := dst[k].(map[string]interface{})
+			if !ok {
+				if _, exists := dst[k]; exists {
+					return fmt.Errorf("%s is both a container and a leaf", srlPath(path, k))
+				}
+				dv = make(map[string]interface{})
+				dst[k] = dv
+			}
+			if err := mergeSrlTree(srlPath(path, k), dv, sv); err != nil {
+				return err
+			}
+			continue
+		case []interface{}:
+			dv, ok := dst[k].([]interface{})
+			if !ok {
+				if _, exists := dst[k]; exists {
+					return fmt.Errorf("%s is both a list and a leaf", srlPath(path, k))
+				}
+			}
+			// a new list is merged in an empty one as well, such that
+			// duplicate keys within the resource are detected
+			l, err := mergeSrlList(srlPath(path, k), k, dv, sv)
+			if err != nil {
+				return err
+			}
+			dst[k] = l
+			continue
+		}
+		if dv, ok := dst[k]; ok && srlValue(dv) != srlValue(v) {
+			return fmt.Errorf("%s is set to %s and %s", srlPath(path, k), srlValue(dv), srlValue(v))
+		}
+		dst[k] = v
+	}
+	return nil
+}
+
+func mergeSrlList(path, name string, dst, src []interface{}) ([]interface{}, error) {
+	for _, se := range src {
+		sm, ok := se.(map[string]interface{})
+		if !ok {
+			// a leaf-list
+			dst = append(dst, se)
+			continue
+		}
+		keys := srlKeys(name, sm)
+		if len(keys) == 0 {
+			dst = append(dst, sm)
+			continue
+		}
+		var entry map[string]interface{}
+		for _, de := range dst {
+			if dm, ok := de.(map[string]interface{}); ok && srlKeyValues(dm, keys) == srlKeyValues(sm, keys) {
+				entry = dm
+				break
+			}
+		}
+		if entry == nil {
+			entry = make(map[string]interface{}, len(sm))
+			dst = append(dst, entry)
+		}
+		if err := mergeSrlTree(srlPath(path, srlKeyValues(sm, keys)), entry, sm); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// srlKeys returns the keys of an entry of the named list
+func srlKeys(name string, entry map[string]interface{}) []string {
+	if keys, ok := srlListKeys[name]; ok {
+		return keys
+	}
+	for _, k := range srlKeyLeafs {
+		if _, ok := entry[k]; ok {
+			return []string{k}
+		}
+	}
+	return nil
+}
+
+func srlKeyValues(entry map[string]interface{}, keys []string) string {
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, srlValue(entry[k]))
+	}
+	return strings.Join(values, " ")
+}
+
+// srlValue returns a leaf value as the SR Linux cli shows it, values with
+// spaces or special characters are quoted
+func srlValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\"'#;{}[]") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// Finish writes the native SR Linux configuration per node
+func (r *srlRenderer) Finish() error {
+	if !r.p.nativeSwitchConfig() {
+		return nil
+	}
+	log.Infof("Writing SR Linux %s configuration...", *r.p.SwitchFormat)
+	if *r.p.SwitchFormat == SwitchFormatGNMI {
+		if err := r.p.CreateDirectory(switchGnmiDir, 0777); err != nil {
+			return err
+		}
+		for _, nodeName := range SortedKeys(r.configs) {
+			if err := r.writeGnmiSetRequest(switchGnmiDir, nodeName, r.configs[nodeName]); err != nil {
+				return err
+			}
+		}
+		return r.writeGnmiTargets(switchGnmiDir, SortedKeys(r.configs))
+	}
+	if err := r.p.CreateDirectory(switchConfigDir, 0777); err != nil {
+		return err
+	}
+	for _, nodeName := range SortedKeys(r.configs) {
+		if err := r.writeConfig(switchConfigDir, nodeName, r.configs[nodeName]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConfig writes the SR Linux configuration of a node in the switch format
+func (r *srlRenderer) writeConfig(dirName, nodeName string, cfg map[string]interface{}) error {
+	file, err := r.p.Sink.Create(filepath.Join(dirName, nodeName+"."+*r.p.SwitchFormat))
+	if err != nil {
+		return err
+	}
+	switch *r.p.SwitchFormat {
+	case SwitchFormatJSON:
+		// the keys of the maps are sorted, the lists keep the order of the resources
+		b, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			file.Close()
+			return err
+		}
+		if _, err := file.Write(append(b, '\n')); err != nil {
+			file.Close()
+			return err
+		}
+	case SwitchFormatCLI:
+		if err := writeSrlFlat(file, "", cfg); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// writeSrlFlat writes the configuration tree as set commands, like info flat
+// shows the configuration of a SR Linux node
+func writeSrlFlat(w io.Writer, path string, m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var err error
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			if len(v) == 0 {
+				_, err = fmt.Fprintf(w, "set /%s %s\n", path, k)
+			} else {
+				err = writeSrlFlat(w, path+" "+k, v)
+			}
+		case []interface{}:
+			leafs := make([]string, 0)
+			for _, e := range v {
+				entry, ok := e.(map[string]interface{})
+				if !ok {
+					leafs = append(leafs, srlValue(e))
+					continue
+				}
+				keys := srlKeys(k, entry)
+				entryPath := path + " " + k + " " + srlKeyValues(entry, keys)
+				rest := make(map[string]interface{})
+				for ek, ev := range entry {
+					rest[ek] = ev
+				}
+				for _, key := range keys {
+					delete(rest, key)
+				}
+				if len(rest) == 0 {
+					if _, err = fmt.Fprintf(w, "set /%s\n", entryPath); err != nil {
+						return err
+					}
+					continue
+				}
+				if err = writeSrlFlat(w, entryPath, rest); err != nil {
+					return err
+				}
+			}
+			if len(leafs) > 0 {
+				_, err = fmt.Fprintf(w, "set /%s %s [ %s ]\n", path, k, strings.Join(leafs, " "))
+			}
+		case nil:
+			_, err = fmt.Fprintf(w, "set /%s %s\n", path, k)
+		default:
+			_, err = fmt.Fprintf(w, "set /%s %s %s\n", path, k, srlValue(v))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
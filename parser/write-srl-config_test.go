@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSwitchFormatNative(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonRes, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliRes, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatCLI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range []*Result{jsonRes, cliRes} {
+		if len(res.SwitchResources) != 0 {
+			t.Errorf("got %d k8s switch resources, want none", len(res.SwitchResources))
+		}
+		// a config per SR Linux node, not for the gateways
+		if len(res.SwitchConfigs) != 2 || res.SwitchConfigs["leaf1"] == nil || res.SwitchConfigs["leaf2"] == nil {
+			t.Errorf("got configs for %v, want leaf1 and leaf2", SortedKeys(res.SwitchConfigs))
+		}
+	}
+
+	var leaf1 struct {
+		Interface []struct {
+			Name         string `json:"name"`
+			Subinterface []struct {
+				Index int `json:"index"`
+			} `json:"subinterface"`
+		} `json:"interface"`
+		NetworkInstance []struct {
+			Name      string `json:"name"`
+			Protocols struct {
+				Bgp struct {
+					AutonomousSystem int `json:"autonomous-system"`
+				} `json:"bgp"`
+			} `json:"protocols"`
+		} `json:"network-instance"`
+	}
+	if err := json.Unmarshal(jsonRes.SwitchConfigs["leaf1"], &leaf1); err != nil {
+		t.Fatal(err)
+	}
+	// the group resources and the node resources end up in a single interface
+	system0 := 0
+	for _, itfce := range leaf1.Interface {
+		if itfce.Name == "system0" {
+			system0++
+			if len(itfce.Subinterface) != 1 || itfce.Subinterface[0].Index != 0 {
+				t.Errorf("system0 has subinterfaces %v, want index 0", itfce.Subinterface)
+			}
+		}
+	}
+	if system0 != 1 {
+		t.Errorf("got system0 %d times, want once", system0)
+	}
+	found := false
+	for _, ni := range leaf1.NetworkInstance {
+		if ni.Name == "default" {
+			found = ni.Protocols.Bgp.AutonomousSystem == 65001
+		}
+	}
+	if !found {
+		t.Error("leaf1 has no default network-instance with AS 65001")
+	}
+
+	for node, want := range map[string][]string{
+		"leaf1": {
+			"set / interface system0 subinterface 0 ipv4 address 100.112.100.0/32\n",
+			"set / network-instance default protocols bgp autonomous-system 65001\n",
+			"set / routing-policy prefix-set system-v4 prefix 100.112.100.0/24 32..32\n",
+		},
+		"leaf2": {
+			"set / interface system0 subinterface 0 ipv4 address 100.112.100.1/32\n",
+			"set / network-instance default protocols bgp autonomous-system 65002\n",
+		},
+	} {
+		cli := string(cliRes.SwitchConfigs[node])
+		for _, line := range want {
+			if !strings.Contains(cli, line) {
+				t.Errorf("%s misses %q", node, line)
+			}
+		}
+	}
+	// the group resources are copied per node
+	if strings.Contains(string(cliRes.SwitchConfigs["leaf1"]), "100.112.100.1/32") {
+		t.Error("leaf1 has the system address of leaf2")
+	}
+}
+
+func TestWithSwitchFormat(t *testing.T) {
+	if _, err := NewParser(WithSwitchFormat("xml")); err == nil {
+		t.Error("expected an error for an unknown switch format")
+	}
+	p, err := NewParser(WithSwitchFormat(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p.SwitchFormat != SwitchFormatK8s {
+		t.Errorf("got switch format %s, want %s", *p.SwitchFormat, SwitchFormatK8s)
+	}
+}
+
+func TestSrlValue(t *testing.T) {
+	for v, want := range map[interface{}]string{
+		"paco-lo0":      "paco-lo0",
+		"paco lo0":      `"paco lo0"`,
+		"":              `""`,
+		65001:           "65001",
+		true:            "true",
+		"3100:100::/64": "3100:100::/64",
+	} {
+		if got := srlValue(v); got != want {
+			t.Errorf("%v: got %s, want %s", v, got, want)
+		}
+	}
+}
+
+func TestSwitchFormatConflict(t *testing.T) {
+	// the irb and the routed itfce of the infrastructure workload of the
+	// multinet deployment share vlan 1000, the routed one gets its own
+	// vxlan-interface
+	cfg, err := LoadConfig(testConfigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var leaf1 struct {
+		NetworkInstance []struct {
+			Name           string `json:"name"`
+			VxlanInterface []struct {
+				Name string `json:"name"`
+			} `json:"vxlan-interface"`
+		} `json:"network-instance"`
+		TunnelInterface []struct {
+			Name           string `json:"name"`
+			VxlanInterface []struct {
+				Index   int    `json:"index"`
+				Type    string `json:"type"`
+				Ingress struct {
+					Vni int `json:"vni"`
+				} `json:"ingress"`
+			} `json:"vxlan-interface"`
+		} `json:"tunnel-interface"`
+	}
+	decodeNodeJSON(t, res, "leaf1", &leaf1)
+	types := make(map[int]string)
+	for _, ti := range leaf1.TunnelInterface {
+		for _, vi := range ti.VxlanInterface {
+			// vni 0 is not valid, index 0 has vni 1
+			if vi.Ingress.Vni != vi.Index && (vi.Index != 0 || vi.Ingress.Vni != 1) {
+				t.Errorf("vxlan-interface %d: got vni %d", vi.Index, vi.Ingress.Vni)
+			}
+			types[vi.Index] = vi.Type
+		}
+	}
+	if types[1000] != "bridged" || types[routedVxlanOffset+1000] != "routed" {
+		t.Errorf("got vxlan-interfaces %v, want 1000 bridged and %d routed", types, routedVxlanOffset+1000)
+	}
+	for _, ni := range leaf1.NetworkInstance {
+		if ni.Name == "infrastructure-ipvrf-itfce-1000" {
+			if len(ni.VxlanInterface) != 1 || ni.VxlanInterface[0].Name != "vxlan0.11000" {
+				t.Errorf("%s: got vxlan-interface %v, want vxlan0.11000", ni.Name, ni.VxlanInterface)
+			}
+		}
+	}
+
+	// a leaf set to different values is a conflict
+	root := make(map[string]interface{})
+	vxlan := func(kind string) map[string]interface{} {
+		return map[string]interface{}{
+			"tunnel-interface-name": "vxlan0",
+			"vxlan-interface":       []interface{}{map[string]interface{}{"index": 1000, "type": kind}},
+		}
+	}
+	if err := mergeSrlResource(root, "K8sSrlNokiaTunnelInterfacesTunnelInterfaceVxlanInterface", vxlan("bridged")); err != nil {
+		t.Fatal(err)
+	}
+	err = mergeSrlResource(root, "K8sSrlNokiaTunnelInterfacesTunnelInterfaceVxlanInterface", vxlan("routed"))
+	if err == nil || !strings.Contains(err.Error(), "tunnel-interface vxlan0 vxlan-interface 1000 type") {
+		t.Errorf("got error %v, want a conflict of the type of vxlan0.1000", err)
+	}
+
+	// the same leaf set twice is no conflict
+	root = make(map[string]interface{})
+	spec := map[string]interface{}{
+		"interface-name": "ethernet-1/49",
+		"subinterface":   []interface{}{map[string]interface{}{"index": 0, "admin-state": "enable"}},
+	}
+	for i := 0; i < 2; i++ {
+		if err := mergeSrlResource(root, "K8sSrlNokiaInterfacesInterfaceSubinterface", spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSwitchFormatGNMI(t *testing.T) {
+	cfg, err := LoadConfig(testConfigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Generate(context.Background(), cfg, WithSwitchFormat(SwitchFormatGNMI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GnmiRequests) != 2 {
+		t.Fatalf("got requests for %v, want leaf1 and leaf2", SortedKeys(res.GnmiRequests))
+	}
+	req := new(gnmiSetRequest)
+	if err := json.Unmarshal(res.GnmiRequests["leaf1"], req); err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]*gnmiUpdate)
+	for _, u := range append(req.Replaces, req.Updates...) {
+		if u.Encoding != "json_ietf" {
+			t.Errorf("%s: got encoding %s", u.Path, u.Encoding)
+		}
+		paths[u.Path] = u
+	}
+	for _, path := range []string{"/interface[name=system0]", "/interface[name=ethernet-1/49]", "/network-instance[name=default]", "/tunnel-interface[name=vxlan0]", "/routing-policy", "/system"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("leaf1 has no operation for %s", path)
+		}
+	}
+	// the key is in the path, not in the value
+	if v, ok := paths["/interface[name=system0]"].Value.(map[string]interface{}); !ok || v["name"] != nil {
+		t.Errorf("got value %v for system0", paths["/interface[name=system0]"].Value)
+	}
+
+	targets := string(res.Files[switchGnmiDir+"/targets.yaml"])
+	for _, want := range []string{"  leaf1:\n    address: 172.20.20.3:57400\n", "  leaf2:\n    address: 172.20.20.4:57400\n"} {
+		if !strings.Contains(targets, want) {
+			t.Errorf("targets.yaml misses %q:\n%s", want, targets)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"path/filepath"
+)
+
+var (
+	goSrosGatewayTemplate = `# paco dc gateway {{.Name}}
+{{- range $index, $port := .Ports}}
+/configure port {{$port.Name}} admin-state enable
+/configure port {{$port.Name}} description "{{$port.Description}}"
+/configure port {{$port.Name}} ethernet mode hybrid
+/configure port {{$port.Name}} ethernet encap-type dot1q
+{{- end}}
+{{- if .UePoolCidrs}}
+{{- range $index, $cidr := .UePoolCidrs}}
+/configure policy-options prefix-list "paco-uepool" prefix {{$cidr}} type longer
+{{- end}}
+/configure policy-options policy-statement "paco-export-uepool" entry 10 from prefix-list ["paco-uepool"]
+/configure policy-options policy-statement "paco-export-uepool" entry 10 action action-type accept
+/configure policy-options policy-statement "paco-export-uepool" default-action action-type reject
+{{- end}}
+{{- range $name, $keychain := .Keychains}}
+/configure system security keychains keychain "{{$name}}" admin-state enable
+/configure system security keychains keychain "{{$name}}" tcp-option-number receive tcp-ao
+/configure system security keychains keychain "{{$name}}" tcp-option-number send tcp-ao
+/configure system security keychains keychain "{{$name}}" bidirectional entry 0 admin-state enable
+/configure system security keychains keychain "{{$name}}" bidirectional entry 0 algorithm {{$keychain.KeyAlgorithm}}
+/configure system security keychains keychain "{{$name}}" bidirectional entry 0 authentication-key {{printf "%q" $keychain.AuthKey}}
+{{- end}}
+{{- $as := .AS}}
+{{- range $wlName, $vprn := .Vprns}}
+/configure service vprn "{{$vprn.Name}}" admin-state enable
+/configure service vprn "{{$vprn.Name}}" service-id {{$vprn.ServiceID}}
+/configure service vprn "{{$vprn.Name}}" customer "1"
+/configure service vprn "{{$vprn.Name}}" autonomous-system {{$as}}
+{{- range $index, $itfce := $vprn.Interfaces}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" admin-state enable
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" sap {{$itfce.Port}}:{{$itfce.VlanID}}
+{{- if $itfce.IPv4Address}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv4 primary address {{$itfce.IPv4Address}} prefix-length {{$itfce.IPv4PrefixLength}}
+{{- if and $vprn.Session $vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv4 bfd admin-state enable
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv4 bfd transmit-interval {{$vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv4 bfd receive {{$vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv4 bfd multiplier {{$vprn.Session.BfdMultiplier}}
+{{- end}}
+{{- end}}
+{{- if $itfce.IPv6Address}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv6 address {{$itfce.IPv6Address}} prefix-length {{$itfce.IPv6PrefixLength}}
+{{- if and $vprn.Session $vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv6 bfd admin-state enable
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv6 bfd transmit-interval {{$vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv6 bfd receive {{$vprn.Session.BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" interface "{{$itfce.Name}}" ipv6 bfd multiplier {{$vprn.Session.BfdMultiplier}}
+{{- end}}
+{{- end}}
+{{- end}}
+/configure service vprn "{{$vprn.Name}}" bgp admin-state enable
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" family ipv4 true
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" family ipv6 true
+{{- with $vprn.Session}}
+{{- if .BfdInterval}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" bfd-liveness true
+{{- end}}
+{{- if eq .AuthType "md5"}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" authentication-key {{printf "%q" .AuthKey}}
+{{- else if eq .AuthType "tcp-ao"}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" authentication-keychain "{{.Keychain}}"
+{{- end}}
+{{- if .HoldTime}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" hold-time seconds {{.HoldTime}}
+{{- end}}
+{{- if .KeepaliveInterval}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" keepalive {{.KeepaliveInterval}}
+{{- end}}
+{{- if .MaxPrefix}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" prefix-limit ipv4 maximum {{.MaxPrefix}}
+/configure service vprn "{{$vprn.Name}}" bgp group "paco-leaf" prefix-limit ipv6 maximum {{.MaxPrefix}}
+{{- end}}
+{{- end}}
+{{- range $index, $neighbor := $vprn.Neighbors}}
+/configure service vprn "{{$vprn.Name}}" bgp neighbor "{{$neighbor.PeerIP}}" group "{{$neighbor.PeerGroup}}"
+/configure service vprn "{{$vprn.Name}}" bgp neighbor "{{$neighbor.PeerIP}}" peer-as {{$neighbor.PeerAS}}
+{{- end}}
+{{- if $vprn.ExportUePool}}
+/configure service vprn "{{$vprn.Name}}" bgp group "wan" family ipv4 true
+/configure service vprn "{{$vprn.Name}}" bgp group "wan" export policy ["paco-export-uepool"]
+{{- range $index, $neighbor := $vprn.WanNeighbors}}
+/configure service vprn "{{$vprn.Name}}" bgp neighbor "{{$neighbor.PeerIP}}" group "{{$neighbor.PeerGroup}}"
+/configure service vprn "{{$vprn.Name}}" bgp neighbor "{{$neighbor.PeerIP}}" peer-as {{$neighbor.PeerAS}}
+{{- end}}
+{{- end}}
+{{- end}}
+`
+)
+
+// writeConfig writes the MD-CLI configuration of an SR OS gateway
+func (r *srosRenderer) writeConfig(dirName, fileName *string, gw *srosGateway) error {
+	file, err := r.p.Sink.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
+	if err != nil {
+		return err
+	}
+	if err := r.templates["srosGateway"].Execute(file, gw); err != nil {
+		file.Close()
+		return &TemplateError{Template: "srosGateway", Err: err}
+	}
+	return file.Close()
+}
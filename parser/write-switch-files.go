@@ -2,13 +2,11 @@ package parser
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig"
-	log "github.com/sirupsen/logrus"
 	"github.com/stoewer/go-strcase"
 )
 
@@ -86,13 +84,13 @@ spec:
   tunnel-interface-name: {{.TunnelInterfaceName}}
   vxlan-interface:
 {{- range $index, $element := .VxlanInterfaces}}
-  - index: {{$element.VlanID}}
+  - index: {{$element.Index}}
     type: {{$element.Kind}}
     ingress:
-{{- if eq $element.VlanID "0"}}
+{{- if eq $element.Index "0"}}
       vni: 1
 {{- else}}
-      vni: {{$element.VlanID}}
+      vni: {{$element.Index}}
 {{- end}}
     egress:
       source-ip: use-system-ipv4-address
@@ -127,15 +125,32 @@ spec:
           vlan-id: "{{$element.VlanID}}"
 {{- end}}
 {{- end}}
-{{- if eq $element.Kind "routed" "loopback"}}
+{{- if $element.Unnumbered}}
+{{- if $element.IPv4Unnumbered}}
+    ipv4:
+      admin-state: enable
+      unnumbered:
+        admin-state: enable
+        interface: system0.0
+{{- end}}
+    ipv6:
+      admin-state: enable
+      router-advertisement:
+        router-role:
+          admin-state: enable
+{{- else if eq $element.Kind "routed" "loopback"}}
+{{- if $element.IPv4Prefix}}
     ipv4:
       address: 
       - ip-prefix: {{$element.IPv4Prefix}}
+{{- end}}
+{{- if $element.IPv6Prefix}}
     ipv6:
       address: 
       - ip-prefix: {{$element.IPv6Prefix}}
 {{- end}}
 {{- end}}
+{{- end}}
 `
 
 	goK8sSrlIrbSubInterfaceTemplate = `
@@ -246,29 +261,77 @@ spec:
     - group-name: {{$element.Name}}
 {{- if $element.PolicyName }}
       export-policy: {{$element.PolicyName}}
+{{- end}}
+{{- if $element.ImportPolicyName }}
+      import-policy: {{$element.ImportPolicyName}}
 {{- end}}
       admin-state: enable
+{{- if $element.NextHopUnchanged}}
+      next-hop-self: false
+{{- else}}
       next-hop-self: true
+{{- end}}
+{{- if $element.ClusterID}}
+      route-reflector:
+        client: true
+        cluster-id: {{$element.ClusterID}}
+{{- end}}
+{{- with $element.Session}}
+{{- if .BfdInterval}}
+      failure-detection:
+        enable-bfd: true
+        fast-failover: true
+{{- end}}
+{{- if .Keychain}}
+      authentication:
+        keychain: {{.Keychain}}
+{{- end}}
+{{- if or .HoldTime .KeepaliveInterval}}
+      timers:
+{{- if .HoldTime}}
+        hold-time: {{.HoldTime}}
+{{- end}}
+{{- if .KeepaliveInterval}}
+        keepalive-interval: {{.KeepaliveInterval}}
+{{- end}}
+{{- end}}
+{{- end}}
 {{- range $index, $protocol := $element.Protocols}}
       {{$protocol}}:
         admin-state: enable
+{{- if and $element.IPv6NextHops (eq $protocol "ipv4-unicast")}}
+        advertise-ipv6-next-hops: true
+        receive-ipv6-next-hops: true
 {{- end}}
+{{- if and $element.Session $element.Session.MaxPrefix (ne $protocol "evpn")}}
+        prefix-limit:
+          max-received-routes: {{$element.Session.MaxPrefix}}
 {{- end}}
-    ipv4-unicast:
-      admin-state: enable
-      multipath:
-        allow-multiple-as: true
-        max-paths-level-1: 64
-        max-paths-level-2: 64
-    ipv6-unicast:
+{{- end}}
+{{- end}}
+{{- range $index, $af := .ProtocolBgp.AddressFamilies}}
+    {{$af}}:
       admin-state: enable
       multipath:
         allow-multiple-as: true
         max-paths-level-1: 64
         max-paths-level-2: 64
+{{- end}}
+{{- if .ProtocolBgp.DynamicNeighbors}}
+    dynamic-neighbors:
+      interface:
+{{- range $index, $element := .ProtocolBgp.DynamicNeighbors}}
+      - interface-name: {{$element.Interface}}
+        peer-group: {{$element.PeerGroup}}
+        allowed-peer-as:
+{{- range $as := $element.AllowedPeers}}
+        - "{{$as}}"
+{{- end}}
+{{- end}}
+{{- end}}
     neighbor:
 {{- range $index, $element := .ProtocolBgp.Neighbors}}
-    - peer-address: {{$element.PeerIP}}
+    - peer-address: "{{$element.PeerIP}}"
       peer-as: {{$element.PeerAS}}
       peer-group: {{$element.PeerGroup}}
 {{- if ne $element.LocalAS 0}}
@@ -277,7 +340,99 @@ spec:
 {{- end}}
 {{- if ne $element.TransportAddress ""}}
       transport:
-        local-address: {{$element.TransportAddress}}
+        local-address: "{{$element.TransportAddress}}"
+{{- end}}
+{{- end}}
+`
+	goK8sSrlprotocolsisisTemplate = `
+apiVersion: srlinux.henderiw.be/v1alpha1
+kind: K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsIsis
+metadata:
+  name: {{.ResourceName}}
+  labels:
+    target: {{.Target}}
+spec:
+  network-instance-name: {{.ProtocolIgp.NetworkInstanceName}}
+  isis:
+    instance:
+    - name: underlay
+      admin-state: enable
+      level-capability: {{.ProtocolIgp.LevelCapability}}
+      net:
+      - {{.ProtocolIgp.Net}}
+{{- range $af := .ProtocolIgp.AddressFamilies}}
+      {{$af}}:
+        admin-state: enable
+{{- end}}
+      interface:
+{{- $igp := .ProtocolIgp}}
+{{- range $index, $element := .ProtocolIgp.Interfaces}}
+      - interface-name: {{$element.Name}}
+        admin-state: enable
+{{- if $element.Passive}}
+        passive: true
+{{- else}}
+        circuit-type: point-to-point
+{{- end}}
+{{- range $af := $igp.AddressFamilies}}
+        {{$af}}:
+          admin-state: enable
+{{- if and $igp.Bfd (not $element.Passive)}}
+          enable-bfd: true
+{{- end}}
+{{- end}}
+        level:
+{{- range $level := $igp.Levels}}
+        - level-number: {{$level}}
+{{- if and (ne $igp.HelloInterval 0) (not $element.Passive)}}
+          timers:
+            hello-interval: {{$igp.HelloInterval}}
+            hello-multiplier: {{$igp.HelloMultiplier}}
+{{- end}}
+{{- end}}
+{{- end}}
+`
+	goK8sSrlprotocolsospfTemplate = `
+apiVersion: srlinux.henderiw.be/v1alpha1
+kind: K8sSrlNokiaNetworkInstanceNetworkInstanceProtocolsOspf
+metadata:
+  name: {{.ResourceName}}
+  labels:
+    target: {{.Target}}
+spec:
+  network-instance-name: {{.ProtocolIgp.NetworkInstanceName}}
+  ospf:
+    instance:
+{{- $igp := .ProtocolIgp}}
+{{- range $instance := .ProtocolIgp.OspfInstances}}
+    - name: {{$instance.Name}}
+      admin-state: enable
+      version: ospf-v3
+      address-family: {{$instance.AddressFamily}}
+{{- if ne $instance.InstanceID 0}}
+      instance-id: {{$instance.InstanceID}}
+{{- end}}
+      router-id: {{$igp.RouterID}}
+      max-ecmp-paths: 64
+      area:
+      - area-id: {{$igp.Area}}
+        interface:
+{{- range $index, $element := $igp.Interfaces}}
+        - interface-name: {{$element.Name}}
+          admin-state: enable
+{{- if $element.Passive}}
+          passive: true
+{{- else}}
+          interface-type: point-to-point
+{{- if $igp.Bfd}}
+          failure-detection:
+            enable-bfd: true
+{{- end}}
+{{- if ne $igp.HelloInterval 0}}
+          hello-interval: {{$igp.HelloInterval}}
+          dead-interval: {{$igp.DeadInterval}}
+{{- end}}
+{{- end}}
 {{- end}}
 {{- end}}
 `
@@ -304,6 +459,42 @@ spec:
               admin-state: enable
               interface: {{$element.LagName}}
 {{- end}}
+`
+	goK8sSrlKeychainTemplate = `
+apiVersion: srlinux.henderiw.be/v1alpha1
+kind: K8sSrlNokiaSystemSystemAuthentication
+metadata:
+  name: {{.ResourceName}}
+  labels:
+    target: {{.Target}}
+spec:
+  authentication:
+    keychain:
+    - name: {{.Session.Keychain}}
+      admin-state: enable
+      type: {{.Session.KeychainType}}
+      key:
+      - index: 0
+        algorithm: {{.Session.KeyAlgorithm}}
+        authentication-key: {{printf "%q" .Session.AuthKey}}
+`
+	goK8sSrlBfdTemplate = `
+apiVersion: srlinux.henderiw.be/v1alpha1
+kind: K8sSrlNokiaBfdBfd
+metadata:
+  name: {{.ResourceName}}
+  labels:
+    target: {{.Target}}
+spec:
+  bfd:
+    subinterface:
+{{- range $index, $subinterface := .Bfd.SubInterfaces}}
+    - id: {{$subinterface}}
+      admin-state: enable
+      desired-minimum-transmit-interval: {{$.Bfd.Session.BfdMicroseconds}}
+      required-minimum-receive: {{$.Bfd.Session.BfdMicroseconds}}
+      detection-multiplier: {{$.Bfd.Session.BfdMultiplier}}
+{{- end}}
 `
 	goK8sSrlNetworkInstanceBgpVpnTemplate = `
 apiVersion: srlinux.henderiw.be/v1alpha1
@@ -364,52 +555,63 @@ metadata:
     target: {{.Target}}
 spec:
   routing-policy:
+{{- if .RoutingPolicy.PrefixSets}}
     prefix-set:
-{{- if .RoutingPolicy.IPv4Prefix}}
-    - name: {{.RoutingPolicy.IPv4PrefixSetName}}
-      prefix: 
-      - ip-prefix: {{.RoutingPolicy.IPv4Prefix}}
-        mask-length-range: 32..32
+{{- range $index, $set := .RoutingPolicy.PrefixSets}}
+    - name: {{$set.Name}}
+      prefix:
+{{- range $index, $prefix := $set.Prefixes}}
+      - ip-prefix: {{$prefix.IPPrefix}}
+        mask-length-range: {{$prefix.MaskLengthRange}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .RoutingPolicy.CommunitySets}}
+    community-set:
+{{- range $index, $set := .RoutingPolicy.CommunitySets}}
+    - name: {{$set.Name}}
+      member:
+      - "{{$set.Member}}"
 {{- end }}
-{{- if .RoutingPolicy.IPv6Prefix}}
-    - name: {{.RoutingPolicy.IPv6PrefixSetName}}
-      prefix: 
-      - ip-prefix: {{.RoutingPolicy.IPv6Prefix}}
-        mask-length-range: 128..128
 {{- end }}
     policy:
-    - name: {{.RoutingPolicy.Name}}
+{{- range $index, $policy := .RoutingPolicy.Policies}}
+    - name: {{$policy.Name}}
+{{- if $policy.DefaultAccept}}
+      default-action:
+        accept: {}
+{{- end }}
       statement:
-{{- if .RoutingPolicy.IPv4Prefix}}
-      - sequence-id: 10
+{{- range $index, $statement := $policy.Statements}}
+      - sequence-id: {{$statement.SequenceID}}
         match:
-          prefix-set: {{.RoutingPolicy.IPv4PrefixSetName}}
-        action:
-          accept: {}
+{{- if $statement.PrefixSet}}
+          prefix-set: {{$statement.PrefixSet}}
+{{- end }}
+{{- if $statement.CommunitySet}}
+          bgp:
+            community-set: {{$statement.CommunitySet}}
 {{- end }}
-{{- if .RoutingPolicy.IPv6Prefix}}
-      - sequence-id: 20
-        match:
-          prefix-set: {{.RoutingPolicy.IPv6PrefixSetName}}
         action:
+{{- if $statement.Reject}}
+          reject: {}
+{{- else if $statement.AddCommunitySet}}
+          accept:
+            bgp:
+              communities:
+                add: {{$statement.AddCommunitySet}}
+{{- else}}
           accept: {}
 {{- end }}
+{{- end }}
+{{- end }}
 `
 
 	goTemplates = map[string]*template.Template{
-		"kustomize":                 makek8sTemplate("kustomize", goK8sKustomizeTemplate),
-		"srlInterface":              makek8sTemplate("srlInterface", goK8sSrlinterfaceTemplate),
-		"srlSubInterface":           makek8sTemplate("srlSubInterface", goK8sSrlsubinterfaceTemplate),
-		"srlIrbSubInterface":        makek8sTemplate("srlIrbSubInterface", goK8sSrlIrbSubInterfaceTemplate),
-		"srlTunnelInterface":        makek8sTemplate("srlTunnelInterface", goK8sSrlTunnelInterfaceTemplate),
-		"srlVxlanInterface":         makek8sTemplate("srlVxlanInterface", goK8sSrlVxlanInterfaceTemplate),
-		"srlNetworkInstance":        makek8sTemplate("srlNetworkInstance", goK8sSrlnetworkinstanceTemplate),
-		"srlProtocolsBgp":           makek8sTemplate("srlProtocolsBgp", goK8sSrlprotocolsbgpTemplate),
-		"srlSystemNetworkInstance":  makek8sTemplate("srlSystemNetworkInstance", goK8sSrlSystemNetworkInstanceTemplate),
-		"srlNetworkInstanceBgpVpn":  makek8sTemplate("srlNetworkInstanceBgpVpn", goK8sSrlNetworkInstanceBgpVpnTemplate),
-		"srlNetworkInstanceBgpEvpn": makek8sTemplate("srlNetworkInstanceBgpEvpn", goK8sSrlNetworkInstanceBgpEvpnTemplate),
-		"srlNetworkInstanceLinux":   makek8sTemplate("srlNetworkInstanceLinux", goK8sSrlNetworkInstanceLinuxTemplate),
-		"srlRoutingPolicy":          makek8sTemplate("srlRoutingPolicy", goK8sSrlRoutingPoliciesTemplate),
+		"kustomize":       makek8sTemplate("kustomize", goK8sKustomizeTemplate),
+		"clab":            makek8sTemplate("clab", goClabTemplate),
+		"diagram-dot":     makek8sTemplate("diagram-dot", goDiagramDotTemplate),
+		"diagram-mermaid": makek8sTemplate("diagram-mermaid", goDiagramMermaidTemplate),
 	}
 
 	// templateHelperFunctions specifies a set of functions that are supplied as
@@ -458,16 +660,9 @@ spec:
 			return rtCommExpr
 		},
 		"lastmap": func(s string, x map[string][]*string) bool {
-			i := 0
-			for k := range x {
-				if k == s {
-					if i == len(x)-1 {
-						return true
-					}
-				}
-				i++
-			}
-			return false
+			// range in a template iterates over the map in sorted key order
+			keys := SortedKeys(x)
+			return len(keys) > 0 && keys[len(keys)-1] == s
 		},
 	}
 )
@@ -480,7 +675,11 @@ func makek8sTemplate(name, src string) *template.Template {
 
 // WriteKustomize function writes the kustomize resource file
 func (p *Parser) WriteKustomize(dirName, fileName *string, resources []string) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
+	// the native switch formats have no kustomizations
+	if p.nativeSwitchConfig() {
+		return nil
+	}
+	file, err := p.Sink.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
 	if err != nil {
 		return err
 	}
@@ -492,301 +691,113 @@ func (p *Parser) WriteKustomize(dirName, fileName *string, resources []string) e
 	}
 
 	if err := goTemplates["kustomize"].Execute(file, s); err != nil {
-		log.Error(err)
+		file.Close()
+		return &TemplateError{Template: "kustomize", Err: err}
 	}
-	file.Close()
-	return nil
+	return file.Close()
 }
 
-// WriteSrlInterface function writes the k8s srl interface resource
+// WriteSrlInterface renders the interfaces with the switch renderer of the target nodes
 func (p *Parser) WriteSrlInterface(dirName, fileName, resName, target *string, interfaces []*k8ssrlinterface) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName string
-		Target       string
-		Interfaces   []*k8ssrlinterface
-	}{
-		ResourceName: *resName,
-		Target:       *target,
-		Interfaces:   interfaces,
-	}
-
-	if err := goTemplates["srlInterface"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.Interfaces(res, interfaces)
+	})
 }
 
-// WriteSrlInterface function writes the k8s srl subinterface resource
+// WriteSrlSubInterface renders the subinterfaces with the switch renderer of the target nodes
 func (p *Parser) WriteSrlSubInterface(dirName, fileName, resName, target *string, subinterfaces []*k8ssrlsubinterface) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName  string
-		Target        string
-		InterfaceName string
-		SubInterfaces []*k8ssrlsubinterface
-	}{
-		ResourceName:  *resName,
-		Target:        *target,
-		InterfaceName: subinterfaces[0].InterfaceRealName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
-		SubInterfaces: subinterfaces,
-	}
-
-	if err := goTemplates["srlSubInterface"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.SubInterfaces(res, subinterfaces)
+	})
 }
 
-// WriteSrlIrbSubInterface function writes the k8s srl subinterface resource
+// WriteSrlIrbSubInterface renders the irb subinterfaces with the switch renderer of the target nodes
 func (p *Parser) WriteSrlIrbSubInterface(dirName, fileName, resName, target *string, irbsubinterfaces []*k8ssrlirbsubinterface) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName  string
-		Target        string
-		InterfaceName string
-		SubInterfaces []*k8ssrlirbsubinterface
-	}{
-		ResourceName:  *resName,
-		Target:        *target,
-		InterfaceName: irbsubinterfaces[0].InterfaceRealName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
-		SubInterfaces: irbsubinterfaces,
-	}
-
-	if err := goTemplates["srlIrbSubInterface"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.IrbSubInterfaces(res, irbsubinterfaces)
+	})
 }
 
-// WriteSrlTunnelInterface function writes the k8s srl tunnel-interface resource
+// WriteSrlTunnelInterface renders the tunnel interfaces with the switch renderer of the target nodes
 func (p *Parser) WriteSrlTunnelInterface(dirName, fileName, resName, target *string, tunnelinterfaces []*k8ssrlTunnelInterface) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName     string
-		Target           string
-		TunnelInterfaces []*k8ssrlTunnelInterface
-	}{
-		ResourceName:     *resName,
-		Target:           *target,
-		TunnelInterfaces: tunnelinterfaces,
-	}
-
-	if err := goTemplates["srlTunnelInterface"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.TunnelInterfaces(res, tunnelinterfaces)
+	})
 }
 
-// WriteSrlVxlanInterface function writes the k8s srl vxlan interface within the tunnelinterface resource
+// WriteSrlVxlanInterface renders the vxlan interfaces with the switch renderer of the target nodes
 func (p *Parser) WriteSrlVxlanInterface(dirName, fileName, resName, target *string, vxlaninterfaces []*k8ssrlVxlanInterface) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName        string
-		Target              string
-		TunnelInterfaceName string
-		VxlanInterfaces     []*k8ssrlVxlanInterface
-	}{
-		ResourceName:        *resName,
-		Target:              *target,
-		TunnelInterfaceName: vxlaninterfaces[0].TunnelInterfaceName, // if we come here there will be 1 element in the list so we pick the first, since interfacename will always be the same
-		VxlanInterfaces:     vxlaninterfaces,
-	}
-
-	if err := goTemplates["srlVxlanInterface"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.VxlanInterfaces(res, vxlaninterfaces)
+	})
 }
 
-// WriteSrlNetworkInstance function writes the k8s srl network-instance resource
+// WriteSrlNetworkInstance renders the network instance with the switch renderer of the target nodes
 func (p *Parser) WriteSrlNetworkInstance(dirName, fileName, resName, target *string, netwinstance *k8ssrlNetworkInstance) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName    string
-		Target          string
-		NetworkInstance *k8ssrlNetworkInstance
-	}{
-		ResourceName:    *resName,
-		Target:          *target,
-		NetworkInstance: netwinstance,
-	}
-
-	if err := goTemplates["srlNetworkInstance"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.NetworkInstance(res, netwinstance)
+	})
 }
 
-// WriteSrlNetworkInstance function writes the k8s srl protocols bgp resource
+// WriteSrlProtocolsBgp renders the bgp protocol with the switch renderer of the target nodes
 func (p *Parser) WriteSrlProtocolsBgp(dirName, fileName, resName, target *string, protocolsbgp *k8ssrlprotocolsbgp) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName string
-		Target       string
-		ProtocolBgp  *k8ssrlprotocolsbgp
-	}{
-		ResourceName: *resName,
-		Target:       *target,
-		ProtocolBgp:  protocolsbgp,
-	}
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.ProtocolsBgp(res, protocolsbgp)
+	})
+}
 
-	if err := goTemplates["srlProtocolsBgp"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+// WriteSrlProtocolsIgp renders the igp protocol with the switch renderer of the target nodes
+func (p *Parser) WriteSrlProtocolsIgp(dirName, fileName, resName, target *string, protocolsigp *k8ssrlprotocolsigp) error {
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.ProtocolsIgp(res, protocolsigp)
+	})
 }
 
+// WriteSrlSystemNetworkInstance renders the ethernet segments with the switch renderer of the target nodes
 func (p *Parser) WriteSrlSystemNetworkInstance(dirName, fileName, resName, target *string, esis []*k8ssrlESI) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName string
-		Target       string
-		ESIs         []*k8ssrlESI
-	}{
-		ResourceName: *resName,
-		Target:       *target,
-		ESIs:         esis,
-	}
-
-	if err := goTemplates["srlSystemNetworkInstance"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.ESIs(res, esis)
+	})
 }
 
-// WriteSrlNetworkInstanceBgpVpn function writes the k8s srl network-instance bgpvpn protocol resource
+// WriteSrlNetworkInstanceBgpVpn renders the bgp-vpn protocol with the switch renderer of the target nodes
 func (p *Parser) WriteSrlNetworkInstanceBgpVpn(dirName, fileName, resName, target *string, netwInstanceProtocol *k8ssrlNetworkInstance) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName            string
-		Target                  string
-		NetworkInstanceProtocol *k8ssrlNetworkInstance
-	}{
-		ResourceName:            *resName,
-		Target:                  *target,
-		NetworkInstanceProtocol: netwInstanceProtocol,
-	}
-
-	if err := goTemplates["srlNetworkInstanceBgpVpn"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.BgpVpn(res, netwInstanceProtocol)
+	})
 }
 
-// WriteSrlNetworkInstanceBgpEvpn function writes the k8s srl network-instance bgpevpn protocol resource
+// WriteSrlNetworkInstanceBgpEvpn renders the bgp-evpn protocol with the switch renderer of the target nodes
 func (p *Parser) WriteSrlNetworkInstanceBgpEvpn(dirName, fileName, resName, target *string, netwInstanceProtocol *k8ssrlNetworkInstance) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName            string
-		Target                  string
-		NetworkInstanceProtocol *k8ssrlNetworkInstance
-	}{
-		ResourceName:            *resName,
-		Target:                  *target,
-		NetworkInstanceProtocol: netwInstanceProtocol,
-	}
-
-	if err := goTemplates["srlNetworkInstanceBgpEvpn"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.BgpEvpn(res, netwInstanceProtocol)
+	})
 }
 
-// WriteSrlNetworkInstanceLinux function writes the k8s srl network-instance bgpevpn protocol resource
+// WriteSrlNetworkInstanceLinux renders the linux protocol with the switch renderer of the target nodes
 func (p *Parser) WriteSrlNetworkInstanceLinux(dirName, fileName, resName, target *string, netwInstanceProtocol *k8ssrlNetworkInstance) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
-
-	s := struct {
-		ResourceName            string
-		Target                  string
-		NetworkInstanceProtocol *k8ssrlNetworkInstance
-	}{
-		ResourceName:            *resName,
-		Target:                  *target,
-		NetworkInstanceProtocol: netwInstanceProtocol,
-	}
-
-	if err := goTemplates["srlNetworkInstanceLinux"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.Linux(res, netwInstanceProtocol)
+	})
 }
 
-// WriteSrlNetworkInstanceLinux function writes the k8s srl network-instance bgpevpn protocol resource
+// WriteSrlRoutingPolicy renders the routing policy with the switch renderer of the target nodes
 func (p *Parser) WriteSrlRoutingPolicy(dirName, fileName, resName, target *string, routingPolicy *k8ssrlRoutingPolicy) error {
-	file, err := os.Create(filepath.Join(*dirName, filepath.Base(*fileName)))
-	if err != nil {
-		return err
-	}
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.RoutingPolicy(res, routingPolicy)
+	})
+}
 
-	s := struct {
-		ResourceName  string
-		Target        string
-		RoutingPolicy *k8ssrlRoutingPolicy
-	}{
-		ResourceName:  *resName,
-		Target:        *target,
-		RoutingPolicy: routingPolicy,
-	}
+// WriteSrlKeychain renders the keychain of the authentication of the bgp sessions with the switch renderer of the target nodes
+func (p *Parser) WriteSrlKeychain(dirName, fileName, resName, target *string, session *bgpSession) error {
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.Keychain(res, session)
+	})
+}
 
-	if err := goTemplates["srlRoutingPolicy"].Execute(file, s); err != nil {
-		log.Error(err)
-	}
-	file.Close()
-	return nil
+// WriteSrlBfd renders the bfd of the subinterfaces with the switch renderer of the target nodes
+func (p *Parser) WriteSrlBfd(dirName, fileName, resName, target *string, bfd *k8ssrlBfd) error {
+	return p.render(dirName, fileName, resName, target, func(r switchRenderer, res *switchResource) error {
+		return r.Bfd(res, bfd)
+	})
 }
@@ -0,0 +1,9 @@
+// Package templates holds the built-in templates that are rendered by the parser
+package templates
+
+import "embed"
+
+// FS holds the built-in app-helm, app-kustomize and server templates
+//
+//go:embed app-helm app-kustomize server
+var FS embed.FS